@@ -0,0 +1,49 @@
+package market
+
+import (
+	"strings"
+	"sync"
+)
+
+// defaultSymbolAliases 内置的币种别名：其他交易所使用的代码 → Binance 代码
+var defaultSymbolAliases = map[string]string{
+	"XBT": "BTC",  // Kraken/BitMEX
+	"XDG": "DOGE", // Kraken
+}
+
+// symbolAliases Normalize/NormalizeWithQuote 使用的币种别名表，key/value 均为大写
+var symbolAliases = struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+}{aliases: copyAliases(defaultSymbolAliases)}
+
+// SetSymbolAliases 替换币种别名表（如 {"XBT": "BTC"}），大小写不敏感；传入 nil 时恢复内置别名
+// 别名在追加计价币种前作用于基础币与显式计价币种，例如 "xbt" → "BTCUSDT"，"ETH/XBT" → "ETHBTC"
+func SetSymbolAliases(aliases map[string]string) {
+	if aliases == nil {
+		aliases = defaultSymbolAliases
+	}
+	copied := copyAliases(aliases)
+	symbolAliases.mu.Lock()
+	symbolAliases.aliases = copied
+	symbolAliases.mu.Unlock()
+}
+
+// copyAliases 复制别名表并统一转为大写
+func copyAliases(aliases map[string]string) map[string]string {
+	result := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		result[strings.ToUpper(strings.TrimSpace(from))] = strings.ToUpper(strings.TrimSpace(to))
+	}
+	return result
+}
+
+// resolveAlias 返回币种代码对应的 Binance 代码，无别名时原样返回
+func resolveAlias(asset string) string {
+	symbolAliases.mu.RLock()
+	defer symbolAliases.mu.RUnlock()
+	if to, ok := symbolAliases.aliases[asset]; ok && to != "" {
+		return to
+	}
+	return asset
+}
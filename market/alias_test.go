@@ -0,0 +1,32 @@
+package market
+
+import "testing"
+
+func TestSetSymbolAliases(t *testing.T) {
+	resetPrecisionCache()
+	t.Cleanup(resetPrecisionCache)
+	t.Cleanup(func() { SetSymbolAliases(nil) })
+
+	tests := []struct {
+		name    string
+		aliases map[string]string
+		symbol  string
+		want    string
+	}{
+		{"内置别名", nil, "xbt", "BTCUSDT"},
+		{"内置别名作用于计价币种", nil, "ETH/XBT", "ETHBTC"},
+		{"无别名的币种不变", nil, "sol", "SOLUSDT"},
+		{"自定义别名大小写不敏感", map[string]string{" kPepe ": "1000pepe"}, "kpepe", "1000PEPEUSDT"},
+		{"自定义表替换内置别名", map[string]string{"kpepe": "1000PEPE"}, "xbt", "XBTUSDT"},
+		{"自定义表下无别名的币种不变", map[string]string{"kpepe": "1000PEPE"}, "SOLUSDT", "SOLUSDT"},
+		{"传入nil恢复内置别名", nil, "XBTUSDT", "BTCUSDT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSymbolAliases(tt.aliases)
+			if got := Normalize(tt.symbol); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.symbol, got, tt.want)
+			}
+		})
+	}
+}
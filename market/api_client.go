@@ -1,19 +1,14 @@
 package market
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
 )
 
-const (
-	baseURL = "https://fapi.binance.com"
-)
-
 type APIClient struct {
 	client *http.Client
 }
@@ -27,14 +22,14 @@ func NewAPIClient() *APIClient {
 }
 
 func (c *APIClient) GetExchangeInfo() (*ExchangeInfo, error) {
-	url := fmt.Sprintf("%s/fapi/v1/exchangeInfo", baseURL)
+	url := fmt.Sprintf("%s/fapi/v1/exchangeInfo", BaseURL)
 	resp, err := c.client.Get(url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +43,7 @@ func (c *APIClient) GetExchangeInfo() (*ExchangeInfo, error) {
 }
 
 func (c *APIClient) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
-	url := fmt.Sprintf("%s/fapi/v1/klines", baseURL)
+	url := fmt.Sprintf("%s/fapi/v1/klines", BaseURL)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -66,7 +61,7 @@ func (c *APIClient) GetKlines(symbol, interval string, limit int) ([]Kline, erro
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +76,7 @@ func (c *APIClient) GetKlines(symbol, interval string, limit int) ([]Kline, erro
 	for _, kr := range klineResponses {
 		kline, err := parseKline(kr)
 		if err != nil {
-			log.Printf("解析K线数据失败: %v", err)
+			logger.Warnf("解析K线数据失败: %v", err)
 			continue
 		}
 		klines = append(klines, kline)
@@ -114,7 +109,7 @@ func parseKline(kr KlineResponse) (Kline, error) {
 }
 
 func (c *APIClient) GetCurrentPrice(symbol string) (float64, error) {
-	url := fmt.Sprintf("%s/fapi/v1/ticker/price", baseURL)
+	url := fmt.Sprintf("%s/fapi/v1/ticker/price", BaseURL)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return 0, err
@@ -130,7 +125,7 @@ func (c *APIClient) GetCurrentPrice(symbol string) (float64, error) {
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp.Body)
 	if err != nil {
 		return 0, err
 	}
@@ -148,3 +143,39 @@ func (c *APIClient) GetCurrentPrice(symbol string) (float64, error) {
 
 	return price, nil
 }
+
+// fetchFuturesKlines 通过合约 REST 接口获取K线，作为 WebSocket 数据源超时时的回退
+func fetchFuturesKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	url := fmt.Sprintf("%s/fapi/v1/klines?symbol=%s&interval=%s&limit=%d", BaseURL, symbol, interval, limit)
+	return fetchRESTKlines(ctx, url)
+}
+
+// fetchSpotKlines 通过现货 REST 接口获取K线（返回格式与合约K线一致）
+func fetchSpotKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	url := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&limit=%d", SpotBaseURL, symbol, interval, limit)
+	return fetchRESTKlines(ctx, url)
+}
+
+// fetchRESTKlines 请求 REST klines 接口并解析，单根K线解析失败时记录日志后跳过
+func fetchRESTKlines(ctx context.Context, url string) ([]Kline, error) {
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var klineResponses []KlineResponse
+	if err := json.Unmarshal(body, &klineResponses); err != nil {
+		return nil, err
+	}
+
+	klines := make([]Kline, 0, len(klineResponses))
+	for _, kr := range klineResponses {
+		kline, err := parseKline(kr)
+		if err != nil {
+			logger.Warnf("解析K线数据失败: %v", err)
+			continue
+		}
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
@@ -0,0 +1,142 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// GetMany 并发获取多个交易对的市场数据，单个交易对失败不影响其他交易对
+// concurrency: 最大并发数(<=0 时按1处理)
+// 返回的两个 map 均以标准化后的 symbol 为键：成功的交易对在 data 中，失败的在 errs 中；
+// ctx 被取消后未开始的交易对以 ctx.Err() 记入 errs，保证每个输入交易对都有结果
+func GetMany(ctx context.Context, symbols []string, concurrency int) (map[string]*Data, map[string]error) {
+	results := make(map[string]*Data, len(symbols))
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	fetchMany(ctx, symbols, concurrency, func(symbol string, data *Data, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[symbol] = err
+			return
+		}
+		results[symbol] = data
+	})
+	return results, errs
+}
+
+// GetManyWithContext 并发获取多个交易对的市场数据，支持中途取消
+// concurrency: 最大并发数(<=0 时按1处理)
+// ctx 被取消后不再派发新的交易对，正在进行的获取通过 GetContext 中止，
+// 返回已完成部分的结果以及 ctx.Err()；未取消时 error 为 nil。
+// 单个交易对失败只记录日志，不影响其他交易对，结果以标准化后的 symbol 为键。
+func GetManyWithContext(ctx context.Context, symbols []string, concurrency int) (map[string]*Data, error) {
+	results := make(map[string]*Data, len(symbols))
+	var mu sync.Mutex
+	fetchMany(ctx, symbols, concurrency, func(symbol string, data *Data, err error) {
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warnf("获取 %s 市场数据失败: %v", symbol, err)
+			}
+			return
+		}
+		mu.Lock()
+		results[symbol] = data
+		mu.Unlock()
+	})
+	return results, ctx.Err()
+}
+
+// fetchMany 以有界 worker 池对每个（标准化后的）交易对调用 GetContext，并通过 onResult 回报结果
+// onResult 会被多个 goroutine 并发调用；ctx 取消后剩余交易对直接以 ctx.Err() 回报
+func fetchMany(ctx context.Context, symbols []string, concurrency int, onResult func(symbol string, data *Data, err error)) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for symbol := range jobs {
+				// 每个交易对开始前检查是否已取消
+				if err := ctx.Err(); err != nil {
+					onResult(symbol, nil, err)
+					continue
+				}
+				data, err := GetContext(ctx, symbol)
+				onResult(symbol, data, err)
+			}
+		}()
+	}
+
+	dispatched := 0
+dispatch:
+	for _, symbol := range symbols {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- Normalize(symbol):
+			dispatched++
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, symbol := range symbols[dispatched:] {
+		onResult(Normalize(symbol), nil, ctx.Err())
+	}
+}
+
+// MultiConcurrency GetMulti 同时获取的最大周期数
+var MultiConcurrency = 4
+
+// GetMulti 只获取并计算指定周期的日内指标，返回以周期为键的结果
+// 适合只关心部分时间框架(如 {"15m","4h"})的场景，避免获取全部五个周期的开销
+// 周期需为 Binance 支持的K线周期，重复周期只获取一次；各周期以最多 MultiConcurrency 个并发获取，
+// 数据源超时时与 Get 一样改用 REST 接口，任一失败即取消其余请求并返回错误
+func GetMulti(symbol string, intervals []string) (map[string]*IntradayData, error) {
+	return getMulti(context.Background(), DefaultOptions(), symbol, intervals)
+}
+
+// getMulti GetMulti 的实现，K线数据源与超时取自 opts
+func getMulti(ctx context.Context, opts Options, symbol string, intervals []string) (map[string]*IntradayData, error) {
+	if len(intervals) == 0 {
+		return nil, fmt.Errorf("未指定K线周期")
+	}
+	opts.Timeframes = intervals
+	timeframes, err := opts.timeframes()
+	if err != nil {
+		return nil, err
+	}
+	symbol = Normalize(symbol)
+
+	series := make([]*IntradayData, len(timeframes))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxInt(MultiConcurrency, 1))
+	for i, interval := range timeframes {
+		g.Go(func() error {
+			klines, err := fetchMarketKlines(gctx, opts, symbol, interval)
+			if err != nil {
+				return fmt.Errorf("获取%s K线失败: %w", interval, err)
+			}
+			series[i] = calculateIntradaySeries(klines, DefaultIndicatorConfig().Intraday)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*IntradayData, len(timeframes))
+	for i, interval := range timeframes {
+		results[interval] = series[i]
+	}
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencySource 记录最大并发调用数的 KlineSource
+type concurrencySource struct {
+	fakeSource
+	mu        sync.Mutex
+	inFlight  int
+	maxFlight int
+	intervals []string
+}
+
+func (s *concurrencySource) GetCurrentKlines(symbol, interval string) ([]Kline, error) {
+	s.mu.Lock()
+	s.inFlight++
+	s.maxFlight = maxInt(s.maxFlight, s.inFlight)
+	s.intervals = append(s.intervals, interval)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	return s.fakeSource.GetCurrentKlines(symbol, interval)
+}
+
+func TestGetMulti(t *testing.T) {
+	prev := MultiConcurrency
+	MultiConcurrency = 2
+	t.Cleanup(func() { MultiConcurrency = prev })
+
+	tests := []struct {
+		name      string
+		intervals []string
+		want      int
+		wantErr   bool
+	}{
+		{"去重", []string{"15m", "4h", "15m"}, 2, false},
+		{"有界并发", []string{"1m", "3m", "5m", "15m", "30m", "1h"}, 6, false},
+		{"未指定周期", nil, 0, true},
+		{"不支持的周期", []string{"7m"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &concurrencySource{fakeSource: fakeSource{klines: testKlines(60, time.Minute), delay: 10 * time.Millisecond}}
+			opts := DefaultOptions()
+			opts.Source = src
+
+			got, err := getMulti(context.Background(), opts, "btc", tt.intervals)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want || len(src.intervals) != tt.want {
+				t.Errorf("结果 %d 项、数据源调用 %d 次, want %d", len(got), len(src.intervals), tt.want)
+			}
+			if src.maxFlight > MultiConcurrency {
+				t.Errorf("最大并发 %d 超过 MultiConcurrency=%d", src.maxFlight, MultiConcurrency)
+			}
+			for _, interval := range tt.intervals {
+				if !tt.wantErr && got[interval] == nil {
+					t.Errorf("缺少周期 %s 的结果", interval)
+				}
+			}
+		})
+	}
+}
+
+func TestGetManyIsolatesFailures(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+		"/fapi/v1/klines":       klinesHandler(100),
+	})
+	WSMonitorCli = useTestMonitor(t)
+
+	data, errs := GetMany(context.Background(), []string{"btc", "ETHUSDT", "nope"}, 2)
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT"} {
+		if data[symbol] == nil || data[symbol].Symbol != symbol {
+			t.Errorf("缺少 %s 的结果, errs=%v", symbol, errs)
+		}
+	}
+	if len(data) != 2 || len(errs) != 1 {
+		t.Fatalf("成功 %d 个、失败 %d 个, want 2/1", len(data), len(errs))
+	}
+	if !errors.Is(errs["NOPEUSDT"], ErrUnknownSymbol) {
+		t.Errorf("errs[NOPEUSDT] = %v, want ErrUnknownSymbol", errs["NOPEUSDT"])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs = GetMany(ctx, []string{"btc", "eth"}, 1)
+	if len(errs) != 2 || !errors.Is(errs["BTCUSDT"], context.Canceled) {
+		t.Errorf("取消后 errs = %v, want 每个交易对均为 context.Canceled", errs)
+	}
+}
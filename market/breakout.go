@@ -0,0 +1,83 @@
+package market
+
+// 假突破检测结果
+const (
+	FakeoutNone     = "none"
+	FakeoutBullTrap = "bull_trap" // 向上突破前高后收回其下方（多头陷阱）
+	FakeoutBearTrap = "bear_trap" // 向下跌破前低后收回其上方（空头陷阱）
+)
+
+// fakeoutLookback Data 中15分钟假突破检测使用的回看K线数
+const fakeoutLookback = 20
+
+// fakeoutBreachBars 允许发生“突破-收回”序列的最近K线数（突破可在前一根，收回在最新一根）
+const fakeoutBreachBars = 2
+
+// DetectFakeout 检测最近K线是否构成假突破
+// 参考区间为最近 fakeoutBreachBars 根之前的 lookback 根K线的最高/最低价：
+// 最近几根K线的最高价突破区间高点、而最新收盘价回到高点下方 → "bull_trap"；
+// 最低价跌破区间低点、而最新收盘价回到低点上方 → "bear_trap"；
+// 否则（含上下同时突破的情形）返回 "none"。
+func DetectFakeout(klines []Kline, lookback int) string {
+	if lookback <= 0 || len(klines) < lookback+fakeoutBreachBars {
+		return FakeoutNone
+	}
+
+	end := len(klines) - fakeoutBreachBars
+	refHigh, refLow := klines[end-lookback].High, klines[end-lookback].Low
+	for _, k := range klines[end-lookback : end] {
+		if k.High > refHigh {
+			refHigh = k.High
+		}
+		if k.Low < refLow {
+			refLow = k.Low
+		}
+	}
+
+	breachHigh, breachLow := false, false
+	for _, k := range klines[end:] {
+		if k.High > refHigh {
+			breachHigh = true
+		}
+		if k.Low < refLow {
+			breachLow = true
+		}
+	}
+
+	lastClose := klines[len(klines)-1].Close
+	bullTrap := breachHigh && lastClose < refHigh
+	bearTrap := breachLow && lastClose > refLow
+	switch {
+	case bullTrap && !bearTrap:
+		return FakeoutBullTrap
+	case bearTrap && !bullTrap:
+		return FakeoutBearTrap
+	default:
+		return FakeoutNone
+	}
+}
+
+// BreakoutVolumeMultiple 突破K线成交量至少为参考区间平均成交量的倍数，DetectBreakout 才确认突破
+var BreakoutVolumeMultiple = 1.5
+
+// DetectBreakout 检测最新K线是否放量突破唐奇安通道
+// 通道取最新K线之前 period 根K线的最高价/最低价：最新收盘价高于通道上轨且成交量超过
+// 这 period 根平均成交量的 BreakoutVolumeMultiple 倍 → up；跌破下轨同理 → down。
+// 缩量触及通道视为无效突破；K线不足 period+1 根时均为 false
+func DetectBreakout(klines []Kline, period int) (up, down bool) {
+	if period <= 0 || len(klines) < period+1 {
+		return false, false
+	}
+
+	last := klines[len(klines)-1]
+	upper, lower, _ := calculateDonchian(klines[:len(klines)-1], period)
+	volume := 0.0
+	for _, k := range klines[len(klines)-1-period : len(klines)-1] {
+		volume += k.Volume
+	}
+	avgVolume := volume / float64(period)
+	if avgVolume <= 0 || last.Volume <= avgVolume*BreakoutVolumeMultiple {
+		return false, false
+	}
+	return last.Close > upper, last.Close < lower
+}
@@ -0,0 +1,62 @@
+package market
+
+import "testing"
+
+func TestDetectBreakout(t *testing.T) {
+	// 参考区间：20根收盘100、振幅±1(通道 99~101)、成交量1的K线
+	withLast := func(close, volume float64) []Kline {
+		klines := closeKlines(1, stepCloses(20, 100, 0)...)
+		return append(klines, Kline{Open: 100, High: close + 1, Low: close - 1, Close: close, Volume: volume})
+	}
+	tests := []struct {
+		name             string
+		klines           []Kline
+		wantUp, wantDown bool
+	}{
+		{"放量向上突破", withLast(105, 3), true, false},
+		{"缩量假突破", withLast(105, 1.2), false, false},
+		{"恰好1.5倍量不确认", withLast(105, 1.5), false, false},
+		{"放量向下突破", withLast(95, 2), false, true},
+		{"放量但在通道内", withLast(100.5, 5), false, false},
+		{"K线不足", closeKlines(1, stepCloses(20, 100, 0)...), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down := DetectBreakout(tt.klines, 20)
+			if up != tt.wantUp || down != tt.wantDown {
+				t.Errorf("DetectBreakout = %v, %v; want %v, %v", up, down, tt.wantUp, tt.wantDown)
+			}
+		})
+	}
+}
+
+func TestDetectFakeout(t *testing.T) {
+	// 参考区间：20根收盘100、振幅±1(区间 99~101)，之后追加最近两根K线
+	bar := func(high, low, close float64) Kline {
+		return Kline{Open: 100, High: high, Low: low, Close: close}
+	}
+	withRecent := func(recent ...Kline) []Kline {
+		return append(closeKlines(1, stepCloses(20, 100, 0)...), recent...)
+	}
+	tests := []struct {
+		name   string
+		klines []Kline
+		want   string
+	}{
+		{"向上突破后站稳", withRecent(bar(105, 99.5, 104), bar(106, 103, 105)), FakeoutNone},
+		{"向上突破后收回区间", withRecent(bar(105, 100, 104), bar(104, 99.5, 100)), FakeoutBullTrap},
+		{"最新一根冲高回落", withRecent(bar(100.5, 99.5, 100), bar(103, 99.5, 100.5)), FakeoutBullTrap},
+		{"向下跌破后站稳", withRecent(bar(100.5, 95, 96), bar(97, 94, 95)), FakeoutNone},
+		{"向下跌破后收回区间", withRecent(bar(100, 95, 96), bar(100.5, 96, 100)), FakeoutBearTrap},
+		{"上下同时突破", withRecent(bar(105, 95, 100), bar(100.5, 99.5, 100)), FakeoutNone},
+		{"未突破", withRecent(bar(100.8, 99.2, 100), bar(100.9, 99.5, 100.2)), FakeoutNone},
+		{"K线不足", closeKlines(1, stepCloses(21, 100, 0)...), FakeoutNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFakeout(tt.klines, 20); got != tt.want {
+				t.Errorf("DetectFakeout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
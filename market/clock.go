@@ -0,0 +1,7 @@
+package market
+
+import "time"
+
+// now 返回当前时间，缓存过期、资金费倒计时与 Retry-After 等时间相关逻辑统一经由它取时，
+// 测试中可替换为固定时钟以获得确定结果（请求耗时统计与 REST 限速仍使用单调时钟）
+var now = time.Now
@@ -0,0 +1,41 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockDrivesExpiry(t *testing.T) {
+	resetPrecisionCache()
+	t.Cleanup(resetPrecisionCache)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := useFakeClock(t, start)
+
+	if got := now(); !got.Equal(start) {
+		t.Fatalf("now() = %v, want %v", got, start)
+	}
+	precisionCache.Store("BTCUSDT", precisionEntry{precision: 2, status: "TRADING", fetchedAt: now()})
+	funding := &FundingData{NextFundingTime: Timestamp{start.Add(8 * time.Hour)}}
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		wantCached    bool
+		wantCountdown string
+	}{
+		{"初始", 0, true, "8h0m"},
+		{"恰好到期", PrecisionCacheTTL, true, "7h0m"},
+		{"过期1秒", time.Second, false, "6h59m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if _, ok := cachedPrecision("BTCUSDT"); ok != tt.wantCached {
+				t.Errorf("缓存命中 = %v, want %v", ok, tt.wantCached)
+			}
+			if got := fundingCountdown(funding, now()); got != tt.wantCountdown {
+				t.Errorf("fundingCountdown = %q, want %q", got, tt.wantCountdown)
+			}
+		})
+	}
+}
@@ -15,7 +15,6 @@ type CombinedStreamsClient struct {
 	conn        *websocket.Conn
 	mu          sync.RWMutex
 	subscribers map[string]chan []byte
-	reconnect   bool
 	done        chan struct{}
 	batchSize   int // 每批订阅的流数量
 }
@@ -23,7 +22,6 @@ type CombinedStreamsClient struct {
 func NewCombinedStreamsClient(batchSize int) *CombinedStreamsClient {
 	return &CombinedStreamsClient{
 		subscribers: make(map[string]chan []byte),
-		reconnect:   true,
 		done:        make(chan struct{}),
 		batchSize:   batchSize,
 	}
@@ -35,7 +33,7 @@ func (c *CombinedStreamsClient) Connect() error {
 	}
 
 	// 组合流使用不同的端点
-	conn, _, err := dialer.Dial("wss://fstream.binance.com/stream", nil)
+	conn, _, err := dialer.Dial(StreamBaseURL+"/stream", nil)
 	if err != nil {
 		return fmt.Errorf("组合流WebSocket连接失败: %v", err)
 	}
@@ -45,7 +43,7 @@ func (c *CombinedStreamsClient) Connect() error {
 	c.mu.Unlock()
 
 	log.Println("组合流WebSocket连接成功")
-	go c.readMessages()
+	goBackground(c.readMessages)
 
 	return nil
 }
@@ -148,10 +146,10 @@ func (c *CombinedStreamsClient) handleCombinedMessage(message []byte) {
 		return
 	}
 
+	// 持有读锁完成发送（非阻塞），避免 Close 在查找与发送之间关闭该通道
 	c.mu.RLock()
+	defer c.mu.RUnlock()
 	ch, exists := c.subscribers[combinedMsg.Stream]
-	c.mu.RUnlock()
-
 	if exists {
 		select {
 		case ch <- combinedMsg.Data:
@@ -170,21 +168,27 @@ func (c *CombinedStreamsClient) AddSubscriber(stream string, bufferSize int) <-c
 }
 
 func (c *CombinedStreamsClient) handleReconnect() {
-	if !c.reconnect {
+	// 已关闭时不再重连（done 由 Close 关闭，可安全地并发读取）
+	select {
+	case <-c.done:
 		return
+	default:
 	}
 
 	log.Println("组合流尝试重新连接...")
-	time.Sleep(3 * time.Second)
+	select {
+	case <-c.done:
+		return
+	case <-time.After(3 * time.Second):
+	}
 
 	if err := c.Connect(); err != nil {
 		log.Printf("组合流重新连接失败: %v", err)
-		go c.handleReconnect()
+		goBackground(c.handleReconnect)
 	}
 }
 
 func (c *CombinedStreamsClient) Close() {
-	c.reconnect = false
 	close(c.done)
 
 	c.mu.Lock()
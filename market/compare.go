@@ -0,0 +1,165 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ComparisonMetrics 单个交易对用于横向对比的关键指标
+type ComparisonMetrics struct {
+	Symbol           string
+	Price            float64
+	RSI7             float64 // 3分钟 RSI7
+	RSI14_15m        float64 // 15分钟 RSI14 最新值
+	RSI14_4h         float64 // 4小时 RSI14 最新值
+	PriceChange1h    float64
+	PriceChange4h    float64
+	PriceChange1d    float64
+	MomentumScore    float64 // 动量评分：1h/4h/1d 价格变化百分比的均值
+	ATRPercent       float64 // 1小时 ATR14 / 当前价格 * 100，可跨币种比较波动率
+	FundingRate      float64
+	RelativeStrength float64 // 相对强弱：本币1天涨跌幅 - 对比币1天涨跌幅（百分点）
+}
+
+// Comparison 两个交易对的对比结果
+type Comparison struct {
+	A ComparisonMetrics
+	B ComparisonMetrics
+}
+
+// CompareSymbols 并发获取两个交易对的市场数据并提取关键指标进行对比
+func CompareSymbols(a, b string) (*Comparison, error) {
+	return compareSymbols(context.Background(), a, b, DefaultOptions())
+}
+
+// compareSymbols 按 opts 并发获取两个交易对的数据，任一失败即返回该交易对的错误
+func compareSymbols(ctx context.Context, a, b string, opts Options) (*Comparison, error) {
+	var dataA, dataB *Data
+	var errA, errB error
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		dataA, errA = GetWithOptions(ctx, a, opts)
+	}()
+	go func() {
+		defer wg.Done()
+		dataB, errB = GetWithOptions(ctx, b, opts)
+	}()
+	wg.Wait()
+
+	if errA != nil {
+		return nil, fmt.Errorf("获取 %s 市场数据失败: %w", a, errA)
+	}
+	if errB != nil {
+		return nil, fmt.Errorf("获取 %s 市场数据失败: %w", b, errB)
+	}
+
+	return compareData(dataA, dataB), nil
+}
+
+// compareData 基于已获取的市场数据构建对比结果
+func compareData(a, b *Data) *Comparison {
+	ma := comparisonMetrics(a)
+	mb := comparisonMetrics(b)
+	ma.RelativeStrength = ma.PriceChange1d - mb.PriceChange1d
+	mb.RelativeStrength = -ma.RelativeStrength
+	return &Comparison{A: ma, B: mb}
+}
+
+func comparisonMetrics(d *Data) ComparisonMetrics {
+	m := ComparisonMetrics{
+		Symbol:        d.Symbol,
+		Price:         d.CurrentPrice,
+		RSI7:          d.CurrentRSI7,
+		PriceChange1h: d.PriceChange1h,
+		PriceChange4h: d.PriceChange4h,
+		PriceChange1d: d.PriceChange1d,
+		MomentumScore: (d.PriceChange1h + d.PriceChange4h + d.PriceChange1d) / 3,
+		FundingRate:   d.FundingRate,
+	}
+	if d.Intraday15m != nil && len(d.Intraday15m.RSI14Values) > 0 {
+		m.RSI14_15m = d.Intraday15m.RSI14Values[len(d.Intraday15m.RSI14Values)-1]
+	}
+	if d.LongerTermContext != nil && len(d.LongerTermContext.RSI14Values) > 0 {
+		m.RSI14_4h = d.LongerTermContext.RSI14Values[len(d.LongerTermContext.RSI14Values)-1]
+	}
+	if d.Intraday1h != nil && d.CurrentPrice > 0 {
+		m.ATRPercent = d.Intraday1h.ATR14 / d.CurrentPrice * 100
+	}
+	return m
+}
+
+// FormatComparison 以两列对齐的形式输出对比结果
+func FormatComparison(c *Comparison) string {
+	if c == nil {
+		return ""
+	}
+
+	rows := []struct {
+		label string
+		a, b  string
+	}{
+		{"交易对", c.A.Symbol, c.B.Symbol},
+		{"当前价格", fmt.Sprintf("%.4f", c.A.Price), fmt.Sprintf("%.4f", c.B.Price)},
+		{"7期RSI(3m)", fmt.Sprintf("%.2f", c.A.RSI7), fmt.Sprintf("%.2f", c.B.RSI7)},
+		{"14期RSI(15m)", fmt.Sprintf("%.2f", c.A.RSI14_15m), fmt.Sprintf("%.2f", c.B.RSI14_15m)},
+		{"14期RSI(4h)", fmt.Sprintf("%.2f", c.A.RSI14_4h), fmt.Sprintf("%.2f", c.B.RSI14_4h)},
+		{"1小时涨跌", fmt.Sprintf("%.2f%%", c.A.PriceChange1h), fmt.Sprintf("%.2f%%", c.B.PriceChange1h)},
+		{"4小时涨跌", fmt.Sprintf("%.2f%%", c.A.PriceChange4h), fmt.Sprintf("%.2f%%", c.B.PriceChange4h)},
+		{"1天涨跌", fmt.Sprintf("%.2f%%", c.A.PriceChange1d), fmt.Sprintf("%.2f%%", c.B.PriceChange1d)},
+		{"动量评分", fmt.Sprintf("%.3f", c.A.MomentumScore), fmt.Sprintf("%.3f", c.B.MomentumScore)},
+		{"ATR%(1h)", fmt.Sprintf("%.3f%%", c.A.ATRPercent), fmt.Sprintf("%.3f%%", c.B.ATRPercent)},
+		{"资金费率", fmt.Sprintf("%.2e", c.A.FundingRate), fmt.Sprintf("%.2e", c.B.FundingRate)},
+		{"相对强弱", fmt.Sprintf("%+.2f", c.A.RelativeStrength), fmt.Sprintf("%+.2f", c.B.RelativeStrength)},
+	}
+
+	labelWidth, colWidth := 0, 0
+	for _, r := range rows {
+		labelWidth = maxInt(labelWidth, displayWidth(r.label))
+		colWidth = maxInt(colWidth, maxInt(displayWidth(r.a), displayWidth(r.b)))
+	}
+
+	var sb strings.Builder
+	for _, r := range rows {
+		sb.WriteString(padRight(r.label, labelWidth))
+		sb.WriteString("  ")
+		sb.WriteString(padLeft(r.a, colWidth))
+		sb.WriteString("  ")
+		sb.WriteString(padLeft(r.b, colWidth))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// displayWidth 终端显示宽度：中日韩等宽字符按2计算
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r >= 0x1100 && utf8.RuneLen(r) >= 3 {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", maxInt(0, width-displayWidth(s)))
+}
+
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", maxInt(0, width-displayWidth(s))) + s
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,113 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// symbolSource 按交易对返回K线的 KlineSource，未配置的交易对返回错误
+type symbolSource map[string][]Kline
+
+func (s symbolSource) GetCurrentKlines(symbol, interval string) ([]Kline, error) {
+	klines, ok := s[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%s 无K线数据", symbol)
+	}
+	return append([]Kline(nil), klines...), nil
+}
+
+// scaledKlines 将 testKlines 的价格按 factor 缩放
+func scaledKlines(n int, factor float64) []Kline {
+	klines := testKlines(n, 3*time.Minute)
+	for i := range klines {
+		k := &klines[i]
+		k.Open, k.High, k.Low, k.Close = k.Open*factor, k.High*factor, k.Low*factor, k.Close*factor
+	}
+	return klines
+}
+
+func TestCompareSymbols(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+	opts := DefaultOptions()
+	opts.Source = symbolSource{
+		"BTCUSDT": scaledKlines(100, 600),
+		"ETHUSDT": scaledKlines(100, 30),
+	}
+
+	c, err := compareSymbols(context.Background(), "btc", "ETHUSDT", opts)
+	if err != nil {
+		t.Fatalf("compareSymbols() error = %v", err)
+	}
+	if c.A.Symbol != "BTCUSDT" || c.B.Symbol != "ETHUSDT" {
+		t.Fatalf("交易对 = %s/%s, want BTCUSDT/ETHUSDT", c.A.Symbol, c.B.Symbol)
+	}
+	if c.A.Price <= c.B.Price || c.B.Price <= 0 {
+		t.Errorf("价格 = %v/%v, want BTC 高于 ETH 且均为正", c.A.Price, c.B.Price)
+	}
+	if c.A.RelativeStrength != -c.B.RelativeStrength {
+		t.Errorf("相对强弱 = %v/%v, want 互为相反数", c.A.RelativeStrength, c.B.RelativeStrength)
+	}
+
+	out := FormatComparison(c)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("输出 %d 行, want 12:\n%s", len(lines), out)
+	}
+	for _, want := range []string{
+		"BTCUSDT", "ETHUSDT",
+		fmt.Sprintf("%.4f", c.A.Price), fmt.Sprintf("%.4f", c.B.Price),
+		fmt.Sprintf("%+.2f", c.A.RelativeStrength), fmt.Sprintf("%+.2f", c.B.RelativeStrength),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+	// 交易对行：A 列在 B 列之前，且各行显示宽度一致
+	if first := lines[0]; strings.Index(first, "BTCUSDT") > strings.Index(first, "ETHUSDT") {
+		t.Errorf("列顺序错误: %q", first)
+	}
+	for _, line := range lines[1:] {
+		if displayWidth(line) != displayWidth(lines[0]) {
+			t.Errorf("行宽不一致: %q (%d) vs %q (%d)", line, displayWidth(line), lines[0], displayWidth(lines[0]))
+		}
+	}
+	if FormatComparison(nil) != "" {
+		t.Error("FormatComparison(nil) 应返回空字符串")
+	}
+}
+
+func TestCompareSymbolsPropagatesError(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+	opts := DefaultOptions()
+	opts.Source = symbolSource{"BTCUSDT": scaledKlines(100, 600)}
+
+	tests := []struct {
+		name    string
+		a, b    string
+		wantErr string
+		wantIs  error
+	}{
+		{"B 获取K线失败", "BTCUSDT", "ETHUSDT", "获取 ETHUSDT 市场数据失败", nil},
+		{"A 交易对不存在", "NOPEUSDT", "BTCUSDT", "获取 NOPEUSDT 市场数据失败", ErrUnknownSymbol},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := compareSymbols(context.Background(), tt.a, tt.b, opts)
+			if c != nil || err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("compareSymbols() = %v, %v; want 错误包含 %q", c, err, tt.wantErr)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("err = %v, want errors.Is %v", err, tt.wantIs)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package market
+
+// OversoldConfluenceMin 判定超卖共振所需的最少同时触发指标数量
+var OversoldConfluenceMin = 3
+
+// oversoldCheck 单个超卖条件，基于15分钟日内数据
+type oversoldCheck struct {
+	name  string
+	fired func(d *IntradayData) bool
+}
+
+// oversoldChecks 参与共振判断的超卖条件
+var oversoldChecks = []oversoldCheck{
+	{"RSI14<30", func(d *IntradayData) bool {
+		return len(d.RSI14Values) > 0 && d.RSI14Values[len(d.RSI14Values)-1] < 30
+	}},
+	{"Stochastic<20", func(d *IntradayData) bool {
+		// %K/%D 均为0表示K线不足未计算
+		return d.StochK < 20 && (d.StochK > 0 || d.StochD > 0)
+	}},
+	{"StochRSI<20", func(d *IntradayData) bool {
+		// StochRSI 为0也可能是K线不足未计算，要求 RSI 序列已形成
+		return d.StochRSI < 20 && len(d.RSI14Values) > 0
+	}},
+	{"WilliamsR<-80", func(d *IntradayData) bool {
+		return d.WilliamsR < -80
+	}},
+	{"CCI<-100", func(d *IntradayData) bool {
+		return d.CCI20 < -100
+	}},
+	{"%B<0", func(d *IntradayData) bool {
+		// 收盘价跌破布林带下轨
+		return d.BBUpper > d.BBLower && len(d.MidPrices) > 0 && d.MidPrices[len(d.MidPrices)-1] < d.BBLower
+	}},
+	{"MACD<0且柱状图回升", func(d *IntradayData) bool {
+		// MACDValues12269 对应的 MACD(默认12,26,9) DIF 位于零轴下方，柱状图为负但较上一根收窄，空头动能衰竭
+		dif, hist := d.MACDValues12269, d.MACDHist12269
+		if len(dif) == 0 || len(hist) < 2 {
+			return false
+		}
+		last, prev := hist[len(hist)-1], hist[len(hist)-2]
+		return dif[len(dif)-1] < 0 && last < 0 && last > prev
+	}},
+}
+
+// OversoldConfluence 判断15分钟周期上是否有至少 OversoldConfluenceMin 个超卖指标同时触发
+// 参与判断的指标：RSI14、随机指标、StochRSI、威廉指标、CCI20、布林带%B、MACD柱状图
+// 返回是否达到阈值以及触发的指标列表(按上述顺序)
+func OversoldConfluence(data *Data) (bool, []string) {
+	if data == nil || data.Intraday15m == nil {
+		return false, nil
+	}
+	var agreeing []string
+	for _, check := range oversoldChecks {
+		if check.fired(data.Intraday15m) {
+			agreeing = append(agreeing, check.name)
+		}
+	}
+	return len(agreeing) >= OversoldConfluenceMin, agreeing
+}
@@ -0,0 +1,121 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOversoldConfluence(t *testing.T) {
+	// 全部7个超卖条件均触发的15分钟数据
+	oversold := func() *IntradayData {
+		return &IntradayData{
+			RSI14Values:     []float64{35, 25},
+			StochK:          10,
+			StochD:          12,
+			StochRSI:        5,
+			WilliamsR:       -90,
+			CCI20:           -150,
+			BBUpper:         110,
+			BBLower:         90,
+			MidPrices:       []float64{92, 89},
+			MACDValues12269: []float64{-1.2, -1},
+			MACDHist12269:   []float64{-0.5, -0.3},
+		}
+	}
+	neutral := func() *IntradayData {
+		return &IntradayData{
+			RSI14Values:     []float64{50},
+			StochK:          50,
+			StochD:          50,
+			StochRSI:        50,
+			WilliamsR:       -50,
+			CCI20:           0,
+			BBUpper:         110,
+			BBLower:         90,
+			MidPrices:       []float64{100},
+			MACDValues12269: []float64{0.5},
+			MACDHist12269:   []float64{0.1, 0.2},
+		}
+	}
+	with := func(base func() *IntradayData, modify func(d *IntradayData)) *IntradayData {
+		d := base()
+		modify(d)
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		intraday *IntradayData
+		want     bool
+		wantList []string
+	}{
+		{"全部触发", oversold(), true, []string{"RSI14<30", "Stochastic<20", "StochRSI<20", "WilliamsR<-80", "CCI<-100", "%B<0", "MACD<0且柱状图回升"}},
+		{"均未触发", neutral(), false, nil},
+		{"仅RSI未达阈值", with(neutral, func(d *IntradayData) { d.RSI14Values = []float64{25} }), false, []string{"RSI14<30"}},
+		{"RSI+StochRSI+MACD", with(neutral, func(d *IntradayData) {
+			d.RSI14Values = []float64{25}
+			d.StochRSI = 10
+			d.MACDValues12269 = []float64{-1}
+			d.MACDHist12269 = []float64{-0.5, -0.3}
+		}), true, []string{"RSI14<30", "StochRSI<20", "MACD<0且柱状图回升"}},
+		{"RSI+%B 共两个", with(neutral, func(d *IntradayData) {
+			d.RSI14Values = []float64{25}
+			d.MidPrices = []float64{89}
+		}), false, []string{"RSI14<30", "%B<0"}},
+		{"MACD柱状图继续放大不算", with(oversold, func(d *IntradayData) { d.MACDHist12269 = []float64{-0.3, -0.5} }), true,
+			[]string{"RSI14<30", "Stochastic<20", "StochRSI<20", "WilliamsR<-80", "CCI<-100", "%B<0"}},
+		{"DIF在零轴上方不算", with(oversold, func(d *IntradayData) { d.MACDValues12269 = []float64{0.2} }), true,
+			[]string{"RSI14<30", "Stochastic<20", "StochRSI<20", "WilliamsR<-80", "CCI<-100", "%B<0"}},
+		{"K线不足(零值)", &IntradayData{}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, list := OversoldConfluence(&Data{Intraday15m: tt.intraday})
+			if got != tt.want || !reflect.DeepEqual(list, tt.wantList) {
+				t.Errorf("OversoldConfluence = %v, %v; want %v, %v", got, list, tt.want, tt.wantList)
+			}
+		})
+	}
+
+	if ok, list := OversoldConfluence(nil); ok || list != nil {
+		t.Errorf("OversoldConfluence(nil) = %v, %v; want false, nil", ok, list)
+	}
+	if ok, list := OversoldConfluence(&Data{}); ok || list != nil {
+		t.Errorf("缺少15分钟数据时 = %v, %v; want false, nil", ok, list)
+	}
+}
+
+func TestOversoldConfluenceMin(t *testing.T) {
+	prev := OversoldConfluenceMin
+	t.Cleanup(func() { OversoldConfluenceMin = prev })
+
+	data := &Data{Intraday15m: &IntradayData{RSI14Values: []float64{25}, StochRSI: 10, StochK: 50, WilliamsR: -50}}
+	for _, tt := range []struct {
+		min  int
+		want bool
+	}{{1, true}, {2, true}, {3, false}} {
+		OversoldConfluenceMin = tt.min
+		if got, _ := OversoldConfluence(data); got != tt.want {
+			t.Errorf("OversoldConfluenceMin=%d: got %v, want %v", tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestOversoldConfluenceFromKlines(t *testing.T) {
+	// 持续下跌后收盘跌破布林带下轨
+	closes := append(stepCloses(80, 200, -0.5), stepCloses(20, 159, -2)...)
+	data := &Data{Intraday15m: calculateIntradaySeries(closeKlines(0.5, closes...), DefaultIndicatorConfig().Intraday)}
+	ok, list := OversoldConfluence(data)
+	if !ok {
+		t.Fatalf("持续下跌应触发超卖共振, 触发 %v", list)
+	}
+	for _, name := range []string{"RSI14<30", "WilliamsR<-80", "CCI<-100"} {
+		found := false
+		for _, got := range list {
+			found = found || got == name
+		}
+		if !found {
+			t.Errorf("触发列表 %v 缺少 %s", list, name)
+		}
+	}
+}
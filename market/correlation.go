@@ -0,0 +1,89 @@
+package market
+
+import (
+	"fmt"
+	"math"
+)
+
+// btcSymbol 相关性计算的基准交易对
+const btcSymbol = "BTCUSDT"
+
+// GetBTCCorrelation 计算交易对与BTC在指定周期上最近 window 个收益率的皮尔逊相关系数
+// 两组K线按开盘时间对齐，仅使用双方都存在的相邻K线计算收益率。
+// 结果接近1说明该币基本跟随BTC波动，接近0或为负说明走势相对独立。
+func GetBTCCorrelation(symbol string, interval string, window int) (float64, error) {
+	if window < 2 {
+		return 0, fmt.Errorf("相关性窗口至少为2，当前为 %d", window)
+	}
+	symbol = Normalize(symbol)
+	if symbol == btcSymbol {
+		return 1, nil
+	}
+
+	klines, err := WSMonitorCli.GetCurrentKlines(symbol, interval)
+	if err != nil {
+		return 0, fmt.Errorf("获取%s %s K线失败: %v", symbol, interval, err)
+	}
+	btcKlines, err := WSMonitorCli.GetCurrentKlines(btcSymbol, interval)
+	if err != nil {
+		return 0, fmt.Errorf("获取%s %s K线失败: %v", btcSymbol, interval, err)
+	}
+
+	returns, btcReturns := alignedReturns(klines, btcKlines)
+	if len(returns) < window {
+		return 0, fmt.Errorf("对齐后的收益率数量(%d)不足窗口(%d)", len(returns), window)
+	}
+	return pearsonCorrelation(returns[len(returns)-window:], btcReturns[len(btcReturns)-window:])
+}
+
+// alignedReturns 按开盘时间对齐两组K线，返回相邻对齐K线之间的收益率序列
+func alignedReturns(a, b []Kline) ([]float64, []float64) {
+	bIndex := make(map[int64]int, len(b))
+	for i, k := range b {
+		bIndex[k.OpenTime] = i
+	}
+
+	var ra, rb []float64
+	prevA, prevB := -1, -1
+	for i, k := range a {
+		j, ok := bIndex[k.OpenTime]
+		if !ok {
+			prevA, prevB = -1, -1
+			continue
+		}
+		// 仅在双方都是紧邻的上一根K线时计算收益率，避免跨越缺口
+		if prevA >= 0 && prevA == i-1 && prevB == j-1 && a[prevA].Close > 0 && b[prevB].Close > 0 {
+			ra = append(ra, (k.Close-a[prevA].Close)/a[prevA].Close)
+			rb = append(rb, (b[j].Close-b[prevB].Close)/b[prevB].Close)
+		}
+		prevA, prevB = i, j
+	}
+	return ra, rb
+}
+
+// pearsonCorrelation 计算两个等长序列的皮尔逊相关系数，任一序列方差为0时返回错误
+func pearsonCorrelation(x, y []float64) (float64, error) {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0, fmt.Errorf("序列长度无效: %d vs %d", len(x), len(y))
+	}
+	n := float64(len(x))
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, fmt.Errorf("收益率方差为0，相关系数无定义")
+	}
+	return cov / math.Sqrt(varX*varY), nil
+}
@@ -0,0 +1,78 @@
+package market
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// returnKlines 从 100 开始按收益率序列生成1小时K线，offset 为第一根K线的序号
+func returnKlines(offset int, returns []float64) []Kline {
+	step := time.Hour.Milliseconds()
+	klines := make([]Kline, len(returns)+1)
+	price := 100.0
+	for i := range klines {
+		if i > 0 {
+			price *= 1 + returns[i-1]
+		}
+		open := int64(offset+i) * step
+		klines[i] = Kline{OpenTime: open, CloseTime: open + step - 1, Open: price, High: price, Low: price, Close: price}
+	}
+	return klines
+}
+
+func TestGetBTCCorrelation(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{})
+	m := useTestMonitor(t)
+	WSMonitorCli = m
+
+	btcReturns := make([]float64, 40)
+	negated := make([]float64, len(btcReturns))
+	flat := make([]float64, len(btcReturns))
+	for i := range btcReturns {
+		btcReturns[i] = 0.01 * math.Sin(float64(i)*0.7)
+		negated[i] = -btcReturns[i]
+	}
+	m.getKlineDataMap("1h").Store("BTCUSDT", returnKlines(0, btcReturns))
+	m.getKlineDataMap("1h").Store("ETHUSDT", returnKlines(0, btcReturns))
+	m.getKlineDataMap("1h").Store("SOLUSDT", returnKlines(0, negated))
+	m.getKlineDataMap("1h").Store("XRPUSDT", returnKlines(0, flat))
+	// 只有最近20根，与BTC按开盘时间对齐
+	m.getKlineDataMap("1h").Store("ADAUSDT", returnKlines(20, btcReturns[20:]))
+
+	tests := []struct {
+		name    string
+		symbol  string
+		window  int
+		want    float64
+		wantErr string // 为空表示期望成功
+	}{
+		{"完全正相关", "ETHUSDT", 30, 1, ""},
+		{"完全负相关", "SOLUSDT", 30, -1, ""},
+		{"BTC自身", "btc", 30, 1, ""},
+		{"长度不一致按时间对齐", "ADAUSDT", 20, 1, ""},
+		{"对齐后数量不足", "ADAUSDT", 21, 0, "不足窗口"},
+		{"方差为0", "XRPUSDT", 30, 0, "方差为0"},
+		{"窗口过小", "ETHUSDT", 1, 0, "窗口至少为2"},
+		{"K线获取失败", "NOPEUSDT", 30, 0, "获取NOPEUSDT 1h K线失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetBTCCorrelation(tt.symbol, "1h", tt.window)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetBTCCorrelation() = %v, %v; want 错误包含 %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBTCCorrelation() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("GetBTCCorrelation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
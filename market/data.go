@@ -1,123 +1,219 @@
 package market
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"math"
-	"net/http"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
+// UseLogReturns 为 true 时，RSI/MACD 等动量指标基于累计对数收益率 ln(close/close₀) 计算，而非原始收盘价
+// 解读差异：
+//   - MACD(DIF/DEA) 变为对数价格空间的均线差，数值近似为相对幅度(0.01≈1%)，不再是价格单位，可跨币种比较；
+//   - RSI 的涨跌幅改为对数收益率，长期大幅波动的币种更一致，数值与价格RSI接近但不完全相同；
+//   - EMA、ATR、价格变化与成交量等仍保持原始价格尺度。
+var UseLogReturns = false
+
 // Get 获取指定代币的市场数据
 func Get(symbol string) (*Data, error) {
-	var klines3m, klines4h []Kline
-	var err error
+	return GetContext(context.Background(), symbol)
+}
+
+// GetContext 获取指定代币的市场数据，支持通过 ctx 取消或设置超时
+// ctx 在获取过程中被取消时立即返回（错误满足 errors.Is(err, ctx.Err())），不再等待剩余周期的请求完成
+func GetContext(ctx context.Context, symbol string) (*Data, error) {
+	return getWithConfig(ctx, symbol, DefaultIndicatorConfig(), DefaultOptions())
+}
+
+// GetWithConfig 使用自定义指标周期获取市场数据，配置非法时在发起请求前直接返回错误
+// 未设置 Intraday/LongerTerm 时这两组序列指标使用默认周期
+func GetWithConfig(symbol string, cfg IndicatorConfig) (*Data, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("指标配置无效: %w", err)
+	}
+	return getWithConfig(context.Background(), symbol, cfg, DefaultOptions())
+}
+
+func getWithConfig(ctx context.Context, symbol string, cfg IndicatorConfig, opts Options) (*Data, error) {
 	// 标准化symbol
 	symbol = Normalize(symbol)
-	// 获取3分钟K线数据 (最近10个)
-	klines3m, err = WSMonitorCli.GetCurrentKlines(symbol, "3m") // 多获取一些用于计算
-	if err != nil {
-		return nil, fmt.Errorf("获取3分钟K线失败: %v", err)
-	}
 
-	// 获取4小时K线数据 (最近10个)
-	klines4h, err = WSMonitorCli.GetCurrentKlines(symbol, "4h") // 多获取用于计算指标
-	if err != nil {
-		return nil, fmt.Errorf("获取4小时K线失败: %v", err)
+	// 预检合约交易对是否存在，避免对无效/已下架交易对发起全部请求；exchangeInfo 不可用时跳过预检
+	// 预检同时预热价格精度缓存，Format 只读该缓存
+	// 历史模式(EndTime)下已下架交易对的历史数据仍然有效，同样跳过
+	if opts.market() == Futures && opts.EndTime.IsZero() {
+		exists, err := symbolExists(ctx, symbol)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			logger.Warnf("预检交易对 %s 失败，跳过: %v", symbol, err)
+		} else if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSymbol, symbol)
+		}
 	}
 
-	// 新增15m数据
-	klines15m, err := WSMonitorCli.GetCurrentKlines(symbol, "15m")
-	if err != nil {
-		return nil, fmt.Errorf("获取15分钟K线失败: %v", err)
+	// 并发获取各周期K线（默认 3m/15m/1h/4h/1d）与合约衍生数据，总耗时约等于最慢的单个请求
+	// 任一周期K线获取失败即取消其余请求并返回错误；衍生数据失败不致命
+	g, gctx := errgroup.WithContext(ctx)
+	fetched := make([][]Kline, len(opts.Timeframes))
+	for i, tf := range opts.Timeframes {
+		i, tf := i, tf
+		g.Go(func() error {
+			start := time.Now()
+			klines, err := fetchMarketKlines(gctx, opts, symbol, tf)
+			logFetch(symbol, tf+" K线", start, err)
+			if err != nil {
+				return fmt.Errorf("获取%s K线失败: %w", tf, err)
+			}
+			if len(klines) == 0 {
+				return fmt.Errorf("获取%s K线失败: 无数据", tf)
+			}
+			fetched[i] = klines
+			return nil
+		})
 	}
 
-	// 新增1h数据
-	klines1h, err := WSMonitorCli.GetCurrentKlines(symbol, "1h")
-	if err != nil {
-		return nil, fmt.Errorf("获取1小时K线失败: %v", err)
+	// 合约衍生数据：OI、资金费率、多空比、主动买卖比（现货模式下不存在，保持为空）
+	// 历史模式(EndTime)下这些接口只能返回当前值，为避免混入未来数据同样不获取
+	var deriv derivativesData
+	if opts.Market != Spot && opts.EndTime.IsZero() {
+		g.Go(func() error {
+			deriv = fetchDerivatives(gctx, symbol, opts)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
-	// 新增1d数据
-	klines1d, err := WSMonitorCli.GetCurrentKlines(symbol, "1d")
-	if err != nil {
-		return nil, fmt.Errorf("获取1天K线失败: %v", err)
+	// OI/资金费率/多空比失败本身不致命，但若是因为 ctx 取消则直接返回
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// 计算当前指标 (基于3分钟最新数据)
-	currentPrice := klines3m[len(klines3m)-1].Close
-	currentEMA20 := calculateEMA(klines3m, 20)
-	dif, _, _ := calculateMACD(klines3m, 12, 26, 9)
+	// 按请求顺序校验，保证跳过日志与 Warnings 的顺序稳定
+	klinesByTF := make(map[string][]Kline, len(opts.Timeframes))
+	var warnings []string // K线不足导致无法计算的指标
+	for i, tf := range opts.Timeframes {
+		klines := opts.limitKlines(fetched[i])
+		if err := ValidateKlines(klines); err != nil {
+			if !opts.SkipInvalidKlines {
+				return nil, fmt.Errorf("%s K线数据异常: %w", tf, err)
+			}
+			logger.Warnf("%s %s K线数据异常，跳过该周期: %v", symbol, tf, err)
+			continue
+		}
+		klinesByTF[tf] = klines
+		warnings = append(warnings, klineWarnings(tf, len(klines))...)
+	}
+	if len(klinesByTF) == 0 {
+		return nil, fmt.Errorf("%s 没有可用的K线数据", symbol)
+	}
+	klines3m := klinesByTF["3m"]
+	klines15m := klinesByTF["15m"]
+	klines1h := klinesByTF["1h"]
+	klines4h := klinesByTF["4h"]
+	klines1d := klinesByTF["1d"]
+
+	// 计算当前指标 (基于3分钟最新数据，未请求3m时使用最短周期)
+	primary := klines3m
+	if primary == nil {
+		available := make([]string, 0, len(klinesByTF))
+		for tf := range klinesByTF {
+			available = append(available, tf)
+		}
+		primary = klinesByTF[shortestTimeframe(available)]
+	}
+	currentPrice := primary[len(primary)-1].Close
+	currentEMA20 := calculateEMA(primary, cfg.EMAPeriod)
+	momentum := momentumKlines(primary)
+	dif, _, _ := calculateMACD(momentum, cfg.MACDShort, cfg.MACDLong, cfg.MACDSignal)
 	currentMACD := dif
-	currentRSI7 := calculateRSI(klines3m, 7)
+	currentRSI7 := calculateRSI(momentum, cfg.RSIPeriod)
 
 	// 计算价格变化百分比
 
 	// 3分钟价格变化（当前与上一根3m）
-	priceChange3m := 0.0
-	if len(klines3m) >= 2 {
-		prev := klines3m[len(klines3m)-2].Close
-		if prev > 0 {
-			priceChange3m = ((currentPrice - prev) / prev) * 100
-		}
-	}
+	priceChange3m := priceChangeFromPrev(currentPrice, klines3m)
 
-	// 1小时价格变化 = 20个3分钟K线前的价格
+	// 1小时价格变化 = 20个3分钟K线前的价格；未请求3m时使用上一根1h K线
 	priceChange1h := 0.0
-	if len(klines3m) >= 21 { // 至少需要21根K线 (当前 + 20根前)
+	if klines3m == nil {
+		priceChange1h = priceChangeFromPrev(currentPrice, klines1h)
+	} else if len(klines3m) >= 21 { // 至少需要21根K线 (当前 + 20根前)
 		price1hAgo := klines3m[len(klines3m)-21].Close
 		if price1hAgo > 0 {
 			priceChange1h = ((currentPrice - price1hAgo) / price1hAgo) * 100
 		}
 	}
 
-	// 4小时价格变化 = 1个4小时K线前的价格
-	priceChange4h := 0.0
-	if len(klines4h) >= 2 {
-		price4hAgo := klines4h[len(klines4h)-2].Close
-		if price4hAgo > 0 {
-			priceChange4h = ((currentPrice - price4hAgo) / price4hAgo) * 100
-		}
+	// 4小时/15分钟/1天价格变化 = 上一根对应周期K线的收盘价
+	priceChange4h := priceChangeFromPrev(currentPrice, klines4h)
+	priceChange15m := priceChangeFromPrev(currentPrice, klines15m)
+	priceChange1d := priceChangeFromPrev(currentPrice, klines1d)
+
+	// 1小时摆动点支撑/阻力
+	supports, resistances := DetectLevels(klines1h, levelLookback)
+	nearestSupport, nearestResistance := nearestLevels(currentPrice, supports, resistances)
+
+	oiData, funding := deriv.oi, deriv.funding
+	var fundingRate float64
+	if funding != nil {
+		fundingRate = funding.Rate
 	}
+	fundingHistory, longShort := deriv.fundingHistory, deriv.longShort
+	takerBuySellRatio, liquidations, depth := deriv.takerBuySellRatio, deriv.liquidations, deriv.depth
 
-	priceChange15m := 0.0
-	if len(klines15m) >= 2 { // 15分钟前的价格（1根15分钟K线）
-		price15mAgo := klines15m[len(klines15m)-2].Close
-		if price15mAgo > 0 {
-			priceChange15m = ((currentPrice - price15mAgo) / price15mAgo) * 100
+	// 计算各时间框架的指标数据（开启 HeikinAshi 时基于平均K线计算）
+	timeframes := make(map[string]*IntradayData, len(klinesByTF))
+	for tf, klines := range klinesByTF {
+		if opts.HeikinAshi {
+			klines = ToHeikinAshi(klines)
 		}
+		timeframes[tf] = calculateIntradaySeries(klines, cfg.Intraday)
 	}
-	priceChange1d := 0.0
-	if len(klines1d) >= 2 { // 1天前的价格（1根1天K线）
-		price1dAgo := klines1d[len(klines1d)-2].Close
-		if price1dAgo > 0 {
-			priceChange1d = ((currentPrice - price1dAgo) / price1dAgo) * 100
-		}
+	intradayData := timeframes["3m"] // 3分钟
+	intraday15m := timeframes["15m"] // 15分钟
+	intraday1h := timeframes["1h"]   // 1小时
+	var longerTermData, longerTerm1d *LongerTermData
+	if klines4h != nil {
+		longerTermData = calculateLongerTermData(klines4h, cfg.LongerTerm) // 4小时
 	}
-
-	// 获取OI数据
-	oiData, err := getOpenInterestData(symbol)
-	if err != nil {
-		// OI失败不影响整体,使用默认值
-		oiData = &OIData{Latest: 0, Average: 0}
+	if klines1d != nil {
+		longerTerm1d = calculateLongerTermData(klines1d, cfg.LongerTerm) // 1天
+	}
+	var longerTerm1w, longerTerm1Mo *LongerTermData
+	if klines1w := klinesByTF["1w"]; klines1w != nil {
+		longerTerm1w = calculateLongerTermData(klines1w, cfg.LongerTerm) // 1周
+	}
+	if klines1M := klinesByTF["1M"]; klines1M != nil {
+		longerTerm1Mo = calculateLongerTermData(klines1M, cfg.LongerTerm) // 1月
 	}
 
-	// 获取Funding Rate
-	fundingRate, _ := getFundingRate(symbol)
+	// 价量+OI协同效率（现货无OI，按OI不变计算）
+	var oiChange5m, oiChange15m, oiChange1h float64
+	if oiData != nil {
+		oiChange5m, oiChange15m, oiChange1h = oiData.Change5m, oiData.Change15m, oiData.Change1h
+	}
+	effort3m := computeEffortResult(priceChange3m, intradayData, oiChange5m)
+	effort15m := computeEffortResult(priceChange15m, intraday15m, oiChange15m)
+	effort1h := computeEffortResult(priceChange1h, intraday1h, oiChange1h)
 
-	// 计算各时间框架的指标数据
-	intradayData := calculateIntradaySeries(klines3m)   // 3分钟
-	intraday15m := calculateIntradaySeries(klines15m)   // 15分钟
-	intraday1h := calculateIntradaySeries(klines1h)     // 1小时
-	longerTermData := calculateLongerTermData(klines4h) // 4小时
-	longerTerm1d := calculateLongerTermData(klines1d)   // 1天
+	// 1小时MACD柱状图背离，周期与当前MACD指标一致
+	macdBullDiv1h, macdBearDiv1h := DetectMACDDivergence(klines1h, cfg.MACDShort, cfg.MACDLong, cfg.MACDSignal)
 
-	return &Data{
+	data := &Data{
 		Symbol:            symbol,
+		Market:            opts.market(),
 		CurrentPrice:      currentPrice,
 		PriceChange3m:     priceChange3m,
 		PriceChange15m:    priceChange15m, // 新增
@@ -129,18 +225,127 @@ func Get(symbol string) (*Data, error) {
 		CurrentRSI7:       currentRSI7,
 		OpenInterest:      oiData,
 		FundingRate:       fundingRate,
+		Funding:           funding,
+		FundingHistory:    fundingHistory,
+		FundingTrend:      classifyFundingTrend(fundingHistory),
+		FundingPercentile: percentileRank(fundingRate, fundingHistory),
+		LongShort:         longShort,
+		TakerBuySellRatio: takerBuySellRatio,
+		Liquidations:      liquidations,
+		Depth:             depth,
+		Ticker:            deriv.ticker,
 		IntradaySeries:    intradayData,
 		LongerTermContext: longerTermData,
 		Intraday15m:       intraday15m,  // 新增
 		Intraday1h:        intraday1h,   // 新增
 		LongerTerm1d:      longerTerm1d, // 新增
-		EffortResult3m:    computeEffortResult(priceChange3m, intradayData, oiData.Change5m),
-		EffortResult15m:   computeEffortResult(priceChange15m, intraday15m, oiData.Change15m),
-		EffortResult1h:    computeEffortResult(priceChange1h, intraday1h, oiData.Change1h),
-		EffortLabel3m:     classifyEffortResult(computeEffortResult(priceChange3m, intradayData, oiData.Change5m)),
-		EffortLabel15m:    classifyEffortResult(computeEffortResult(priceChange15m, intraday15m, oiData.Change15m)),
-		EffortLabel1h:     classifyEffortResult(computeEffortResult(priceChange1h, intraday1h, oiData.Change1h)),
-	}, nil
+		LongerTerm1w:      longerTerm1w,
+		LongerTerm1Mo:     longerTerm1Mo,
+		Timeframes:        timeframes,
+		EffortResult3m:    effort3m,
+		EffortResult15m:   effort15m,
+		EffortResult1h:    effort1h,
+		EffortLabel3m:     classifyEffortResult(effort3m),
+		EffortLabel15m:    classifyEffortResult(effort15m),
+		EffortLabel1h:     classifyEffortResult(effort1h),
+
+		MACDBullishDivergence1h: macdBullDiv1h,
+		MACDBearishDivergence1h: macdBearDiv1h,
+		Fakeout15m:              DetectFakeout(klines15m, fakeoutLookback),
+		NearestSupport:          nearestSupport,
+		NearestResistance:       nearestResistance,
+		Patterns3m:              DetectPatterns(klines3m),
+
+		LatestKlineTimes: latestKlineTimes(klinesByTF),
+		LastUpdate:       Timestamp{time.UnixMilli(primary[len(primary)-1].CloseTime)},
+		Partial:          len(warnings) > 0,
+		Warnings:         warnings,
+	}
+
+	if !opts.OmitKlines {
+		data.Klines = klinesByTF
+	}
+	return data, nil
+}
+
+// priceChangeFromPrev 当前价格相对上一根K线收盘价的百分比变化，K线不足2根时返回0
+func priceChangeFromPrev(currentPrice float64, klines []Kline) float64 {
+	if len(klines) < 2 {
+		return 0
+	}
+	prev := klines[len(klines)-2].Close
+	if prev <= 0 {
+		return 0
+	}
+	return ((currentPrice - prev) / prev) * 100
+}
+
+// fetchMarketKlines 按市场类型获取K线：合约走 Options.Source(默认 WSMonitorCli 缓存)，现货直接请求现货 REST 接口
+// 设置了 EndTime 时改为通过 REST 获取截至该时间的历史K线；DerivedTimeframes 中的周期由基础周期重采样得到
+func fetchMarketKlines(ctx context.Context, opts Options, symbol, interval string) ([]Kline, error) {
+	if base, ok := opts.DerivedTimeframes[interval]; ok {
+		return fetchDerivedKlines(ctx, opts, symbol, base, interval)
+	}
+	limit := opts.KlineLimit
+	if limit <= 0 {
+		limit = defaultKlineLimit
+	}
+	if !opts.EndTime.IsZero() {
+		return fetchKlinesAt(ctx, opts.market(), symbol, interval, limit, opts.EndTime)
+	}
+	if opts.Market == Spot {
+		return fetchSpotKlines(ctx, symbol, interval, limit)
+	}
+	klines, err := fetchKlines(ctx, opts.klineSource(), symbol, interval, opts.klineTimeout())
+	if errors.Is(err, errKlineTimeout) {
+		logger.Warnf("%s %s K线数据源 %v 内未返回，改用 REST 接口", symbol, interval, opts.klineTimeout())
+		return fetchFuturesKlines(ctx, symbol, interval, limit)
+	}
+	return klines, err
+}
+
+// fetchDerivedKlines 获取基础周期K线并重采样为目标周期（见 Options.DerivedTimeframes）
+func fetchDerivedKlines(ctx context.Context, opts Options, symbol, base, target string) ([]Kline, error) {
+	klines, err := fetchMarketKlines(ctx, opts, symbol, base)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := intervalDuration(base)
+	to, _ := intervalDuration(target)
+	return Resample(klines, from, to)
+}
+
+// errKlineTimeout K线数据源在超时时间内未返回
+var errKlineTimeout = errors.New("K线数据源响应超时")
+
+// fetchKlines 从数据源获取K线，ctx 取消时立即返回 ctx.Err()，超过 timeout 未返回时返回 errKlineTimeout
+// GetCurrentKlines 本身不感知 ctx，被放弃的请求会在后台结束，结果丢弃
+func fetchKlines(ctx context.Context, src KlineSource, symbol, interval string, timeout time.Duration) ([]Kline, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type result struct {
+		klines []Kline
+		err    error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		start := time.Now()
+		klines, err := src.GetCurrentKlines(symbol, interval)
+		observeFetch(wsKlinesEndpoint, start, err)
+		ch <- result{klines: klines, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.klines, r.err
+	case <-timer.C:
+		return nil, errKlineTimeout
+	}
 }
 
 // computeEffortResult 计算价量+OI协同效率
@@ -184,97 +389,116 @@ func classifyEffortResult(r float64) string {
 	}
 }
 
-// calculateEMA 计算EMA
+// momentumKlines 返回用于动量指标(RSI/MACD)计算的K线
+// 未开启 UseLogReturns 时原样返回；开启时返回副本，收盘价替换为相对首根K线的累计对数收益率，
+// 非正收盘价沿用上一值，避免对数无意义
+func momentumKlines(klines []Kline) []Kline {
+	if !UseLogReturns || len(klines) == 0 || klines[0].Close <= 0 {
+		return klines
+	}
+	base := klines[0].Close
+	result := make([]Kline, len(klines))
+	copy(result, klines)
+	prev := 0.0
+	for i := range result {
+		if result[i].Close > 0 {
+			prev = math.Log(result[i].Close / base)
+		}
+		result[i].Close = prev
+	}
+	return result
+}
+
+// calculateEMA 计算EMA最新值
+// 种子始终为序列前 period 根K线的SMA，之后逐根递推，与 emaSeries 同一口径：
+// 对任意前缀 klines[:i+1] 调用的结果等于 emaSeries(klines, period)[i]，因此逐点EMA构成连续曲线
 func calculateEMA(klines []Kline, period int) float64 {
-	if len(klines) < period {
+	if period <= 0 || len(klines) < period {
 		return 0
 	}
+	return emaSeries(klines, period)[len(klines)-1]
+}
 
-	// 计算SMA作为初始EMA
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += klines[i].Close
+// calculateMACD 计算MACD指标的正确实现
+// 参数: klines - K线数据切片, shortPeriod - 短期EMA周期(如12), longPeriod - 长期EMA周期(如26), signalPeriod - 信号线周期(如9)
+// 返回值: dif - 快线, dea - 慢线(信号线), histogram - 柱状值
+func calculateMACD(klines []Kline, shortPeriod, longPeriod, signalPeriod int) (float64, float64, float64) {
+	if len(klines) == 0 {
+		return 0, 0, 0
 	}
-	ema := sum / float64(period)
+	// 复用一次前向计算的 EMA/DIF/DEA 序列，取最后一个点
+	dif, dea, hist, _ := macdSeries(klines, shortPeriod, longPeriod, signalPeriod)
+	last := len(klines) - 1
+	return dif[last], dea[last], hist[last]
+}
 
-	// 计算EMA
-	multiplier := 2.0 / float64(period+1)
-	for i := period; i < len(klines); i++ {
-		ema = (klines[i].Close-ema)*multiplier + ema
+// emaSeries 逐根计算收盘价EMA序列，与 calculateEMA(klines[:i+1], period) 逐点对齐
+// 前 period-1 个位置无有效值，填0
+func emaSeries(klines []Kline, period int) []float64 {
+	closes := make([]float64, len(klines))
+	for i, k := range klines {
+		closes[i] = k.Close
 	}
-
-	return ema
+	return emaOfValues(closes, period)
 }
 
-// calculateEMAOfDIF 计算DIF序列的EMA（即DEA信号线）
-func calculateEMAOfDIF(difSeries []float64, signalPeriod int) float64 {
-	if len(difSeries) < signalPeriod {
-		return 0
+// emaOfValues 对任意数值序列计算EMA序列（以前period个值的SMA为种子）
+// 前 period-1 个位置无有效值，填0；长度不足时全部为0
+func emaOfValues(values []float64, period int) []float64 {
+	result := make([]float64, len(values))
+	if period <= 0 || len(values) < period {
+		return result
 	}
 
-	// 计算前signalPeriod个DIF值的SMA作为初始EMA
 	sum := 0.0
-	for i := 0; i < signalPeriod; i++ {
-		sum += difSeries[i]
+	for i := 0; i < period; i++ {
+		sum += values[i]
 	}
-	ema := sum / float64(signalPeriod)
+	ema := sum / float64(period)
+	result[period-1] = ema
 
-	// 计算后续的EMA值
-	multiplier := 2.0 / float64(signalPeriod+1)
-	for i := signalPeriod; i < len(difSeries); i++ {
-		ema = (difSeries[i]-ema)*multiplier + ema
+	multiplier := 2.0 / float64(period+1)
+	for i := period; i < len(values); i++ {
+		ema = (values[i]-ema)*multiplier + ema
+		result[i] = ema
 	}
-
-	return ema
+	return result
 }
 
-// buildDIFSeries 构建DIF值序列
-func buildDIFSeries(klines []Kline, shortPeriod, longPeriod int) []float64 {
-	var difSeries []float64
-	// 从第 longPeriod 根K线开始，才能计算出有效的EMA(longPeriod)
-	for i := longPeriod - 1; i < len(klines); i++ {
-		// 截取从开始到当前K线的子切片计算EMA
-		subKlines := klines[:i+1]
-		emaS := calculateEMA(subKlines, shortPeriod)
-		emaL := calculateEMA(subKlines, longPeriod)
-		difSeries = append(difSeries, emaS-emaL)
-	}
-	return difSeries
-}
+// macdSeries 计算与 klines 逐点对齐的 DIF/DEA/柱状图序列
+// 返回 start 为第一个 DEA 有效的下标(之前的 DEA/柱状图为0)；数据不足时 start = len(klines)
+func macdSeries(klines []Kline, shortPeriod, longPeriod, signalPeriod int) (dif, dea, hist []float64, start int) {
+	n := len(klines)
+	dif = make([]float64, n)
+	dea = make([]float64, n)
+	hist = make([]float64, n)
 
-// calculateMACD 计算MACD指标的正确实现
-// 参数: klines - K线数据切片, shortPeriod - 短期EMA周期(如12), longPeriod - 长期EMA周期(如26), signalPeriod - 信号线周期(如9)
-// 返回值: dif - 快线, dea - 慢线(信号线), histogram - 柱状值
-func calculateMACD(klines []Kline, shortPeriod, longPeriod, signalPeriod int) (float64, float64, float64) {
-	// 1. 数据长度检查
-	totalPeriod := longPeriod
+	longest := longPeriod
 	if shortPeriod > longPeriod {
-		totalPeriod = shortPeriod
+		longest = shortPeriod
 	}
-	if len(klines) < totalPeriod {
-		return 0, 0, 0
+	if n < longest {
+		return dif, dea, hist, n
 	}
 
-	// 2. 计算DIF = EMA(close, short) - EMA(close, long)
-	emaShort := calculateEMA(klines, shortPeriod)
-	emaLong := calculateEMA(klines, longPeriod)
-	dif := emaShort - emaLong
-
-	// 3. 关键：需要先构建历史的DIF值序列，才能计算DEA
-	// 获取从开始到当前的所有DIF值（需要一个辅助函数）
-	difSeries := buildDIFSeries(klines, shortPeriod, longPeriod)
-	if len(difSeries) < signalPeriod {
-		return dif, 0, 0 // 如果DIF序列长度不足，无法计算有效的DEA
+	emaShort := emaSeries(klines, shortPeriod)
+	emaLong := emaSeries(klines, longPeriod)
+	difStart := longPeriod - 1
+	for i := longest - 1; i < n; i++ {
+		dif[i] = emaShort[i] - emaLong[i]
 	}
 
-	// 4. 计算DEA = EMA(DIF序列, signalPeriod)
-	dea := calculateEMAOfDIF(difSeries, signalPeriod)
-
-	// 5. 计算MACD柱状图 (Histogram)
-	histogram := dif - dea
-
-	// return dif, dea, histogram  （快线） （慢线）（柱状图）
-	return dif, dea, histogram
+	// DEA 基于从 longPeriod-1 开始的DIF序列计算
+	signal := emaOfValues(dif[difStart:], signalPeriod)
+	start = difStart + signalPeriod - 1
+	if start >= n {
+		return dif, dea, hist, n
+	}
+	for i := start; i < n; i++ {
+		dea[i] = signal[i-difStart]
+		hist[i] = dif[i] - dea[i]
+	}
+	return dif, dea, hist, start
 }
 
 // calculateRSI 计算RSI
@@ -321,16 +545,93 @@ func calculateRSI(klines []Kline, period int) float64 {
 	return rsi
 }
 
-// calculateATR 计算ATR
-func calculateATR(klines []Kline, period int) float64 {
-	if len(klines) <= period {
+// rsiSeries 逐根计算RSI序列，与 calculateRSI(klines[:i+1], period) 逐点对齐
+// 前 period 个位置无有效值，填0
+func rsiSeries(klines []Kline, period int) []float64 {
+	result := make([]float64, len(klines))
+	if period <= 0 || len(klines) <= period {
+		return result
+	}
+
+	gains := 0.0
+	losses := 0.0
+	for i := 1; i <= period; i++ {
+		change := klines[i].Close - klines[i-1].Close
+		if change > 0 {
+			gains += change
+		} else {
+			losses += -change
+		}
+	}
+	avgGain := gains / float64(period)
+	avgLoss := losses / float64(period)
+	result[period] = rsiFromAverages(avgGain, avgLoss)
+
+	// Wilder平滑
+	for i := period + 1; i < len(klines); i++ {
+		change := klines[i].Close - klines[i-1].Close
+		if change > 0 {
+			avgGain = (avgGain*float64(period-1) + change) / float64(period)
+			avgLoss = (avgLoss * float64(period-1)) / float64(period)
+		} else {
+			avgGain = (avgGain * float64(period-1)) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + (-change)) / float64(period)
+		}
+		result[i] = rsiFromAverages(avgGain, avgLoss)
+	}
+	return result
+}
+
+// calculateStochRSI 计算随机RSI：对最近 stochPeriod 个RSI值应用随机指标公式，结果范围0-100
+// (当前RSI - 窗口最低RSI) / (窗口最高RSI - 窗口最低RSI) * 100；窗口内RSI持平时返回50
+// 需要至少 rsiPeriod+stochPeriod 根K线，不足时返回0
+func calculateStochRSI(klines []Kline, rsiPeriod, stochPeriod int) float64 {
+	if rsiPeriod <= 0 || stochPeriod <= 0 || len(klines) < rsiPeriod+stochPeriod {
 		return 0
 	}
 
+	rsi := rsiSeries(klines, rsiPeriod)
+	window := rsi[len(rsi)-stochPeriod:]
+	lowest, highest := window[0], window[0]
+	for _, v := range window {
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+	}
+	if highest == lowest {
+		return 50
+	}
+	return (window[len(window)-1] - lowest) / (highest - lowest) * 100
+}
+
+// rsiFromAverages 由平均涨幅/跌幅计算RSI
+func rsiFromAverages(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		return 100
+	}
+	rs := avgGain / avgLoss
+	return 100 - (100 / (1 + rs))
+}
+
+// calculateATRPercent 计算ATR占最新收盘价的百分比，可跨不同价格量级的交易对比较波动率
+// K线不足或收盘价非正时返回0
+func calculateATRPercent(klines []Kline, period int) float64 {
+	if len(klines) == 0 || klines[len(klines)-1].Close <= 0 {
+		return 0
+	}
+	return calculateATR(klines, period) / klines[len(klines)-1].Close * 100
+}
+
+// TrueRangeSeries 返回与 klines 逐点对齐的真实波幅(TR)序列
+// TR[i] = max(最高-最低, |最高-前收|, |最低-前收|)；首根K线没有前收，取最高-最低
+func TrueRangeSeries(klines []Kline) []float64 {
 	trs := make([]float64, len(klines))
-	for i := 1; i < len(klines); i++ {
+	for i := range klines {
 		high := klines[i].High
 		low := klines[i].Low
+		if i == 0 {
+			trs[i] = high - low
+			continue
+		}
 		prevClose := klines[i-1].Close
 
 		tr1 := high - low
@@ -339,6 +640,16 @@ func calculateATR(klines []Kline, period int) float64 {
 
 		trs[i] = math.Max(tr1, math.Max(tr2, tr3))
 	}
+	return trs
+}
+
+// calculateATR 计算ATR（TR[0] 不参与，初始值为 TR[1..period] 的均值）
+func calculateATR(klines []Kline, period int) float64 {
+	if len(klines) <= period {
+		return 0
+	}
+
+	trs := TrueRangeSeries(klines)
 
 	// 计算初始ATR
 	sum := 0.0
@@ -355,8 +666,434 @@ func calculateATR(klines []Kline, period int) float64 {
 	return atr
 }
 
-// calculateIntradaySeries 计算日内系列数据
-func calculateIntradaySeries(klines []Kline) *IntradayData {
+// atrSeries 计算与 klines 逐点对齐的ATR序列（与 calculateATR 相同的初始均值 + Wilder平滑）
+// 前 period 个点为0；K线不足 period+1 根时全部为0
+func atrSeries(klines []Kline, period int) []float64 {
+	result := make([]float64, len(klines))
+	if period <= 0 || len(klines) <= period {
+		return result
+	}
+
+	trs := TrueRangeSeries(klines)
+
+	sum := 0.0
+	for i := 1; i <= period; i++ {
+		sum += trs[i]
+	}
+	result[period] = sum / float64(period)
+	for i := period + 1; i < len(klines); i++ {
+		result[i] = (result[i-1]*float64(period-1) + trs[i]) / float64(period)
+	}
+	return result
+}
+
+// calculateSupertrend 计算超级趋势指标
+// 基础上下轨 = (最高+最低)/2 ± mult*ATR，最终轨道只向趋势方向收紧；收盘价跌破下轨转空、突破上轨转多
+// 返回最新的跟踪止损位（多头为下轨、空头为上轨）及方向；K线不足 atrPeriod+1 根时返回 0, false
+func calculateSupertrend(klines []Kline, atrPeriod int, mult float64) (value float64, bullish bool) {
+	if atrPeriod <= 0 || len(klines) <= atrPeriod {
+		return 0, false
+	}
+
+	atr := atrSeries(klines, atrPeriod)
+	var finalUpper, finalLower float64
+	for i := atrPeriod; i < len(klines); i++ {
+		hl2 := (klines[i].High + klines[i].Low) / 2
+		basicUpper := hl2 + mult*atr[i]
+		basicLower := hl2 - mult*atr[i]
+		closePrice := klines[i].Close
+
+		if i == atrPeriod {
+			finalUpper, finalLower = basicUpper, basicLower
+			bullish = closePrice > hl2
+			continue
+		}
+
+		prevClose := klines[i-1].Close
+		if basicUpper < finalUpper || prevClose > finalUpper {
+			finalUpper = basicUpper
+		}
+		if basicLower > finalLower || prevClose < finalLower {
+			finalLower = basicLower
+		}
+
+		if bullish && closePrice < finalLower {
+			bullish = false
+		} else if !bullish && closePrice > finalUpper {
+			bullish = true
+		}
+	}
+
+	if bullish {
+		return finalLower, true
+	}
+	return finalUpper, false
+}
+
+// calculateADX 计算平均趋向指数及正负方向指标(Wilder DMI)
+// TR/+DM/-DM 与 calculateATR 相同采用首段均值 + Wilder平滑；ADX 为 DX 的 Wilder 平滑
+// K线不足 period+1 根时全部返回0；不足 2*period+1 根时仅 ADX 返回0
+func calculateADX(klines []Kline, period int) (adx, plusDI, minusDI float64) {
+	if period <= 0 || len(klines) <= period {
+		return 0, 0, 0
+	}
+
+	trs := TrueRangeSeries(klines)
+	plusDMs := make([]float64, len(klines))
+	minusDMs := make([]float64, len(klines))
+	for i := 1; i < len(klines); i++ {
+		high := klines[i].High
+		low := klines[i].Low
+
+		upMove := high - klines[i-1].High
+		downMove := klines[i-1].Low - low
+		if upMove > downMove && upMove > 0 {
+			plusDMs[i] = upMove
+		}
+		if downMove > upMove && downMove > 0 {
+			minusDMs[i] = downMove
+		}
+	}
+
+	// 初始平滑值
+	atr, plusDM, minusDM := 0.0, 0.0, 0.0
+	for i := 1; i <= period; i++ {
+		atr += trs[i]
+		plusDM += plusDMs[i]
+		minusDM += minusDMs[i]
+	}
+	n := float64(period)
+	atr, plusDM, minusDM = atr/n, plusDM/n, minusDM/n
+
+	directional := func() (float64, float64, float64) {
+		if atr == 0 {
+			return 0, 0, 0
+		}
+		pdi := 100 * plusDM / atr
+		mdi := 100 * minusDM / atr
+		if pdi+mdi == 0 {
+			return pdi, mdi, 0
+		}
+		return pdi, mdi, 100 * math.Abs(pdi-mdi) / (pdi + mdi)
+	}
+
+	plusDI, minusDI, dx := directional()
+	dxSum, dxCount := dx, 1
+	for i := period + 1; i < len(klines); i++ {
+		atr = (atr*(n-1) + trs[i]) / n
+		plusDM = (plusDM*(n-1) + plusDMs[i]) / n
+		minusDM = (minusDM*(n-1) + minusDMs[i]) / n
+		plusDI, minusDI, dx = directional()
+
+		// 前 period 个 DX 取均值作为初始ADX，之后 Wilder 平滑
+		if dxCount < period {
+			dxSum += dx
+			dxCount++
+			if dxCount == period {
+				adx = dxSum / n
+			}
+		} else {
+			adx = (adx*(n-1) + dx) / n
+		}
+	}
+
+	return adx, plusDI, minusDI
+}
+
+// calculateOBV 计算能量潮(On-Balance Volume)：收盘价上涨累加成交量，下跌累减，持平不变
+// 累计起点为第一根K线(记为0)；K线少于2根时返回0
+func calculateOBV(klines []Kline) float64 {
+	if len(klines) < 2 {
+		return 0
+	}
+	series := obvSeries(klines)
+	return series[len(series)-1]
+}
+
+// obvSeries 计算与 klines 逐点对齐的OBV累计序列
+func obvSeries(klines []Kline) []float64 {
+	result := make([]float64, len(klines))
+	for i := 1; i < len(klines); i++ {
+		result[i] = result[i-1]
+		switch {
+		case klines[i].Close > klines[i-1].Close:
+			result[i] += klines[i].Volume
+		case klines[i].Close < klines[i-1].Close:
+			result[i] -= klines[i].Volume
+		}
+	}
+	return result
+}
+
+// calculateCCI 计算商品通道指数 CCI = (TP - SMA(TP)) / (0.015 * 平均绝对偏差)，TP=(最高+最低+收盘)/3
+// 平均绝对偏差为0或K线不足 period 根时返回0
+func calculateCCI(klines []Kline, period int) float64 {
+	if period <= 0 || len(klines) < period {
+		return 0
+	}
+
+	window := klines[len(klines)-period:]
+	typicals := make([]float64, period)
+	for i, k := range window {
+		typicals[i] = (k.High + k.Low + k.Close) / 3
+	}
+	sma := meanOf(typicals)
+
+	deviation := 0.0
+	for _, tp := range typicals {
+		deviation += math.Abs(tp - sma)
+	}
+	deviation /= float64(period)
+	if deviation == 0 {
+		return 0
+	}
+	return (typicals[period-1] - sma) / (0.015 * deviation)
+}
+
+// calculateCMF 计算蔡金资金流量 CMF：最近 period 根K线的 Σ(资金流乘数×成交量) / Σ成交量，范围[-1, 1]
+// 资金流乘数 = ((收盘-最低) - (最高-收盘)) / (最高-最低)，最高等于最低时为0；
+// 正值表示吸筹(收盘偏向区间高位)，负值表示派发。K线不足或总成交量为0时返回0
+func calculateCMF(klines []Kline, period int) float64 {
+	if period <= 0 || len(klines) < period {
+		return 0
+	}
+	flow, volume := 0.0, 0.0
+	for _, k := range klines[len(klines)-period:] {
+		volume += k.Volume
+		if k.High > k.Low {
+			flow += ((k.Close - k.Low) - (k.High - k.Close)) / (k.High - k.Low) * k.Volume
+		}
+	}
+	if volume == 0 {
+		return 0
+	}
+	return flow / volume
+}
+
+// calculateDonchian 计算唐奇安通道：最近 period 根K线的最高价、最低价及其中值
+// K线不足 period 根时返回0
+func calculateDonchian(klines []Kline, period int) (upper, lower, mid float64) {
+	if period <= 0 || len(klines) < period {
+		return 0, 0, 0
+	}
+
+	window := klines[len(klines)-period:]
+	upper, lower = window[0].High, window[0].Low
+	for _, k := range window {
+		upper = math.Max(upper, k.High)
+		lower = math.Min(lower, k.Low)
+	}
+	return upper, lower, (upper + lower) / 2
+}
+
+// volumeProfileBins LongerTermData 成交量分布使用的价格分档数
+const volumeProfileBins = 24
+
+// valueAreaRatio 价值区域覆盖的成交量比例
+const valueAreaRatio = 0.7
+
+// calculateVolumeProfile 计算成交量分布：把 [最低价, 最高价] 等分为 bins 档，
+// 每根K线的成交量按其 [Low, High] 与各档的重叠长度比例分摊（High==Low 时全部计入所在档）。
+// poc 为成交量最大档的中间价；价值区域从 POC 档开始，每次向成交量较大的相邻档扩展，
+// 直到覆盖总成交量的70%，valueAreaHigh/valueAreaLow 为该区域的上下边界。无有效数据时均返回0
+func calculateVolumeProfile(klines []Kline, bins int) (poc float64, valueAreaHigh, valueAreaLow float64) {
+	if bins <= 0 || len(klines) == 0 {
+		return 0, 0, 0
+	}
+
+	low, high := klines[0].Low, klines[0].High
+	for _, k := range klines {
+		low = math.Min(low, k.Low)
+		high = math.Max(high, k.High)
+	}
+	if high <= low {
+		return low, low, low
+	}
+	step := (high - low) / float64(bins)
+	binOf := func(price float64) int {
+		return int(math.Min(float64(bins-1), math.Max(0, math.Floor((price-low)/step))))
+	}
+
+	volumes := make([]float64, bins)
+	total := 0.0
+	for _, k := range klines {
+		if k.Volume <= 0 {
+			continue
+		}
+		total += k.Volume
+		if k.High <= k.Low {
+			volumes[binOf(k.Close)] += k.Volume
+			continue
+		}
+		for i := binOf(k.Low); i <= binOf(k.High); i++ {
+			binLow := low + float64(i)*step
+			overlap := math.Min(k.High, binLow+step) - math.Max(k.Low, binLow)
+			if overlap > 0 {
+				volumes[i] += k.Volume * overlap / (k.High - k.Low)
+			}
+		}
+	}
+	if total == 0 {
+		return 0, 0, 0
+	}
+
+	pocBin := 0
+	for i, v := range volumes {
+		if v > volumes[pocBin] {
+			pocBin = i
+		}
+	}
+
+	lo, hi := pocBin, pocBin
+	covered := volumes[pocBin]
+	for covered < total*valueAreaRatio && (lo > 0 || hi < bins-1) {
+		below, above := -1.0, -1.0
+		if lo > 0 {
+			below = volumes[lo-1]
+		}
+		if hi < bins-1 {
+			above = volumes[hi+1]
+		}
+		if above >= below {
+			hi++
+			covered += above
+		} else {
+			lo--
+			covered += below
+		}
+	}
+
+	poc = low + (float64(pocBin)+0.5)*step
+	return poc, low + float64(hi+1)*step, low + float64(lo)*step
+}
+
+// calculateVWAP 计算成交量加权平均价，典型价格取 (最高+最低+收盘)/3
+// 总成交量为0时返回最后一根K线的收盘价；无K线时返回0
+func calculateVWAP(klines []Kline) float64 {
+	if len(klines) == 0 {
+		return 0
+	}
+
+	pv, volume := 0.0, 0.0
+	for _, k := range klines {
+		typical := (k.High + k.Low + k.Close) / 3
+		pv += typical * k.Volume
+		volume += k.Volume
+	}
+	if volume == 0 {
+		return klines[len(klines)-1].Close
+	}
+	return pv / volume
+}
+
+// vwapBandMult LongerTermData VWAP 标准差带的倍数
+const vwapBandMult = 2.0
+
+// calculateVWAPBands 计算VWAP及其上下 mult 倍成交量加权标准差带（锚定于K线窗口起点）
+// 标准差 = sqrt(Σ成交量*(典型价格-VWAP)² / Σ成交量)；总成交量为0时上下轨等于VWAP；无K线时全部为0
+func calculateVWAPBands(klines []Kline, mult float64) (vwap, upper, lower float64) {
+	vwap = calculateVWAP(klines)
+	if len(klines) == 0 {
+		return 0, 0, 0
+	}
+
+	weighted, volume := 0.0, 0.0
+	for _, k := range klines {
+		diff := (k.High+k.Low+k.Close)/3 - vwap
+		weighted += k.Volume * diff * diff
+		volume += k.Volume
+	}
+	if volume == 0 {
+		return vwap, vwap, vwap
+	}
+	stdDev := math.Sqrt(weighted / volume)
+	return vwap, vwap + mult*stdDev, vwap - mult*stdDev
+}
+
+// calculateBollingerBands 计算布林带
+// 中轨为 period 期收盘价SMA，上下轨为中轨 ± stdDevMult 倍总体标准差；K线不足时返回0
+func calculateBollingerBands(klines []Kline, period int, stdDevMult float64) (upper, middle, lower float64) {
+	if period <= 0 || len(klines) < period {
+		return 0, 0, 0
+	}
+	window := klines[len(klines)-period:]
+
+	sum := 0.0
+	for _, k := range window {
+		sum += k.Close
+	}
+	middle = sum / float64(period)
+
+	variance := 0.0
+	for _, k := range window {
+		diff := k.Close - middle
+		variance += diff * diff
+	}
+	stdDev := math.Sqrt(variance / float64(period))
+
+	return middle + stdDevMult*stdDev, middle, middle - stdDevMult*stdDev
+}
+
+// calculateStochastic 计算随机指标
+// %K = (收盘价 - kPeriod内最低价) / (kPeriod内最高价 - 最低价) * 100，区间无波动时取50；
+// %D 为最近 dPeriod 个 %K 的SMA。K线不足 kPeriod+dPeriod-1 根时返回0
+func calculateStochastic(klines []Kline, kPeriod, dPeriod int) (k, d float64) {
+	if kPeriod <= 0 || dPeriod <= 0 || len(klines) < kPeriod+dPeriod-1 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for j := len(klines) - dPeriod; j < len(klines); j++ {
+		window := klines[j-kPeriod+1 : j+1]
+		highest, lowest := window[0].High, window[0].Low
+		for _, kl := range window {
+			highest = math.Max(highest, kl.High)
+			lowest = math.Min(lowest, kl.Low)
+		}
+		value := 50.0
+		if highest > lowest {
+			value = (klines[j].Close - lowest) / (highest - lowest) * 100
+		}
+		sum += value
+		k = value
+	}
+	return k, sum / float64(dPeriod)
+}
+
+// calculateWilliamsR 计算威廉指标 %R = (period内最高价 - 收盘价) / (最高价 - 最低价) * -100，范围[-100, 0]
+// 接近0表示收于区间高点，接近-100表示收于区间低点；区间无波动时取-50，K线不足 period 根时返回0
+func calculateWilliamsR(klines []Kline, period int) float64 {
+	if period <= 0 || len(klines) < period {
+		return 0
+	}
+
+	window := klines[len(klines)-period:]
+	highest, lowest := window[0].High, window[0].Low
+	for _, k := range window {
+		highest = math.Max(highest, k.High)
+		lowest = math.Min(lowest, k.Low)
+	}
+	if highest == lowest {
+		return -50
+	}
+	return (highest - klines[len(klines)-1].Close) / (highest - lowest) * -100
+}
+
+// calculateROC 计算变动率 ROC = (最新收盘价 - period根前收盘价) / period根前收盘价 * 100
+// K线不足 period+1 根或基准价格非正时返回0
+func calculateROC(klines []Kline, period int) float64 {
+	if period <= 0 || len(klines) <= period {
+		return 0
+	}
+	base := klines[len(klines)-1-period].Close
+	if base <= 0 {
+		return 0
+	}
+	return (klines[len(klines)-1].Close - base) / base * 100
+}
+
+// calculateIntradaySeries 计算日内系列数据，EMA/ATR/RSI/MACD 周期取自 p
+func calculateIntradaySeries(klines []Kline, p IntradayPeriods) *IntradayData {
 	data := &IntradayData{
 		MidPrices:       make([]float64, 0, 10),
 		EMA20Values:     make([]float64, 0, 10),
@@ -367,12 +1104,64 @@ func calculateIntradaySeries(klines []Kline) *IntradayData {
 		RSI10Values:     make([]float64, 0, 10),
 		RSI14Values:     make([]float64, 0, 10),
 		VolumeValues:    make([]float64, 0, 10),
+		OBVValues:       make([]float64, 0, 10),
 	}
 	// 计算ATR
-	data.ATR6 = calculateATR(klines, 6)
-	data.ATR10 = calculateATR(klines, 10)
-	data.ATR12 = calculateATR(klines, 12)
-	data.ATR14 = calculateATR(klines, 14)
+	data.ATR6 = calculateATR(klines, p.ATR[0])
+	data.ATR10 = calculateATR(klines, p.ATR[1])
+	data.ATR12 = calculateATR(klines, p.ATR[2])
+	data.ATR14 = calculateATR(klines, p.ATR[3])
+	data.ATR14Percent = calculateATRPercent(klines, p.ATR[3])
+
+	// 计算布林带(20,2)
+	data.BBUpper, data.BBMiddle, data.BBLower = calculateBollingerBands(klines, 20, 2)
+
+	// 布林带宽度与 TTM 挤压检测
+	data.Squeeze, data.BBBandwidth = DetectSqueeze(klines)
+
+	// 计算随机指标(14,3)
+	data.StochK, data.StochD = calculateStochastic(klines, 14, 3)
+
+	// 计算威廉指标(14)
+	data.WilliamsR = calculateWilliamsR(klines, 14)
+
+	// 计算CCI(20)
+	data.CCI20 = calculateCCI(klines, 20)
+
+	// 计算变动率(10)
+	data.ROC = calculateROC(klines, 10)
+
+	// 计算超级趋势(10,3)
+	data.Supertrend, data.SupertrendBullish = calculateSupertrend(klines, 10, 3)
+
+	// EMA(9/21)交叉
+	data.EMACrossUp, data.EMACrossDown = DetectEMACross(klines, emaCrossFast, emaCrossSlow)
+
+	// EMA带(8/13/21/34/55)
+	data.Ribbon = calculateEMARibbon(klines, RibbonPeriods)
+
+	// 计算OBV
+	obv := obvSeries(klines)
+	data.OBV = calculateOBV(klines)
+
+	// 动量指标(MACD/RSI)使用的K线，可选对数收益率
+	momentum := momentumKlines(klines)
+
+	// 计算随机RSI(14,14)
+	data.StochRSI = calculateStochRSI(momentum, 14, 14)
+
+	// 一次前向计算完整指标序列（EMA递推、Wilder RSI递推、增量MACD），再截取最近10个点
+	ema20 := emaSeries(klines, p.EMA)
+	macdA, macdB := p.MACD[0], p.MACD[1]
+	macd10208, dea10208, hist10208, signal10208 := macdSeries(momentum, macdA.Short, macdA.Long, macdA.Signal)
+	macd12269, dea12269, hist12269, signal12269 := macdSeries(momentum, macdB.Short, macdB.Long, macdB.Signal)
+	rsi7 := rsiSeries(momentum, p.RSI[0])
+	rsi9 := rsiSeries(momentum, p.RSI[1])
+	rsi10 := rsiSeries(momentum, p.RSI[2])
+	rsi14 := rsiSeries(momentum, p.RSI[3])
+
+	// RSI(14)背离：价格取原始K线高低点，RSI 与其他动量指标口径一致
+	data.RSIBullishDivergence, data.RSIBearishDivergence = rsiDivergence(klines, rsi14, p.RSI[3])
 
 	// 获取最近10个数据点
 	start := len(klines) - 10
@@ -383,42 +1172,41 @@ func calculateIntradaySeries(klines []Kline) *IntradayData {
 	for i := start; i < len(klines); i++ {
 		data.MidPrices = append(data.MidPrices, klines[i].Close)
 		data.VolumeValues = append(data.VolumeValues, klines[i].Volume)
+		data.OBVValues = append(data.OBVValues, obv[i])
 
-		// 计算每个点的EMA20
-		if i >= 19 {
-			ema20 := calculateEMA(klines[:i+1], 20)
-			data.EMA20Values = append(data.EMA20Values, ema20)
+		// 每个点的EMA(p.EMA)，从第 p.EMA 根K线起
+		if i >= p.EMA-1 {
+			data.EMA20Values = append(data.EMA20Values, ema20[i])
 		}
 
-		// 计算每个点的MACD
-		if i >= 25 {
-			dif, _, _ := calculateMACD(klines[:i+1], 10, 20, 8)
-			macd := dif
-			data.MACDValues10208 = append(data.MACDValues10208, macd)
+		// 每个点的MACD（DIF 从长周期形成后开始），DEA/柱状图从信号线形成后开始
+		if i >= macdA.difStart() {
+			data.MACDValues10208 = append(data.MACDValues10208, macd10208[i])
+		}
+		if i >= macdB.difStart() {
+			data.MACDValues12269 = append(data.MACDValues12269, macd12269[i])
 		}
-		// 计算每个点的MACD
-		if i >= 25 {
-			dif, _, _ := calculateMACD(klines[:i+1], 12, 26, 9)
-			macd := dif
-			data.MACDValues12269 = append(data.MACDValues12269, macd)
+		if i >= signal10208 {
+			data.MACDDEA10208 = append(data.MACDDEA10208, dea10208[i])
+			data.MACDHist10208 = append(data.MACDHist10208, hist10208[i])
+		}
+		if i >= signal12269 {
+			data.MACDDEA12269 = append(data.MACDDEA12269, dea12269[i])
+			data.MACDHist12269 = append(data.MACDHist12269, hist12269[i])
 		}
 
-		// 计算每个点的RSI
-		if i >= 7 {
-			rsi7 := calculateRSI(klines[:i+1], 7)
-			data.RSI7Values = append(data.RSI7Values, rsi7)
+		// 每个点的RSI
+		if i >= p.RSI[0] {
+			data.RSI7Values = append(data.RSI7Values, rsi7[i])
 		}
-		if i >= 9 {
-			rsi9 := calculateRSI(klines[:i+1], 9)
-			data.RSI9Values = append(data.RSI9Values, rsi9)
+		if i >= p.RSI[1] {
+			data.RSI9Values = append(data.RSI9Values, rsi9[i])
 		}
-		if i >= 10 {
-			rsi10 := calculateRSI(klines[:i+1], 10)
-			data.RSI10Values = append(data.RSI10Values, rsi10)
+		if i >= p.RSI[2] {
+			data.RSI10Values = append(data.RSI10Values, rsi10[i])
 		}
-		if i >= 14 {
-			rsi14 := calculateRSI(klines[:i+1], 14)
-			data.RSI14Values = append(data.RSI14Values, rsi14)
+		if i >= p.RSI[3] {
+			data.RSI14Values = append(data.RSI14Values, rsi14[i])
 		}
 	}
 
@@ -437,11 +1225,13 @@ func calculateIntradaySeries(klines []Kline) *IntradayData {
 		}
 	}
 
+	data.Trend = classifyIntradayTrend(data.MidPrices, data.EMA20Values)
+
 	return data
 }
 
-// calculateLongerTermData 计算长期数据
-func calculateLongerTermData(klines []Kline) *LongerTermData {
+// calculateLongerTermData 计算长期数据，ATR/RSI/MACD 周期取自 p
+func calculateLongerTermData(klines []Kline, p LongerTermPeriods) *LongerTermData {
 	data := &LongerTermData{
 		MACDValues142810: make([]float64, 0, 10),
 		MACDValues12269:  make([]float64, 0, 10),
@@ -454,13 +1244,40 @@ func calculateLongerTermData(klines []Kline) *LongerTermData {
 	data.EMA50 = calculateEMA(klines, 50)
 
 	// 计算ATR
-	data.ATR3 = calculateATR(klines, 3)
-	data.ATR10 = calculateATR(klines, 10)
-	data.ATR12 = calculateATR(klines, 12)
-	data.ATR14 = calculateATR(klines, 14)
+	data.ATR3 = calculateATR(klines, p.ATR[0])
+	data.ATR10 = calculateATR(klines, p.ATR[1])
+	data.ATR12 = calculateATR(klines, p.ATR[2])
+	data.ATR14 = calculateATR(klines, p.ATR[3])
+	data.ATR14Percent = calculateATRPercent(klines, p.ATR[3])
+
+	// 计算VWAP及标准差带
+	data.VWAP, data.VWAPUpper, data.VWAPLower = calculateVWAPBands(klines, vwapBandMult)
+
+	// 计算ADX/DMI
+	data.ADX14, data.PlusDI, data.MinusDI = calculateADX(klines, 14)
+
+	// 计算CCI(20)
+	data.CCI20 = calculateCCI(klines, 20)
+
+	// 计算蔡金资金流量(20)
+	data.CMF20 = calculateCMF(klines, 20)
 
-	// 计算成交量
+	// 计算唐奇安通道(20)
+	data.DonchianUpper, data.DonchianLower, data.DonchianMid = calculateDonchian(klines, 20)
+	data.BreakoutUp, data.BreakoutDown = DetectBreakout(klines, 20)
+
+	// 计算成交量分布(POC/价值区域)
+	data.POC, data.ValueAreaHigh, data.ValueAreaLow = calculateVolumeProfile(klines, volumeProfileBins)
+
+	// 计算一目均衡表(9,26,52)，K线不足时为nil
+	data.Ichimoku, _ = calculateIchimoku(klines)
+
+	// 计算斐波那契回撤价位，K线不足时为nil
+	data.FibLevels = calculateFibLevels(klines, fibLookback)
+
+	// 计算成交量与趋势
 	if len(klines) > 0 {
+		data.Trend = classifyLongerTermTrend(klines[len(klines)-1].Close, data.EMA20, data.EMA50)
 		data.CurrentVolume = klines[len(klines)-1].Volume
 		// 计算平均成交量
 		sum := 0.0
@@ -470,30 +1287,40 @@ func calculateLongerTermData(klines []Kline) *LongerTermData {
 		data.AverageVolume = sum / float64(len(klines))
 	}
 
-	// 计算MACD和RSI序列
+	// 计算MACD和RSI序列（动量指标可选对数收益率），一次前向计算后截取最近10个点
+	momentum := momentumKlines(klines)
+	macdA, macdB := p.MACD[0], p.MACD[1]
+	macd142810, dea142810, hist142810, signal142810 := macdSeries(momentum, macdA.Short, macdA.Long, macdA.Signal)
+	macd12269, dea12269, hist12269, signal12269 := macdSeries(momentum, macdB.Short, macdB.Long, macdB.Signal)
+	rsi14 := rsiSeries(momentum, p.RSI[0])
+	rsi21 := rsiSeries(momentum, p.RSI[1])
+
 	start := len(klines) - 10
 	if start < 0 {
 		start = 0
 	}
 
 	for i := start; i < len(klines); i++ {
-		if i >= 25 {
-			dif, _, _ := calculateMACD(klines[:i+1], 14, 28, 10)
-			macd := dif
-			data.MACDValues142810 = append(data.MACDValues142810, macd)
+		// DIF 从长周期形成后开始，DEA/柱状图从信号线形成后开始
+		if i >= macdA.difStart() {
+			data.MACDValues142810 = append(data.MACDValues142810, macd142810[i])
+		}
+		if i >= macdB.difStart() {
+			data.MACDValues12269 = append(data.MACDValues12269, macd12269[i])
+		}
+		if i >= signal142810 {
+			data.MACDDEA142810 = append(data.MACDDEA142810, dea142810[i])
+			data.MACDHist142810 = append(data.MACDHist142810, hist142810[i])
 		}
-		if i >= 25 {
-			dif, _, _ := calculateMACD(klines[:i+1], 12, 26, 9)
-			macd := dif
-			data.MACDValues12269 = append(data.MACDValues12269, macd)
+		if i >= signal12269 {
+			data.MACDDEA12269 = append(data.MACDDEA12269, dea12269[i])
+			data.MACDHist12269 = append(data.MACDHist12269, hist12269[i])
 		}
-		if i >= 14 {
-			rsi14 := calculateRSI(klines[:i+1], 14)
-			data.RSI14Values = append(data.RSI14Values, rsi14)
+		if i >= p.RSI[0] {
+			data.RSI14Values = append(data.RSI14Values, rsi14[i])
 		}
-		if i >= 21 {
-			rsi21 := calculateRSI(klines[:i+1], 21)
-			data.RSI21Values = append(data.RSI21Values, rsi21)
+		if i >= p.RSI[1] {
+			data.RSI21Values = append(data.RSI21Values, rsi21[i])
 		}
 	}
 
@@ -501,16 +1328,10 @@ func calculateLongerTermData(klines []Kline) *LongerTermData {
 }
 
 // getOpenInterestData 获取OI数据
-func getOpenInterestData(symbol string) (*OIData, error) {
-	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/openInterest?symbol=%s", symbol)
+func getOpenInterestData(ctx context.Context, symbol string) (*OIData, error) {
+	url := fmt.Sprintf("%s/fapi/v1/openInterest?symbol=%s", BaseURL, symbol)
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := doRequest(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -525,9 +1346,9 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 		return nil, err
 	}
 
-	oi, parseErr := strconv.ParseFloat(result.OpenInterest, 64)
-	if parseErr != nil {
-		return nil, fmt.Errorf("parse openInterest failed: %w", parseErr)
+	oi, err := strconv.ParseFloat(result.OpenInterest, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse openInterest failed: %w", err)
 	}
 
 	// --- 构建历史序列与变化率 ---
@@ -556,9 +1377,19 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 
 	trendScore := (change5m + change15m + change1h + change4h + change1d) / 5.0
 
+	// 平均值取自 Binance 持仓量历史；历史获取失败时退化为最新值
+	average := oi
+	history, err := getOpenInterestHistory(ctx, symbol, "5m", OIHistoryLimit)
+	if err != nil {
+		logger.Warnf("获取 %s 持仓量历史失败: %v", symbol, err)
+	} else if len(history) > 0 {
+		average = meanOf(history)
+	}
+
 	return &OIData{
 		Latest:     oi,
-		Average:    oi * 0.999, // TODO: 可替换为真实滑动平均
+		Average:    average,
+		History:    history,
 		Series5m:   append([]float64(nil), series.fiveMins...),
 		Series15m:  append([]float64(nil), series.fifteenMins...),
 		Series1h:   append([]float64(nil), series.oneHours...),
@@ -573,6 +1404,38 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 	}, nil
 }
 
+// OIHistoryLimit 计算持仓量平均值时获取的历史点数（5分钟粒度，Binance 上限500）
+var OIHistoryLimit = 30
+
+// getOpenInterestHistory 获取持仓量历史序列（sumOpenInterest），按时间升序
+func getOpenInterestHistory(ctx context.Context, symbol, period string, limit int) ([]float64, error) {
+	url := fmt.Sprintf("%s/futures/data/openInterestHist?symbol=%s&period=%s&limit=%d", BaseURL, symbol, period, limit)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Symbol          string `json:"symbol"`
+		SumOpenInterest string `json:"sumOpenInterest"`
+		Timestamp       int64  `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	history := make([]float64, 0, len(result))
+	for _, item := range result {
+		v, err := strconv.ParseFloat(item.SumOpenInterest, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse sumOpenInterest failed: %w", err)
+		}
+		history = append(history, v)
+	}
+	return history, nil
+}
+
 // --- OI 序列缓存结构与更新逻辑 ---
 type oiSeries struct {
 	fiveMins    []float64
@@ -593,7 +1456,7 @@ var oiSeriesCache = struct {
 }{data: make(map[string]*oiSeries)}
 
 func updateOISeriesCache(symbol string, oi float64) *oiSeries {
-	now := time.Now()
+	t := now()
 	oiSeriesCache.mu.Lock()
 	defer oiSeriesCache.mu.Unlock()
 
@@ -606,39 +1469,39 @@ func updateOISeriesCache(symbol string, oi float64) *oiSeries {
 		s.oneHours = append(s.oneHours, oi)
 		s.fourHours = append(s.fourHours, oi)
 		s.oneDays = append(s.oneDays, oi)
-		s.last5m = now
-		s.last15m = now
-		s.last1h = now
-		s.last4h = now
-		s.last1d = now
+		s.last5m = t
+		s.last15m = t
+		s.last1h = t
+		s.last4h = t
+		s.last1d = t
 		oiSeriesCache.data[symbol] = s
 		return s
 	}
 
 	// 5m 序列
-	if now.Sub(s.last5m) >= 5*time.Minute {
+	if t.Sub(s.last5m) >= 5*time.Minute {
 		s.fiveMins = append(s.fiveMins, oi)
-		s.last5m = now
+		s.last5m = t
 	}
 	// 15m 序列
-	if now.Sub(s.last15m) >= 15*time.Minute {
+	if t.Sub(s.last15m) >= 15*time.Minute {
 		s.fifteenMins = append(s.fifteenMins, oi)
-		s.last15m = now
+		s.last15m = t
 	}
 	// 1h 序列
-	if now.Sub(s.last1h) >= time.Hour {
+	if t.Sub(s.last1h) >= time.Hour {
 		s.oneHours = append(s.oneHours, oi)
-		s.last1h = now
+		s.last1h = t
 	}
 	// 4h 序列
-	if now.Sub(s.last4h) >= 4*time.Hour {
+	if t.Sub(s.last4h) >= 4*time.Hour {
 		s.fourHours = append(s.fourHours, oi)
-		s.last4h = now
+		s.last4h = t
 	}
 	// 1d 序列
-	if now.Sub(s.last1d) >= 24*time.Hour {
+	if t.Sub(s.last1d) >= 24*time.Hour {
 		s.oneDays = append(s.oneDays, oi)
-		s.last1d = now
+		s.last1d = t
 	}
 
 	// 截断长度避免无限增长（保留最近300个点即可）
@@ -657,19 +1520,13 @@ func updateOISeriesCache(symbol string, oi float64) *oiSeries {
 	return s
 }
 
-// getFundingRate 获取资金费率
-func getFundingRate(symbol string) (float64, error) {
-	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/premiumIndex?symbol=%s", symbol)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
+// getFundingRate 获取资金费率及标记价格、指数价格
+func getFundingRate(ctx context.Context, symbol string) (*FundingData, error) {
+	url := fmt.Sprintf("%s/fapi/v1/premiumIndex?symbol=%s", BaseURL, symbol)
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := doRequest(ctx, url)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	var result struct {
@@ -683,23 +1540,152 @@ func getFundingRate(symbol string) (float64, error) {
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	rate, _ := strconv.ParseFloat(result.LastFundingRate, 64)
-	// 旧代码中的 err 检查已无意义（变量被覆盖），改为显式错误处理
-	// 如果需要严格处理，可改为：rateParsed, parseErr := strconv.ParseFloat(...)
-	// 这里保留简单解析，失败返回 0
-	return rate, nil
+	rate, err := strconv.ParseFloat(result.LastFundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse lastFundingRate failed: %w", err)
+	}
+	markPrice, err := strconv.ParseFloat(result.MarkPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse markPrice failed: %w", err)
+	}
+	indexPrice, err := strconv.ParseFloat(result.IndexPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse indexPrice failed: %w", err)
+	}
+
+	funding := &FundingData{
+		Rate:       rate,
+		MarkPrice:  markPrice,
+		IndexPrice: indexPrice,
+	}
+	// 部分合约(如交割合约)无资金费，nextFundingTime 为0，保持零值
+	if result.NextFundingTime > 0 {
+		funding.NextFundingTime = Timestamp{time.UnixMilli(result.NextFundingTime)}
+	}
+	return funding, nil
+}
+
+// meanOf 计算算术平均值，空切片返回0
+func meanOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentileRank 计算 current 在 history 中的百分位排名(0-100)
+// 相等值按一半计入，避免历史数据全部相同时结果偏向两端；history 为空返回0
+func percentileRank(current float64, history []float64) float64 {
+	if len(history) == 0 {
+		return 0
+	}
+	below, equal := 0, 0
+	for _, v := range history {
+		switch {
+		case v < current:
+			below++
+		case v == current:
+			equal++
+		}
+	}
+	return (float64(below) + 0.5*float64(equal)) / float64(len(history)) * 100
+}
+
+// ichimokuNote 返回一目均衡表摘要及价格相对云层的位置，数据不可用时返回空字符串
+func ichimokuNote(ichimoku *Ichimoku, nf string) string {
+	if ichimoku == nil {
+		return ""
+	}
+	head := fmt.Sprintf("一目均衡表(9,26,52): 转换线="+nf+", 基准线="+nf, ichimoku.Tenkan, ichimoku.Kijun)
+	var position string
+	switch ichimoku.Position {
+	case CloudAbove:
+		position = "价格位于云层上方"
+	case CloudBelow:
+		position = "价格位于云层下方"
+	case CloudInside:
+		position = "价格位于云层内"
+	default:
+		return head + ", 当前云层数据不足\n\n"
+	}
+	return fmt.Sprintf("%s, 云层="+nf+" ~ "+nf+", %s\n\n", head, ichimoku.CloudBottom, ichimoku.CloudTop, position)
+}
+
+// breakoutNote 最新K线放量突破唐奇安通道时的提示文本，无突破返回空字符串
+func breakoutNote(lt *LongerTermData) string {
+	switch {
+	case lt.BreakoutUp:
+		return fmt.Sprintf("放量向上突破唐奇安通道(成交量超过均量%.1f倍)\n\n", BreakoutVolumeMultiple)
+	case lt.BreakoutDown:
+		return fmt.Sprintf("放量向下跌破唐奇安通道(成交量超过均量%.1f倍)\n\n", BreakoutVolumeMultiple)
+	}
+	return ""
+}
+
+// squeezeNote 返回布林带宽度说明，处于 TTM 挤压时附加提示
+func squeezeNote(series *IntradayData) string {
+	if series.Squeeze {
+		return fmt.Sprintf("布林带宽度: %.3f%%, 处于挤压状态(布林带位于肯特纳通道内)\n\n", series.BBBandwidth)
+	}
+	return fmt.Sprintf("布林带宽度: %.3f%%\n\n", series.BBBandwidth)
+}
+
+// levelsNote 返回最近支撑/阻力位及当前价格与其距离的说明，两者均不存在时返回空字符串
+func levelsNote(data *Data, pf string) string {
+	if (data.NearestSupport <= 0 && data.NearestResistance <= 0) || data.CurrentPrice <= 0 {
+		return ""
+	}
+	parts := make([]string, 0, 2)
+	if data.NearestSupport > 0 {
+		parts = append(parts, fmt.Sprintf("最近支撑="+pf+" (距离%.2f%%)",
+			data.NearestSupport, (data.NearestSupport-data.CurrentPrice)/data.CurrentPrice*100))
+	}
+	if data.NearestResistance > 0 {
+		parts = append(parts, fmt.Sprintf("最近阻力="+pf+" (距离+%.2f%%)",
+			data.NearestResistance, (data.NearestResistance-data.CurrentPrice)/data.CurrentPrice*100))
+	}
+	return fmt.Sprintf("支撑/阻力(1小时摆动点): %s\n\n", strings.Join(parts, ", "))
+}
+
+// FormatOptions 控制 Format 输出的小数位数
+type FormatOptions struct {
+	PriceDecimals     int // 当前价格的小数位，默认2
+	IndicatorDecimals int // 价格尺度指标(EMA/MACD/ATR/布林带等)及各序列的小数位，默认3
 }
 
-// Format 格式化输出市场数据
+// DefaultFormatOptions 返回 Format 使用的默认小数位
+func DefaultFormatOptions() FormatOptions {
+	return FormatOptions{PriceDecimals: 2, IndicatorDecimals: 3}
+}
+
+// Format 格式化输出市场数据，小数位按交易对的价格精度(exchangeInfo)自动确定
+// 精度取自 Get 预热的缓存，不发起网络请求；缓存未命中时使用 DefaultFormatOptions
 func Format(data *Data) string {
+	return FormatWith(data, formatOptionsFor(data.Symbol))
+}
+
+// FormatWith 按指定小数位格式化输出市场数据，适用于 SHIB 等需要更多小数位的低价代币
+func FormatWith(data *Data, opts FormatOptions) string {
 	var sb strings.Builder
 
+	// 价格与价格尺度指标的格式（标记/指数价格至少保留4位小数）
+	pf := fmt.Sprintf("%%.%df", opts.PriceDecimals)
+	nf := fmt.Sprintf("%%.%df", opts.IndicatorDecimals)
+	mf := fmt.Sprintf("%%.%df", maxInt(opts.PriceDecimals, 4))
+
 	// 基础价格信息（包含新增的时间框架价格变化）
-	sb.WriteString(fmt.Sprintf("当前价格 = %.2f, 20期EMA = %.3f, MACD = %.3f, 7期RSI = %.3f\n\n",
+	sb.WriteString(fmt.Sprintf("当前价格 = "+pf+", 20期EMA = "+nf+", MACD = "+nf+", 7期RSI = %.3f\n\n",
 		data.CurrentPrice, data.CurrentEMA20, data.CurrentMACD, data.CurrentRSI7))
+	if data.Partial {
+		sb.WriteString(fmt.Sprintf("数据不完整(K线不足，以下指标为0): %s\n\n", strings.Join(data.Warnings, "; ")))
+	}
 	sb.WriteString(fmt.Sprintf("价格变化: 3分钟=%.2f%%, 15分钟=%.2f%%, 1小时=%.2f%%, 4小时=%.2f%%, 1天=%.2f%%\n",
 		data.PriceChange3m, data.PriceChange15m, data.PriceChange1h, data.PriceChange4h, data.PriceChange1d))
 	sb.WriteString(fmt.Sprintf("协同效率: 3m=%.3f(%s), 15m=%.3f(%s), 1h=%.3f(%s)\n\n",
@@ -707,146 +1693,459 @@ func Format(data *Data) string {
 		data.EffortResult15m, data.EffortLabel15m,
 		data.EffortResult1h, data.EffortLabel1h))
 
-	// 持仓量和资金费率
-	sb.WriteString(fmt.Sprintf("合约市场数据（%s）:\n\n", data.Symbol))
-	if data.OpenInterest != nil {
-		sb.WriteString(fmt.Sprintf("持仓量: 最新=%.2f, 平均=%.2f\n",
-			data.OpenInterest.Latest, data.OpenInterest.Average))
-		// 新增：OI变化率与趋势
-		sb.WriteString(fmt.Sprintf("OI变化率: 5m=%.3f%%, 15m=%.3f%%, 1h=%.3f%%, 4h=%.3f%%, 1d=%.3f%%\n",
-			data.OpenInterest.Change5m*100,
-			data.OpenInterest.Change15m*100,
-			data.OpenInterest.Change1h*100,
-			data.OpenInterest.Change4h*100,
-			data.OpenInterest.Change1d*100))
-		sb.WriteString(fmt.Sprintf("OI趋势评分: %.3f\n\n", data.OpenInterest.TrendScore))
-	}
-	sb.WriteString(fmt.Sprintf("资金费率: %.2e\n\n", data.FundingRate))
+	if note := levelsNote(data, pf); note != "" {
+		sb.WriteString(note)
+	}
+
+	// 持仓量和资金费率（仅合约）
+	if data.Market != Spot {
+		sb.WriteString(fmt.Sprintf("合约市场数据（%s）:\n\n", data.Symbol))
+		if data.OpenInterest != nil {
+			sb.WriteString(fmt.Sprintf("持仓量: 最新=%.2f, 平均=%.2f\n",
+				data.OpenInterest.Latest, data.OpenInterest.Average))
+			// 新增：OI变化率与趋势
+			sb.WriteString(fmt.Sprintf("OI变化率: 5m=%.3f%%, 15m=%.3f%%, 1h=%.3f%%, 4h=%.3f%%, 1d=%.3f%%\n",
+				data.OpenInterest.Change5m*100,
+				data.OpenInterest.Change15m*100,
+				data.OpenInterest.Change1h*100,
+				data.OpenInterest.Change4h*100,
+				data.OpenInterest.Change1d*100))
+			sb.WriteString(fmt.Sprintf("OI趋势评分: %.3f\n\n", data.OpenInterest.TrendScore))
+		}
+		sb.WriteString(fmt.Sprintf("资金费率: %.2e\n\n", data.FundingRate))
+		if data.Funding != nil {
+			sb.WriteString(fmt.Sprintf("标记价格: "+mf+", 指数价格: "+mf+", 基差(标记-指数): "+mf+" (%.3f%%)\n\n",
+				data.Funding.MarkPrice, data.Funding.IndexPrice, data.Funding.Basis(), data.Funding.BasisPercent()))
+			if countdown := fundingCountdown(data.Funding, now()); countdown != "" {
+				sb.WriteString(fmt.Sprintf("下次资金费用: %s后\n\n", countdown))
+			}
+		}
+		if len(data.FundingHistory) > 0 {
+			sb.WriteString(fmt.Sprintf("资金费率趋势: %s, 近%d期百分位: %.1f\n\n",
+				data.FundingTrend, len(data.FundingHistory), data.FundingPercentile))
+		}
+		if t := data.Ticker; t != nil {
+			sb.WriteString(fmt.Sprintf("24小时行情: 最高="+pf+", 最低="+pf+", 均价="+pf+", 涨跌幅=%.2f%%, 成交量=%s, 成交额=%s\n\n",
+				t.HighPrice, t.LowPrice, t.WeightedAvgPrice, t.PriceChangePercent, formatVolume(t.Volume), formatVolume(t.QuoteVolume)))
+		}
+		if data.TakerBuySellRatio > 0 {
+			sb.WriteString(fmt.Sprintf("主动买卖量比(%s): %.3f\n\n", TakerBuySellPeriod, data.TakerBuySellRatio))
+		}
+		if d := data.Depth; d != nil {
+			sb.WriteString(fmt.Sprintf("订单簿(前%d档): 买量=%s, 卖量=%s, 失衡=%+.3f, 价差=%.3f%%\n\n",
+				d.Levels, formatVolume(d.BidVolume), formatVolume(d.AskVolume), d.Imbalance, d.SpreadPercent))
+		}
+		if liq := data.Liquidations; liq != nil && liq.LongCount+liq.ShortCount > 0 {
+			sb.WriteString(fmt.Sprintf("最近强平: 多头 %s (%d笔), 空头 %s (%d笔)\n\n",
+				formatVolume(liq.LongVolume), liq.LongCount, formatVolume(liq.ShortVolume), liq.ShortCount))
+		}
+		if data.LongShort != nil {
+			sb.WriteString(fmt.Sprintf("多空账户比(%s): %.3f (多=%.2f%%, 空=%.2f%%)\n\n",
+				LongShortRatioPeriod, data.LongShort.Ratio, data.LongShort.LongAccount*100, data.LongShort.ShortAccount*100))
+		}
+	}
+
+	// 3分钟K线形态
+	if len(data.Patterns3m) > 0 {
+		names := make([]string, len(data.Patterns3m))
+		for i, p := range data.Patterns3m {
+			names[i] = p.Name
+		}
+		sb.WriteString(fmt.Sprintf("3分钟K线形态(最近%d根): %s\n\n", PatternLookback, strings.Join(names, ", ")))
+	}
 
 	// 3分钟数据展示（原有）
 	if data.IntradaySeries != nil {
 		sb.WriteString("日内数据（3分钟周期，从旧到新）:\n\n")
-		sb.WriteString(fmt.Sprintf("10期ATR: %.3f \n\n", data.IntradaySeries.ATR10))
+		sb.WriteString(fmt.Sprintf("10期ATR: "+nf+" \n\n", data.IntradaySeries.ATR10))
+		sb.WriteString(fmt.Sprintf("14期ATR%%: %.3f%%\n\n", data.IntradaySeries.ATR14Percent))
+		sb.WriteString(fmt.Sprintf("布林带(20,2): 上轨="+nf+", 中轨="+nf+", 下轨="+nf+"\n\n",
+			data.IntradaySeries.BBUpper, data.IntradaySeries.BBMiddle, data.IntradaySeries.BBLower))
+		sb.WriteString(squeezeNote(data.IntradaySeries))
+		sb.WriteString(fmt.Sprintf("随机指标(14,3): %%K=%.2f, %%D=%.2f\n\n", data.IntradaySeries.StochK, data.IntradaySeries.StochD))
+		sb.WriteString(fmt.Sprintf("随机RSI(14,14): %.2f\n\n", data.IntradaySeries.StochRSI))
+		sb.WriteString(fmt.Sprintf("威廉指标%%R(14): %.2f\n\n", data.IntradaySeries.WilliamsR))
+		sb.WriteString(fmt.Sprintf("变动率ROC(10): %.2f%%\n\n", data.IntradaySeries.ROC))
+		sb.WriteString(fmt.Sprintf("超级趋势(10,3): "+nf+" (%s)\n\n", data.IntradaySeries.Supertrend, supertrendLabel(data.IntradaySeries.SupertrendBullish)))
+		if note := emaCrossNote(data.IntradaySeries); note != "" {
+			sb.WriteString(note)
+		}
+		if note := ribbonNote(data.IntradaySeries); note != "" {
+			sb.WriteString(note)
+		}
+		if note := rsiDivergenceNote(data.IntradaySeries); note != "" {
+			sb.WriteString(note)
+		}
+		if len(data.IntradaySeries.OBVValues) > 0 {
+			sb.WriteString(fmt.Sprintf("OBV序列: %s\n\n", formatFloatSlice(data.IntradaySeries.OBVValues, opts.IndicatorDecimals)))
+		}
 		if len(data.IntradaySeries.VolumeValues) > 0 {
-			sb.WriteString(fmt.Sprintf("成交量序列: %s\n", formatFloatSlice(data.IntradaySeries.VolumeValues)))
+			sb.WriteString(fmt.Sprintf("成交量序列: %s\n", formatFloatSlice(data.IntradaySeries.VolumeValues, opts.IndicatorDecimals)))
 			sb.WriteString(fmt.Sprintf("平均成交量: %.2f, 量能放大倍数: %.2f\n\n", data.IntradaySeries.VolumeAverage, data.IntradaySeries.VolumeSpikeRatio))
 		}
 		if len(data.IntradaySeries.MidPrices) > 0 {
-			sb.WriteString(fmt.Sprintf("中间价: %s\n\n", formatFloatSlice(data.IntradaySeries.MidPrices)))
+			sb.WriteString(fmt.Sprintf("中间价: %s\n\n", formatFloatSlice(data.IntradaySeries.MidPrices, opts.IndicatorDecimals)))
 		}
 		if len(data.IntradaySeries.EMA20Values) > 0 {
-			sb.WriteString(fmt.Sprintf("20期EMA指标: %s\n\n", formatFloatSlice(data.IntradaySeries.EMA20Values)))
+			sb.WriteString(fmt.Sprintf("20期EMA指标: %s\n\n", formatFloatSlice(data.IntradaySeries.EMA20Values, opts.IndicatorDecimals)))
 		}
 		if len(data.IntradaySeries.MACDValues10208) > 0 {
-			sb.WriteString(fmt.Sprintf("MACD(10,20,8)指标: %s\n\n", formatFloatSlice(data.IntradaySeries.MACDValues10208)))
+			sb.WriteString(fmt.Sprintf("MACD(10,20,8)指标: %s\n\n", formatFloatSlice(data.IntradaySeries.MACDValues10208, opts.IndicatorDecimals)))
+			sb.WriteString(fmt.Sprintf("MACD(10,20,8)柱状图: %s\n\n", formatFloatSlice(data.IntradaySeries.MACDHist10208, opts.IndicatorDecimals)))
 		}
 		if len(data.IntradaySeries.RSI10Values) > 0 {
-			sb.WriteString(fmt.Sprintf("10期RSI指标: %s\n\n", formatFloatSlice(data.IntradaySeries.RSI10Values)))
+			sb.WriteString(fmt.Sprintf("10期RSI指标: %s\n\n", formatFloatSlice(data.IntradaySeries.RSI10Values, opts.IndicatorDecimals)))
 		}
 		if len(data.IntradaySeries.RSI14Values) > 0 {
-			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.IntradaySeries.RSI14Values)))
+			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.IntradaySeries.RSI14Values, opts.IndicatorDecimals)))
 		}
 	}
 
 	// 新增：15分钟数据展示
 	if data.Intraday15m != nil {
 		sb.WriteString("日内数据（15分钟周期，从旧到新）:\n\n")
-		sb.WriteString(fmt.Sprintf("12期ATR: %.3f \n\n", data.Intraday15m.ATR12))
+		sb.WriteString(fmt.Sprintf("12期ATR: "+nf+" \n\n", data.Intraday15m.ATR12))
+		sb.WriteString(fmt.Sprintf("14期ATR%%: %.3f%%\n\n", data.Intraday15m.ATR14Percent))
+		sb.WriteString(fmt.Sprintf("布林带(20,2): 上轨="+nf+", 中轨="+nf+", 下轨="+nf+"\n\n",
+			data.Intraday15m.BBUpper, data.Intraday15m.BBMiddle, data.Intraday15m.BBLower))
+		sb.WriteString(squeezeNote(data.Intraday15m))
+		sb.WriteString(fmt.Sprintf("随机指标(14,3): %%K=%.2f, %%D=%.2f\n\n", data.Intraday15m.StochK, data.Intraday15m.StochD))
+		sb.WriteString(fmt.Sprintf("随机RSI(14,14): %.2f\n\n", data.Intraday15m.StochRSI))
+		sb.WriteString(fmt.Sprintf("威廉指标%%R(14): %.2f\n\n", data.Intraday15m.WilliamsR))
+		sb.WriteString(fmt.Sprintf("变动率ROC(10): %.2f%%\n\n", data.Intraday15m.ROC))
+		sb.WriteString(fmt.Sprintf("超级趋势(10,3): "+nf+" (%s)\n\n", data.Intraday15m.Supertrend, supertrendLabel(data.Intraday15m.SupertrendBullish)))
+		if note := emaCrossNote(data.Intraday15m); note != "" {
+			sb.WriteString(note)
+		}
+		if note := ribbonNote(data.Intraday15m); note != "" {
+			sb.WriteString(note)
+		}
+		if note := rsiDivergenceNote(data.Intraday15m); note != "" {
+			sb.WriteString(note)
+		}
+		if len(data.Intraday15m.OBVValues) > 0 {
+			sb.WriteString(fmt.Sprintf("OBV序列: %s\n\n", formatFloatSlice(data.Intraday15m.OBVValues, opts.IndicatorDecimals)))
+		}
 		if len(data.Intraday15m.MidPrices) > 0 {
-			sb.WriteString(fmt.Sprintf("中间价: %s\n\n", formatFloatSlice(data.Intraday15m.MidPrices)))
+			sb.WriteString(fmt.Sprintf("中间价: %s\n\n", formatFloatSlice(data.Intraday15m.MidPrices, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday15m.EMA20Values) > 0 {
-			sb.WriteString(fmt.Sprintf("20期EMA指标: %s\n\n", formatFloatSlice(data.Intraday15m.EMA20Values)))
+			sb.WriteString(fmt.Sprintf("20期EMA指标: %s\n\n", formatFloatSlice(data.Intraday15m.EMA20Values, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday15m.MACDValues12269) > 0 {
-			sb.WriteString(fmt.Sprintf("MACD(12,26,9)指标: %s\n\n", formatFloatSlice(data.Intraday15m.MACDValues12269)))
+			sb.WriteString(fmt.Sprintf("MACD(12,26,9)指标: %s\n\n", formatFloatSlice(data.Intraday15m.MACDValues12269, opts.IndicatorDecimals)))
+			sb.WriteString(fmt.Sprintf("MACD(12,26,9)柱状图: %s\n\n", formatFloatSlice(data.Intraday15m.MACDHist12269, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday15m.RSI7Values) > 0 {
-			sb.WriteString(fmt.Sprintf("7期RSI指标: %s\n\n", formatFloatSlice(data.Intraday15m.RSI7Values)))
+			sb.WriteString(fmt.Sprintf("7期RSI指标: %s\n\n", formatFloatSlice(data.Intraday15m.RSI7Values, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday15m.RSI14Values) > 0 {
-			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.Intraday15m.RSI14Values)))
+			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.Intraday15m.RSI14Values, opts.IndicatorDecimals)))
 		}
 	}
 
 	// 新增：1小时数据展示
 	if data.Intraday1h != nil {
 		sb.WriteString("日内数据（1小时周期，从旧到新）:\n\n")
-		sb.WriteString(fmt.Sprintf("6期ATR: %.3f vs 14期ATR: %.3f\n\n", data.Intraday1h.ATR6, data.Intraday1h.ATR14))
+		sb.WriteString(fmt.Sprintf("6期ATR: "+nf+" vs 14期ATR: "+nf+"\n\n", data.Intraday1h.ATR6, data.Intraday1h.ATR14))
+		sb.WriteString(fmt.Sprintf("14期ATR%%: %.3f%%\n\n", data.Intraday1h.ATR14Percent))
+		sb.WriteString(fmt.Sprintf("布林带(20,2): 上轨="+nf+", 中轨="+nf+", 下轨="+nf+"\n\n",
+			data.Intraday1h.BBUpper, data.Intraday1h.BBMiddle, data.Intraday1h.BBLower))
+		sb.WriteString(squeezeNote(data.Intraday1h))
+		sb.WriteString(fmt.Sprintf("随机指标(14,3): %%K=%.2f, %%D=%.2f\n\n", data.Intraday1h.StochK, data.Intraday1h.StochD))
+		sb.WriteString(fmt.Sprintf("随机RSI(14,14): %.2f\n\n", data.Intraday1h.StochRSI))
+		sb.WriteString(fmt.Sprintf("威廉指标%%R(14): %.2f\n\n", data.Intraday1h.WilliamsR))
+		sb.WriteString(fmt.Sprintf("超级趋势(10,3): "+nf+" (%s)\n\n", data.Intraday1h.Supertrend, supertrendLabel(data.Intraday1h.SupertrendBullish)))
+		if note := emaCrossNote(data.Intraday1h); note != "" {
+			sb.WriteString(note)
+		}
+		if note := ribbonNote(data.Intraday1h); note != "" {
+			sb.WriteString(note)
+		}
+		if note := rsiDivergenceNote(data.Intraday1h); note != "" {
+			sb.WriteString(note)
+		}
+		if len(data.Intraday1h.OBVValues) > 0 {
+			sb.WriteString(fmt.Sprintf("OBV序列: %s\n\n", formatFloatSlice(data.Intraday1h.OBVValues, opts.IndicatorDecimals)))
+		}
 
 		if len(data.Intraday1h.MidPrices) > 0 {
-			sb.WriteString(fmt.Sprintf("中间价: %s\n\n", formatFloatSlice(data.Intraday1h.MidPrices)))
+			sb.WriteString(fmt.Sprintf("中间价: %s\n\n", formatFloatSlice(data.Intraday1h.MidPrices, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday1h.EMA20Values) > 0 {
-			sb.WriteString(fmt.Sprintf("20期EMA指标: %s\n\n", formatFloatSlice(data.Intraday1h.EMA20Values)))
+			sb.WriteString(fmt.Sprintf("20期EMA指标: %s\n\n", formatFloatSlice(data.Intraday1h.EMA20Values, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday1h.MACDValues12269) > 0 {
-			sb.WriteString(fmt.Sprintf("MACD(12,26,9)指标: %s\n\n", formatFloatSlice(data.Intraday1h.MACDValues12269)))
+			sb.WriteString(fmt.Sprintf("MACD(12,26,9)指标: %s\n\n", formatFloatSlice(data.Intraday1h.MACDValues12269, opts.IndicatorDecimals)))
+			sb.WriteString(fmt.Sprintf("MACD(12,26,9)柱状图: %s\n\n", formatFloatSlice(data.Intraday1h.MACDHist12269, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday1h.RSI9Values) > 0 {
-			sb.WriteString(fmt.Sprintf("9期RSI指标: %s\n\n", formatFloatSlice(data.Intraday1h.RSI9Values)))
+			sb.WriteString(fmt.Sprintf("9期RSI指标: %s\n\n", formatFloatSlice(data.Intraday1h.RSI9Values, opts.IndicatorDecimals)))
 		}
 		if len(data.Intraday1h.RSI14Values) > 0 {
-			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.Intraday1h.RSI14Values)))
+			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.Intraday1h.RSI14Values, opts.IndicatorDecimals)))
 		}
 	}
 
 	// 4小时数据展示（原有）
 	if data.LongerTermContext != nil {
 		sb.WriteString("长期数据（4小时周期）:\n\n")
-		sb.WriteString(fmt.Sprintf("20期EMA: %.3f vs 50期EMA: %.3f\n\n",
+		sb.WriteString(fmt.Sprintf("20期EMA: "+nf+" vs 50期EMA: "+nf+"\n\n",
 			data.LongerTermContext.EMA20, data.LongerTermContext.EMA50))
-		sb.WriteString(fmt.Sprintf("3期ATR: %.3f vs 14期ATR: %.3f\n\n",
+		sb.WriteString(fmt.Sprintf("3期ATR: "+nf+" vs 14期ATR: "+nf+"\n\n",
 			data.LongerTermContext.ATR3, data.LongerTermContext.ATR14))
-		sb.WriteString(fmt.Sprintf("当前成交量: %.3f vs 平均成交量: %.3f\n\n",
-			data.LongerTermContext.CurrentVolume, data.LongerTermContext.AverageVolume))
+		sb.WriteString(fmt.Sprintf("14期ATR%%: %.3f%%\n\n", data.LongerTermContext.ATR14Percent))
+		sb.WriteString(fmt.Sprintf("VWAP: "+nf+" (±%.0fσ: "+nf+" ~ "+nf+")\n\n", data.LongerTermContext.VWAP,
+			vwapBandMult, data.LongerTermContext.VWAPLower, data.LongerTermContext.VWAPUpper))
+		sb.WriteString(fmt.Sprintf("14期ADX: %.2f (+DI: %.2f, -DI: %.2f)\n\n",
+			data.LongerTermContext.ADX14, data.LongerTermContext.PlusDI, data.LongerTermContext.MinusDI))
+		sb.WriteString(fmt.Sprintf("20期CCI: %.2f\n\n", data.LongerTermContext.CCI20))
+		sb.WriteString(fmt.Sprintf("20期CMF: %.3f\n\n", data.LongerTermContext.CMF20))
+		sb.WriteString(fmt.Sprintf("唐奇安通道(20): 上轨="+nf+", 中轨="+nf+", 下轨="+nf+"\n\n",
+			data.LongerTermContext.DonchianUpper, data.LongerTermContext.DonchianMid, data.LongerTermContext.DonchianLower))
+		if note := breakoutNote(data.LongerTermContext); note != "" {
+			sb.WriteString(note)
+		}
+		sb.WriteString(fmt.Sprintf("成交量分布: POC="+nf+", 价值区域="+nf+" ~ "+nf+"\n\n",
+			data.LongerTermContext.POC, data.LongerTermContext.ValueAreaLow, data.LongerTermContext.ValueAreaHigh))
+		if note := ichimokuNote(data.LongerTermContext.Ichimoku, nf); note != "" {
+			sb.WriteString(note)
+		}
+		if note := fibNote(data.LongerTermContext.FibLevels, nf); note != "" {
+			sb.WriteString(note)
+		}
+		sb.WriteString(fmt.Sprintf("当前成交量: %s vs 平均成交量: %s\n\n",
+			formatVolume(data.LongerTermContext.CurrentVolume), formatVolume(data.LongerTermContext.AverageVolume)))
 		if len(data.LongerTermContext.MACDValues142810) > 0 {
-			sb.WriteString(fmt.Sprintf("MACD(14,28,10)指标: %s\n\n", formatFloatSlice(data.LongerTermContext.MACDValues142810)))
+			sb.WriteString(fmt.Sprintf("MACD(14,28,10)指标: %s\n\n", formatFloatSlice(data.LongerTermContext.MACDValues142810, opts.IndicatorDecimals)))
+			sb.WriteString(fmt.Sprintf("MACD(14,28,10)柱状图: %s\n\n", formatFloatSlice(data.LongerTermContext.MACDHist142810, opts.IndicatorDecimals)))
 		}
 		if len(data.LongerTermContext.RSI14Values) > 0 {
-			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.LongerTermContext.RSI14Values)))
+			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.LongerTermContext.RSI14Values, opts.IndicatorDecimals)))
 		}
 		if len(data.LongerTermContext.RSI21Values) > 0 {
-			sb.WriteString(fmt.Sprintf("21期RSI指标: %s\n\n", formatFloatSlice(data.LongerTermContext.RSI21Values)))
+			sb.WriteString(fmt.Sprintf("21期RSI指标: %s\n\n", formatFloatSlice(data.LongerTermContext.RSI21Values, opts.IndicatorDecimals)))
 		}
 	}
 
 	// 新增：1天数据展示
 	if data.LongerTerm1d != nil {
-		sb.WriteString("长期数据（1天周期）:\n\n")
-		sb.WriteString(fmt.Sprintf("20期EMA: %.3f vs 50期EMA: %.3f\n\n",
-			data.LongerTerm1d.EMA20, data.LongerTerm1d.EMA50))
-		sb.WriteString(fmt.Sprintf("3期ATR: %.3f vs 14期ATR: %.3f\n\n",
-			data.LongerTerm1d.ATR3, data.LongerTerm1d.ATR14))
-		sb.WriteString(fmt.Sprintf("当前成交量: %.3f vs 平均成交量: %.3f\n\n",
-			data.LongerTerm1d.CurrentVolume, data.LongerTerm1d.AverageVolume))
-		if len(data.LongerTerm1d.MACDValues12269) > 0 {
-			sb.WriteString(fmt.Sprintf("MACD(12,26,9)指标: %s\n\n", formatFloatSlice(data.LongerTerm1d.MACDValues12269)))
+		writeLongerTermBlock(&sb, "1天", data.LongerTerm1d, nf, opts.IndicatorDecimals)
+	}
+
+	// 周线/月线（Options.Weekly/Monthly 或 Timeframes 包含 1w/1M 时）
+	if data.LongerTerm1w != nil {
+		writeLongerTermBlock(&sb, "1周", data.LongerTerm1w, nf, opts.IndicatorDecimals)
+	}
+	if data.LongerTerm1Mo != nil {
+		writeLongerTermBlock(&sb, "1月", data.LongerTerm1Mo, nf, opts.IndicatorDecimals)
+	}
+
+	// 通过 GetWithOptions 请求的其他周期
+	extra := make([]string, 0, len(data.Timeframes))
+	for tf := range data.Timeframes {
+		if !legacyTimeframes[tf] {
+			extra = append(extra, tf)
+		}
+	}
+	for _, tf := range sortedTimeframes(extra) {
+		series := data.Timeframes[tf]
+		if series == nil {
+			continue
 		}
-		if len(data.LongerTerm1d.RSI14Values) > 0 {
-			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(data.LongerTerm1d.RSI14Values)))
+		sb.WriteString(fmt.Sprintf("周期数据（%s周期，从旧到新）:\n\n", tf))
+		sb.WriteString(fmt.Sprintf("14期ATR: "+nf+"\n\n", series.ATR14))
+		if len(series.MidPrices) > 0 {
+			sb.WriteString(fmt.Sprintf("中间价: %s\n\n", formatFloatSlice(series.MidPrices, opts.IndicatorDecimals)))
+		}
+		if len(series.EMA20Values) > 0 {
+			sb.WriteString(fmt.Sprintf("20期EMA指标: %s\n\n", formatFloatSlice(series.EMA20Values, opts.IndicatorDecimals)))
+		}
+		if len(series.MACDValues12269) > 0 {
+			sb.WriteString(fmt.Sprintf("MACD(12,26,9)指标: %s\n\n", formatFloatSlice(series.MACDValues12269, opts.IndicatorDecimals)))
+			sb.WriteString(fmt.Sprintf("MACD(12,26,9)柱状图: %s\n\n", formatFloatSlice(series.MACDHist12269, opts.IndicatorDecimals)))
+		}
+		if len(series.RSI14Values) > 0 {
+			sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(series.RSI14Values, opts.IndicatorDecimals)))
 		}
 	}
 
 	return sb.String()
 }
 
-// formatFloatSlice 格式化float64切片为字符串
-func formatFloatSlice(values []float64) string {
+// legacyTimeframes 在 Format 中已有专属展示段落的周期
+var legacyTimeframes = map[string]bool{"3m": true, "15m": true, "1h": true, "4h": true, "1d": true, "1w": true, "1M": true}
+
+// writeLongerTermBlock 输出一个长期周期(1d/1w/1M)的数据段落，period 为展示用的周期名称
+func writeLongerTermBlock(sb *strings.Builder, period string, lt *LongerTermData, nf string, decimals int) {
+	sb.WriteString(fmt.Sprintf("长期数据（%s周期）:\n\n", period))
+	sb.WriteString(fmt.Sprintf("20期EMA: "+nf+" vs 50期EMA: "+nf+"\n\n",
+		lt.EMA20, lt.EMA50))
+	sb.WriteString(fmt.Sprintf("3期ATR: "+nf+" vs 14期ATR: "+nf+"\n\n",
+		lt.ATR3, lt.ATR14))
+	sb.WriteString(fmt.Sprintf("14期ATR%%: %.3f%%\n\n", lt.ATR14Percent))
+	sb.WriteString(fmt.Sprintf("VWAP: "+nf+" (±%.0fσ: "+nf+" ~ "+nf+")\n\n", lt.VWAP,
+		vwapBandMult, lt.VWAPLower, lt.VWAPUpper))
+	sb.WriteString(fmt.Sprintf("14期ADX: %.2f (+DI: %.2f, -DI: %.2f)\n\n",
+		lt.ADX14, lt.PlusDI, lt.MinusDI))
+	sb.WriteString(fmt.Sprintf("20期CCI: %.2f\n\n", lt.CCI20))
+	sb.WriteString(fmt.Sprintf("20期CMF: %.3f\n\n", lt.CMF20))
+	sb.WriteString(fmt.Sprintf("唐奇安通道(20): 上轨="+nf+", 中轨="+nf+", 下轨="+nf+"\n\n",
+		lt.DonchianUpper, lt.DonchianMid, lt.DonchianLower))
+	if note := breakoutNote(lt); note != "" {
+		sb.WriteString(note)
+	}
+	sb.WriteString(fmt.Sprintf("成交量分布: POC="+nf+", 价值区域="+nf+" ~ "+nf+"\n\n",
+		lt.POC, lt.ValueAreaLow, lt.ValueAreaHigh))
+	if note := ichimokuNote(lt.Ichimoku, nf); note != "" {
+		sb.WriteString(note)
+	}
+	if note := fibNote(lt.FibLevels, nf); note != "" {
+		sb.WriteString(note)
+	}
+	sb.WriteString(fmt.Sprintf("当前成交量: %s vs 平均成交量: %s\n\n",
+		formatVolume(lt.CurrentVolume), formatVolume(lt.AverageVolume)))
+	if len(lt.MACDValues12269) > 0 {
+		sb.WriteString(fmt.Sprintf("MACD(12,26,9)指标: %s\n\n", formatFloatSlice(lt.MACDValues12269, decimals)))
+		sb.WriteString(fmt.Sprintf("MACD(12,26,9)柱状图: %s\n\n", formatFloatSlice(lt.MACDHist12269, decimals)))
+	}
+	if len(lt.RSI14Values) > 0 {
+		sb.WriteString(fmt.Sprintf("14期RSI指标: %s\n\n", formatFloatSlice(lt.RSI14Values, decimals)))
+	}
+}
+
+// emaCrossNote 最新K线发生EMA交叉时的提示文本，无交叉返回空字符串
+func emaCrossNote(d *IntradayData) string {
+	switch {
+	case d.EMACrossUp:
+		return fmt.Sprintf("EMA(%d/%d)金叉：快线刚上穿慢线\n\n", emaCrossFast, emaCrossSlow)
+	case d.EMACrossDown:
+		return fmt.Sprintf("EMA(%d/%d)死叉：快线刚下穿慢线\n\n", emaCrossFast, emaCrossSlow)
+	}
+	return ""
+}
+
+// rsiDivergenceNote 检测到RSI背离时的提示文本，无背离返回空字符串
+func rsiDivergenceNote(d *IntradayData) string {
+	switch {
+	case d.RSIBullishDivergence:
+		return "RSI(14)看涨背离：价格新低而RSI低点抬高\n\n"
+	case d.RSIBearishDivergence:
+		return "RSI(14)看跌背离：价格新高而RSI高点降低\n\n"
+	}
+	return ""
+}
+
+// supertrendLabel 超级趋势方向的展示文本
+func supertrendLabel(bullish bool) string {
+	if bullish {
+		return "多头"
+	}
+	return "空头"
+}
+
+// formatFloatSlice 按 decimals 位小数格式化float64切片为字符串
+func formatFloatSlice(values []float64, decimals int) string {
 	strValues := make([]string, len(values))
 	for i, v := range values {
-		strValues[i] = fmt.Sprintf("%.3f", v)
+		strValues[i] = strconv.FormatFloat(v, 'f', decimals, 64)
 	}
 	return "[" + strings.Join(strValues, ", ") + "]"
 }
 
+// 成交量输出样式
+const (
+	VolumeStylePlain     = "plain"     // 1234567.891
+	VolumeStyleSeparated = "separated" // 1,234,567.89
+	VolumeStyleCompact   = "compact"   // 1.23M
+)
+
+// VolumeFormatStyle Format 中长期数据成交量的输出样式，默认使用 K/M/B 缩写
+var VolumeFormatStyle = VolumeStyleCompact
+
+// VolumeDecimals 成交量输出保留的小数位数
+var VolumeDecimals = 2
+
+// formatVolume 按 VolumeFormatStyle 格式化成交量
+func formatVolume(v float64) string {
+	switch VolumeFormatStyle {
+	case VolumeStyleSeparated:
+		return formatWithSeparators(v, VolumeDecimals)
+	case VolumeStyleCompact:
+		abs := math.Abs(v)
+		switch {
+		case abs >= 1e9:
+			return strconv.FormatFloat(v/1e9, 'f', VolumeDecimals, 64) + "B"
+		case abs >= 1e6:
+			return strconv.FormatFloat(v/1e6, 'f', VolumeDecimals, 64) + "M"
+		case abs >= 1e3:
+			return strconv.FormatFloat(v/1e3, 'f', VolumeDecimals, 64) + "K"
+		}
+		return strconv.FormatFloat(v, 'f', VolumeDecimals, 64)
+	default:
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	}
+}
+
+// formatWithSeparators 以千分位逗号分隔整数部分
+func formatWithSeparators(v float64, decimals int) string {
+	str := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intPart, fracPart := str, ""
+	if dot := strings.IndexByte(str, '.'); dot >= 0 {
+		intPart, fracPart = str[:dot], str[dot:]
+	}
+
+	var sb strings.Builder
+	if v < 0 {
+		sb.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteString(fracPart)
+	return sb.String()
+}
+
+// knownQuotes 可识别的计价币种（按长度优先匹配）
+var knownQuotes = []string{"USDT", "USDC", "BUSD", "BTC", "ETH"}
+
 // Normalize 标准化symbol,确保是USDT交易对
 func Normalize(symbol string) string {
-	symbol = strings.ToUpper(symbol)
-	if strings.HasSuffix(symbol, "USDT") {
-		return symbol
+	return NormalizeWithQuote(symbol, "USDT")
+}
+
+// NormalizeWithQuote 标准化symbol：转大写、去除分隔符(/ - _)、按 SetSymbolAliases 替换币种别名，
+// 已以可识别计价币种(USDT/USDC/BUSD/BTC/ETH)结尾时保持不变，否则追加 quote
+// 例如 "btc-usdc" → "BTCUSDC"，"ETH/BTC" → "ETHBTC"，"sol" → "SOLUSDT"，"XBT" → "BTCUSDT"
+// 注意：无分隔符时按后缀识别计价币种，以 BTC/ETH 结尾的基础币（如 WBTC）需写成 "WBTC/USDT"
+func NormalizeWithQuote(symbol, quote string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	quote = strings.ToUpper(quote)
+
+	// 带分隔符时分隔符后的部分即计价币种
+	if idx := strings.IndexAny(symbol, "/-_"); idx >= 0 {
+		base := resolveAlias(symbol[:idx])
+		explicitQuote := strings.Map(func(r rune) rune {
+			if strings.ContainsRune("/-_", r) {
+				return -1
+			}
+			return r
+		}, symbol[idx+1:])
+		if explicitQuote == "" {
+			explicitQuote = quote
+		}
+		return base + resolveAlias(explicitQuote)
+	}
+
+	for _, q := range knownQuotes {
+		if len(symbol) > len(q) && strings.HasSuffix(symbol, q) {
+			return resolveAlias(strings.TrimSuffix(symbol, q)) + q
+		}
 	}
-	return symbol + "USDT"
+	return resolveAlias(symbol) + quote
 }
 
 // parseFloat 解析float值
@@ -0,0 +1,335 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		symbol string
+		quote  string
+		want   string
+	}{
+		{"btc", "USDT", "BTCUSDT"},
+		{"BTCUSDT", "USDT", "BTCUSDT"},
+		{"SOLUSDT", "USDT", "SOLUSDT"},
+		{" sol ", "USDT", "SOLUSDT"},
+		{"WBTC/USDT", "USDT", "WBTCUSDT"},
+		{"BTCUSDC", "USDT", "BTCUSDC"},
+		{"ETHBTC", "USDT", "ETHBTC"},
+		{"BNBBUSD", "USDC", "BNBBUSD"},
+		{"usdt", "USDT", "USDTUSDT"}, // 仅有计价币种本身时不视为已带计价币种
+		{"btc-usdc", "USDT", "BTCUSDC"},
+		{"ETH/BTC", "USDT", "ETHBTC"},
+		{"eth_", "USDC", "ETHUSDC"},
+		{"xbt", "USDT", "BTCUSDT"},
+		{"XBTUSDT", "USDT", "BTCUSDT"},
+		{"eth", "usdc", "ETHUSDC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol+"/"+tt.quote, func(t *testing.T) {
+			if got := NormalizeWithQuote(tt.symbol, tt.quote); got != tt.want {
+				t.Errorf("NormalizeWithQuote(%q, %q) = %q, want %q", tt.symbol, tt.quote, got, tt.want)
+			}
+		})
+	}
+	if got := Normalize("btcusdc"); got != "BTCUSDC" {
+		t.Errorf("Normalize(btcusdc) = %q, want BTCUSDC", got)
+	}
+}
+
+func TestMalformedNumericFieldsReturnError(t *testing.T) {
+	const validPremium = `{"symbol":"BTCUSDT","markPrice":"100","indexPrice":"99","lastFundingRate":"0.0001","nextFundingTime":0}`
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		fetch   func(ctx context.Context) error
+		wantErr string
+	}{
+		{
+			name: "持仓量", path: "/fapi/v1/openInterest",
+			body: `{"openInterest":"not-a-number","symbol":"BTCUSDT"}`,
+			fetch: func(ctx context.Context) error {
+				_, err := getOpenInterestData(ctx, "BTCUSDT")
+				return err
+			},
+			wantErr: "parse openInterest failed",
+		},
+		{
+			name: "资金费率", path: "/fapi/v1/premiumIndex",
+			body: strings.Replace(validPremium, `"0.0001"`, `"not-a-number"`, 1),
+			fetch: func(ctx context.Context) error {
+				_, err := getFundingRate(ctx, "BTCUSDT")
+				return err
+			},
+			wantErr: "parse lastFundingRate failed",
+		},
+		{
+			name: "标记价格", path: "/fapi/v1/premiumIndex",
+			body: strings.Replace(validPremium, `"markPrice":"100"`, `"markPrice":""`, 1),
+			fetch: func(ctx context.Context) error {
+				_, err := getFundingRate(ctx, "BTCUSDT")
+				return err
+			},
+			wantErr: "parse markPrice failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{tt.path: jsonHandler(tt.body)})
+			err := tt.fetch(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenInterestAverageFromHistory(t *testing.T) {
+	const hist = `[{"symbol":"BTCUSDT","sumOpenInterest":"100","timestamp":1},` +
+		`{"symbol":"BTCUSDT","sumOpenInterest":"200","timestamp":2},` +
+		`{"symbol":"BTCUSDT","sumOpenInterest":"600","timestamp":3}]`
+	tests := []struct {
+		name        string
+		hist        http.HandlerFunc
+		wantAverage float64
+		wantHistory int
+	}{
+		{"历史均值", jsonHandler(hist), 300, 3},
+		{"历史失败退化为最新值", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"code":-1000,"msg":"unavailable"}`, http.StatusBadRequest)
+		}, 550, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/openInterest":          jsonHandler(`{"openInterest":"550","symbol":"BTCUSDT","time":1}`),
+				"/futures/data/openInterestHist": tt.hist,
+			})
+			oi, err := getOpenInterestData(context.Background(), "BTCUSDT")
+			if err != nil {
+				t.Fatalf("getOpenInterestData: %v", err)
+			}
+			if oi.Latest != 550 || oi.Average != tt.wantAverage || len(oi.History) != tt.wantHistory {
+				t.Errorf("Latest/Average/History = %v/%v/%d, want 550/%v/%d",
+					oi.Latest, oi.Average, len(oi.History), tt.wantAverage, tt.wantHistory)
+			}
+		})
+	}
+}
+
+func TestGetReturnsRawKlines(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+
+	tests := []struct {
+		name       string
+		omitKlines bool
+	}{
+		{"默认保留", false},
+		{"OmitKlines", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Source = &fakeSource{klines: testKlines(100, 3*time.Minute)}
+			opts.OmitKlines = tt.omitKlines
+
+			data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+			if err != nil {
+				t.Fatalf("GetWithOptions: %v", err)
+			}
+			if tt.omitKlines {
+				if data.Klines != nil {
+					t.Errorf("Klines = %d 个周期, want nil", len(data.Klines))
+				}
+				return
+			}
+			for _, tf := range opts.Timeframes {
+				if len(data.Klines[tf]) == 0 {
+					t.Errorf("Klines[%q] 为空", tf)
+				}
+			}
+			if got := data.Klines["3m"]; got[len(got)-1].Close != data.CurrentPrice {
+				t.Errorf("3m 最后收盘价 = %v, CurrentPrice = %v", got[len(got)-1].Close, data.CurrentPrice)
+			}
+		})
+	}
+}
+
+func TestWeeklyMonthlyLongerTerm(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+
+	tests := []struct {
+		name                  string
+		modify                func(o *Options)
+		wantWeekly, wantMonth bool
+	}{
+		{"默认不获取", func(o *Options) {}, false, false},
+		{"Weekly", func(o *Options) { o.Weekly = true }, true, false},
+		{"Monthly", func(o *Options) { o.Monthly = true }, false, true},
+		{"Timeframes 包含1w/1M", func(o *Options) { o.Timeframes = []string{"3m", "4h", "1w", "1M"} }, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			src := &intervalSource{fakeSource: fakeSource{klines: testKlines(100, 3*time.Minute)}}
+			opts.Source = src
+			tt.modify(&opts)
+
+			data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+			if err != nil {
+				t.Fatalf("GetWithOptions: %v", err)
+			}
+			requested := strings.Join(src.intervals, ",")
+			for _, c := range []struct {
+				tf    string
+				data  *LongerTermData
+				want  bool
+				title string
+			}{
+				{"1w", data.LongerTerm1w, tt.wantWeekly, "长期数据（1周周期）"},
+				{"1M", data.LongerTerm1Mo, tt.wantMonth, "长期数据（1月周期）"},
+			} {
+				if (c.data != nil) != c.want {
+					t.Errorf("%s 长期数据 = %v, want 存在: %v", c.tf, c.data, c.want)
+				}
+				if got := strings.Contains(requested, "@"+c.tf); got != c.want {
+					t.Errorf("请求了 %s: %v, want %v（请求: %s）", c.tf, got, c.want, requested)
+				}
+				if got := strings.Contains(Format(data), c.title); got != c.want {
+					t.Errorf("Format 包含 %q: %v, want %v", c.title, got, c.want)
+				}
+			}
+			if tt.wantWeekly && data.LongerTerm1w.EMA20 == 0 {
+				t.Error("1w EMA20 未计算")
+			}
+		})
+	}
+}
+
+func TestGetFetchesConcurrently(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	slow := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			jsonHandler(body)(w, r)
+		}
+	}
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+		"/fapi/v1/openInterest": slow(`{"openInterest":"1234.5","symbol":"BTCUSDT"}`),
+		"/fapi/v1/premiumIndex": slow(`{"symbol":"BTCUSDT","markPrice":"100","indexPrice":"100","lastFundingRate":"0.0001","nextFundingTime":0}`),
+	})
+	opts := DefaultOptions()
+	src := &fakeSource{klines: testKlines(100, 3*time.Minute), delay: delay}
+	opts.Source = src
+
+	begin := time.Now()
+	data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+	elapsed := time.Since(begin)
+	if err != nil {
+		t.Fatalf("GetWithOptions: %v", err)
+	}
+	if n := int(src.calls.Load()); n != len(opts.Timeframes) {
+		t.Fatalf("数据源调用 %d 次, want %d", n, len(opts.Timeframes))
+	}
+	// 顺序获取至少需要 (周期数+2)×delay，并发时接近单次最慢调用
+	if sequential := time.Duration(len(opts.Timeframes)+2) * delay; elapsed >= sequential/2 {
+		t.Errorf("Get 耗时 %v，未并发获取（顺序耗时约 %v）", elapsed, sequential)
+	}
+	if data.OpenInterest == nil || data.FundingRate != 0.0001 {
+		t.Errorf("OpenInterest = %+v, FundingRate = %v; 合约数据应已获取", data.OpenInterest, data.FundingRate)
+	}
+}
+
+func TestSpotSkipsFuturesEndpoints(t *testing.T) {
+	var spotKlines atomic.Int32
+	spot := useTestServer(t, map[string]http.HandlerFunc{
+		"/api/v3/klines": countingHandler(&spotKlines, klinesHandler(100)),
+	})
+	// 合约地址指向另一个服务，记录所有被访问的路径
+	var mu sync.Mutex
+	var futuresPaths []string
+	futures := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		futuresPaths = append(futuresPaths, r.URL.Path)
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer futures.Close()
+	prevSpot := SpotBaseURL
+	t.Cleanup(func() { SpotBaseURL = prevSpot })
+	BaseURL, SpotBaseURL = futures.URL, spot.URL
+
+	opts := DefaultOptions()
+	opts.Market = Spot
+	src := &fakeSource{}
+	opts.Source = src
+	data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+	if err != nil {
+		t.Fatalf("GetWithOptions: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(futuresPaths) != 0 {
+		t.Errorf("现货模式访问了合约接口: %v", futuresPaths)
+	}
+	if n := src.calls.Load(); n != 0 {
+		t.Errorf("现货模式调用了合约K线数据源 %d 次", n)
+	}
+	if n := spotKlines.Load(); n != int32(len(opts.Timeframes)) {
+		t.Errorf("现货 klines 请求 %d 次, want %d", n, len(opts.Timeframes))
+	}
+	if data.OpenInterest != nil || data.Funding != nil || data.LongShort != nil {
+		t.Errorf("现货模式合约数据应为 nil: OI=%v Funding=%v LongShort=%v", data.OpenInterest, data.Funding, data.LongShort)
+	}
+	if data.CurrentPrice <= 0 {
+		t.Errorf("CurrentPrice = %v, want > 0", data.CurrentPrice)
+	}
+}
+
+func TestFormatWithSubCentDecimals(t *testing.T) {
+	data := &Data{
+		Symbol:         "1000SHIBUSDT",
+		CurrentPrice:   0.00001234,
+		CurrentEMA20:   0.0000120149,
+		IntradaySeries: &IntradayData{MidPrices: []float64{0.00001230, 0.00001234}},
+	}
+	tests := []struct {
+		name string
+		opts FormatOptions
+		want []string
+	}{
+		{"8位价格/10位指标", FormatOptions{PriceDecimals: 8, IndicatorDecimals: 10},
+			[]string{"当前价格 = 0.00001234", "20期EMA = 0.0000120149", "[0.0000123000, 0.0000123400]"}},
+		{"默认小数位丢失精度", DefaultFormatOptions(),
+			[]string{"当前价格 = 0.00", "20期EMA = 0.000", "[0.000, 0.000]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := FormatWith(data, tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("输出缺少 %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+
+	if got, want := formatFloatSlice([]float64{1.5, 0.000012345}, 6), "[1.500000, 0.000012]"; got != want {
+		t.Errorf("formatFloatSlice = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package market
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// MinPollInterval 轮询类接口(GetDebounced/Subscribe)允许的最小间隔，默认1秒
+// 低于该值的请求间隔会被提升到此下限，避免高频轮询导致 WebSocket/REST 后端被限频或封禁IP
+var MinPollInterval = time.Second
+
+// clampWarned 是否已提示过轮询间隔过小，进程内只警告一次
+var clampWarned atomic.Bool
+
+// clampPollInterval 将请求的轮询间隔提升到 MinPollInterval 下限
+func clampPollInterval(interval time.Duration) time.Duration {
+	if interval < MinPollInterval {
+		if clampWarned.CompareAndSwap(false, true) {
+			logger.Warnf("轮询间隔 %v 低于下限 %v，已自动调整为 %v", interval, MinPollInterval, MinPollInterval)
+		}
+		return MinPollInterval
+	}
+	return interval
+}
+
+type debouncedEntry struct {
+	data      *Data
+	fetchedAt time.Time
+}
+
+var debounceCache = struct {
+	mu   sync.Mutex
+	data map[string]debouncedEntry
+}{data: make(map[string]debouncedEntry)}
+
+// debounceGroup 合并同一交易对并发的缓存未命中，只发起一次 Get
+var debounceGroup singleflight.Group
+
+// GetDebounced 带去抖的 Get：同一交易对在 interval 内重复调用时直接返回上次结果，
+// 缓存未命中时并发调用共享同一次 Get 的结果
+// interval 小于 MinPollInterval 时按 MinPollInterval 处理；返回的 *Data 在调用方之间共享，请勿修改
+func GetDebounced(symbol string, interval time.Duration) (*Data, error) {
+	interval = clampPollInterval(interval)
+	symbol = Normalize(symbol)
+
+	if data, ok := debouncedData(symbol, interval); ok {
+		return data, nil
+	}
+
+	v, err, _ := debounceGroup.Do(symbol, func() (interface{}, error) {
+		// 排队期间上一次 Get 可能已写入缓存
+		if data, ok := debouncedData(symbol, interval); ok {
+			return data, nil
+		}
+		data, err := Get(symbol)
+		if err != nil {
+			return nil, err
+		}
+		debounceCache.mu.Lock()
+		debounceCache.data[symbol] = debouncedEntry{data: data, fetchedAt: now()}
+		debounceCache.mu.Unlock()
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Data), nil
+}
+
+// debouncedData 返回 interval 内缓存的结果
+func debouncedData(symbol string, interval time.Duration) (*Data, bool) {
+	debounceCache.mu.Lock()
+	entry, ok := debounceCache.data[symbol]
+	debounceCache.mu.Unlock()
+	if !ok || now().Sub(entry.fetchedAt) >= interval {
+		return nil, false
+	}
+	return entry.data, true
+}
@@ -0,0 +1,85 @@
+package market
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClampPollInterval(t *testing.T) {
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	t.Cleanup(func() { SetLogger(nil) })
+	clampWarned.Store(false)
+	t.Cleanup(func() { clampWarned.Store(false) })
+
+	tests := []struct {
+		in, want  time.Duration
+		wantWarns int
+	}{
+		{100 * time.Millisecond, MinPollInterval, 1},
+		{100 * time.Millisecond, MinPollInterval, 1}, // 只警告一次
+		{200 * time.Millisecond, MinPollInterval, 1},
+		{MinPollInterval, MinPollInterval, 1},
+		{5 * time.Second, 5 * time.Second, 1},
+	}
+	for _, tt := range tests {
+		if got := clampPollInterval(tt.in); got != tt.want {
+			t.Errorf("clampPollInterval(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+		if n := len(rec.warns); n != tt.wantWarns {
+			t.Errorf("clampPollInterval(%v) 后警告 %d 条, want %d", tt.in, n, tt.wantWarns)
+		}
+	}
+}
+
+func TestGetDebouncedCoalescesConcurrentMisses(t *testing.T) {
+	var premium atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+		"/fapi/v1/klines":       klinesHandler(100),
+		"/fapi/v1/premiumIndex": countingHandler(&premium, func(w http.ResponseWriter, r *http.Request) {
+			// 保持请求进行中，确保其余调用在首次 Get 完成前到达
+			time.Sleep(50 * time.Millisecond)
+			jsonHandler(`{"symbol":"BTCUSDT","markPrice":"100","indexPrice":"100","lastFundingRate":"0.0001"}`)(w, r)
+		}),
+	})
+	WSMonitorCli = useTestMonitor(t)
+	t.Cleanup(func() {
+		debounceCache.mu.Lock()
+		delete(debounceCache.data, "BTCUSDT")
+		debounceCache.mu.Unlock()
+	})
+
+	const callers = 10
+	results := make([]*Data, callers)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := GetDebounced("BTCUSDT", time.Minute)
+			if err != nil {
+				t.Errorf("GetDebounced() error = %v", err)
+			}
+			results[i] = data
+		}()
+	}
+	wg.Wait()
+
+	if n := premium.Load(); n != 1 {
+		t.Errorf("并发未命中发起 %d 次 Get, want 1", n)
+	}
+	for i, data := range results {
+		if data == nil || data != results[0] {
+			t.Errorf("results[%d] 未共享同一结果", i)
+		}
+	}
+
+	// 缓存有效期内不再请求
+	if data, err := GetDebounced("btc", time.Minute); err != nil || data != results[0] || premium.Load() != 1 {
+		t.Errorf("命中缓存 = %p, %v, 请求 %d 次; want 同一结果且不再请求", data, err, premium.Load())
+	}
+}
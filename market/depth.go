@@ -0,0 +1,92 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// DepthData 订单簿深度快照（前 Levels 档）
+type DepthData struct {
+	Levels        int     `json:"levels"`         // 统计的档位数
+	BidVolume     float64 `json:"bid_volume"`     // 买盘挂单总量（基础资产）
+	AskVolume     float64 `json:"ask_volume"`     // 卖盘挂单总量（基础资产）
+	Imbalance     float64 `json:"imbalance"`      // (买量-卖量)/(买量+卖量)，范围[-1,1]，正值表示买盘更厚
+	BestBid       float64 `json:"best_bid"`       // 买一价
+	BestAsk       float64 `json:"best_ask"`       // 卖一价
+	Spread        float64 `json:"spread"`         // 卖一 - 买一
+	SpreadPercent float64 `json:"spread_percent"` // 价差占中间价的百分比
+}
+
+// getOrderBookImbalance 获取订单簿前 limit 档并计算买卖量失衡与价差
+func getOrderBookImbalance(ctx context.Context, symbol string, limit int) (*DepthData, error) {
+	url := fmt.Sprintf("%s/fapi/v1/depth?symbol=%s&limit=%d", BaseURL, symbol, limit)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseDepth(body)
+}
+
+// parseDepth 解析 depth 响应（bids/asks 为 [价格, 数量] 字符串数组，买盘降序、卖盘升序）
+func parseDepth(body []byte) (*DepthData, error) {
+	var result struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	bidBest, bidVolume, err := sumDepthLevels(result.Bids)
+	if err != nil {
+		return nil, fmt.Errorf("parse bids failed: %w", err)
+	}
+	askBest, askVolume, err := sumDepthLevels(result.Asks)
+	if err != nil {
+		return nil, fmt.Errorf("parse asks failed: %w", err)
+	}
+
+	data := &DepthData{
+		Levels:    len(result.Bids),
+		BidVolume: bidVolume,
+		AskVolume: askVolume,
+		BestBid:   bidBest,
+		BestAsk:   askBest,
+	}
+	if len(result.Asks) > data.Levels {
+		data.Levels = len(result.Asks)
+	}
+	if total := bidVolume + askVolume; total > 0 {
+		data.Imbalance = (bidVolume - askVolume) / total
+	}
+	if bidBest > 0 && askBest > 0 {
+		data.Spread = askBest - bidBest
+		data.SpreadPercent = data.Spread / ((askBest + bidBest) / 2) * 100
+	}
+	return data, nil
+}
+
+// sumDepthLevels 返回第一档价格与各档数量之和
+func sumDepthLevels(levels [][]string) (best, volume float64, err error) {
+	for i, level := range levels {
+		if len(level) < 2 {
+			return 0, 0, fmt.Errorf("档位格式无效: %v", level)
+		}
+		price, err := strconv.ParseFloat(level[0], 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		qty, err := strconv.ParseFloat(level[1], 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		if i == 0 {
+			best = price
+		}
+		volume += qty
+	}
+	return best, volume, nil
+}
@@ -0,0 +1,44 @@
+package market
+
+import "testing"
+
+func TestParseDepth(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    DepthData
+		wantErr bool
+	}{
+		// 买量 3、卖量 1：失衡 = (3-1)/(3+1) = 0.5；价差 1 占中间价 100 的 1%
+		{"买盘更厚", `{"bids":[["99.5","1"],["99","2"]],"asks":[["100.5","1"]]}`,
+			DepthData{Levels: 2, BidVolume: 3, AskVolume: 1, Imbalance: 0.5, BestBid: 99.5, BestAsk: 100.5, Spread: 1, SpreadPercent: 1}, false},
+		{"卖盘更厚", `{"bids":[["99","1"]],"asks":[["101","1"],["102","2"]]}`,
+			DepthData{Levels: 2, BidVolume: 1, AskVolume: 3, Imbalance: -0.5, BestBid: 99, BestAsk: 101, Spread: 2, SpreadPercent: 2}, false},
+		{"单边为空", `{"bids":[["99","2"]],"asks":[]}`,
+			DepthData{Levels: 1, BidVolume: 2, Imbalance: 1, BestBid: 99}, false},
+		{"空订单簿", `{"bids":[],"asks":[]}`, DepthData{}, false},
+		{"档位缺少数量", `{"bids":[["99"]],"asks":[]}`, DepthData{}, true},
+		{"价格格式错误", `{"bids":[],"asks":[["x","1"]]}`, DepthData{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDepth([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Levels != tt.want.Levels {
+				t.Errorf("Levels = %d, want %d", got.Levels, tt.want.Levels)
+			}
+			assertFloatEqual(t, "BidVolume", got.BidVolume, tt.want.BidVolume)
+			assertFloatEqual(t, "AskVolume", got.AskVolume, tt.want.AskVolume)
+			assertFloatEqual(t, "Imbalance", got.Imbalance, tt.want.Imbalance)
+			assertFloatEqual(t, "BestBid", got.BestBid, tt.want.BestBid)
+			assertFloatEqual(t, "BestAsk", got.BestAsk, tt.want.BestAsk)
+			assertFloatEqual(t, "Spread", got.Spread, tt.want.Spread)
+			assertFloatEqual(t, "SpreadPercent", got.SpreadPercent, tt.want.SpreadPercent)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package market
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// derivativesData 合约衍生数据，各项获取失败时保持零值（OI 除外，失败时为全零的 OIData）
+type derivativesData struct {
+	oi                *OIData
+	funding           *FundingData
+	fundingHistory    []float64
+	longShort         *LongShortData
+	takerBuySellRatio float64
+	liquidations      *LiquidationData
+	depth             *DepthData
+	ticker            *TickerStats
+}
+
+// fetchDerivatives 并发获取 OI 与资金费率，以及 opts 开启的资金费率历史、多空比、主动买卖比、
+// 强平、24小时行情与订单簿深度（opts.DepthLimit<=0 时不获取）。各接口互不依赖，失败均不致命
+func fetchDerivatives(ctx context.Context, symbol string, opts Options) derivativesData {
+	var (
+		d  derivativesData
+		wg sync.WaitGroup
+	)
+	fetch := func(what string, f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := f()
+			logFetch(symbol, what, start, err)
+		}()
+	}
+
+	fetch("持仓量", func() (err error) {
+		d.oi, err = getOpenInterestData(ctx, symbol)
+		if err != nil {
+			// OI失败不影响整体,使用默认值
+			d.oi = &OIData{Latest: 0, Average: 0}
+		}
+		return err
+	})
+	fetch("资金费率", func() (err error) {
+		d.funding, err = getFundingRate(ctx, symbol)
+		return err
+	})
+	// 历史为空时百分位为0、趋势为flat
+	if opts.FundingHistory {
+		fetch("资金费率历史", func() (err error) {
+			d.fundingHistory, err = getFundingRateHistory(ctx, symbol, FundingHistoryLimit)
+			return err
+		})
+	}
+	if opts.LongShortRatio {
+		fetch("多空账户比", func() (err error) {
+			d.longShort, err = getLongShortRatio(ctx, symbol)
+			return err
+		})
+	}
+	if opts.TakerBuySellRatio {
+		fetch("主动买卖量比", func() (err error) {
+			d.takerBuySellRatio, err = getTakerBuySellRatio(ctx, symbol, TakerBuySellPeriod)
+			return err
+		})
+	}
+	// 接口可能不可用，保持为nil
+	if opts.Liquidations {
+		fetch("强平订单", func() (err error) {
+			d.liquidations, err = getLiquidations(ctx, symbol)
+			return err
+		})
+	}
+	if opts.Ticker24h {
+		fetch("24小时行情", func() (err error) {
+			d.ticker, err = get24hTicker(ctx, symbol)
+			return err
+		})
+	}
+	if opts.DepthLimit > 0 {
+		fetch("订单簿深度", func() (err error) {
+			d.depth, err = getOrderBookImbalance(ctx, symbol, opts.DepthLimit)
+			return err
+		})
+	}
+
+	wg.Wait()
+	return d
+}
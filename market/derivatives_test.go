@@ -0,0 +1,156 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFetchDerivativesLiquidationsOptIn(t *testing.T) {
+	const orders = `[{"symbol":"BTCUSDT","side":"SELL","averagePrice":"100","executedQty":"2","time":1700000000000}]`
+
+	tests := []struct {
+		name         string
+		liquidations bool
+		wantRequests int32
+	}{
+		{"默认不请求", false, 0},
+		{"显式开启", true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/allForceOrders": countingHandler(&requests, jsonHandler(orders)),
+			})
+			opts := DefaultOptions()
+			opts.Liquidations = tt.liquidations
+
+			d := fetchDerivatives(context.Background(), "BTCUSDT", opts)
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("allForceOrders 请求 %d 次, want %d", n, tt.wantRequests)
+			}
+			if got := d.liquidations != nil; got != tt.liquidations {
+				t.Errorf("liquidations != nil = %v, want %v", got, tt.liquidations)
+			}
+		})
+	}
+}
+
+func TestFetchDerivativesDepthDefaultOff(t *testing.T) {
+	const book = `{"bids":[["99","1"]],"asks":[["101","2"]]}`
+
+	tests := []struct {
+		name         string
+		depthLimit   int
+		wantRequests int32
+	}{
+		{"默认不请求", DefaultOptions().DepthLimit, 0},
+		{"指定档位", 20, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/depth": countingHandler(&requests, jsonHandler(book)),
+			})
+			opts := DefaultOptions()
+			opts.DepthLimit = tt.depthLimit
+
+			d := fetchDerivatives(context.Background(), "BTCUSDT", opts)
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("depth 请求 %d 次, want %d", n, tt.wantRequests)
+			}
+			if got := d.depth != nil; got != (tt.wantRequests > 0) {
+				t.Errorf("depth != nil = %v", got)
+			}
+		})
+	}
+}
+
+func TestFetchDerivativesSentimentOptIn(t *testing.T) {
+	// 默认只请求持仓量(含历史)与资金费率
+	defaultPaths := []string{"/fapi/v1/openInterest", "/futures/data/openInterestHist", "/fapi/v1/premiumIndex"}
+	optional := map[string]string{
+		"FundingHistory":    "/fapi/v1/fundingRate",
+		"LongShortRatio":    "/futures/data/globalLongShortAccountRatio",
+		"TakerBuySellRatio": "/futures/data/takerlongshortRatio",
+		"Ticker24h":         "/fapi/v1/ticker/24hr",
+	}
+	tests := []struct {
+		name   string
+		modify func(o *Options)
+		want   string // 额外请求的路径，为空表示只请求默认接口
+	}{
+		{"默认", func(o *Options) {}, ""},
+		{"FundingHistory", func(o *Options) { o.FundingHistory = true }, optional["FundingHistory"]},
+		{"LongShortRatio", func(o *Options) { o.LongShortRatio = true }, optional["LongShortRatio"]},
+		{"TakerBuySellRatio", func(o *Options) { o.TakerBuySellRatio = true }, optional["TakerBuySellRatio"]},
+		{"Ticker24h", func(o *Options) { o.Ticker24h = true }, optional["Ticker24h"]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make(map[string]*atomic.Int32)
+			routes := make(map[string]http.HandlerFunc)
+			for _, path := range defaultPaths {
+				counts[path] = &atomic.Int32{}
+				routes[path] = countingHandler(counts[path], jsonHandler(`{"openInterest":"1","symbol":"BTCUSDT"}`))
+			}
+			for _, path := range optional {
+				counts[path] = &atomic.Int32{}
+				routes[path] = countingHandler(counts[path], jsonHandler(`[]`))
+			}
+			useTestServer(t, routes)
+			opts := DefaultOptions()
+			tt.modify(&opts)
+
+			fetchDerivatives(context.Background(), "BTCUSDT", opts)
+			for path, count := range counts {
+				want := int32(0)
+				if path == tt.want {
+					want = 1
+				}
+				for _, p := range defaultPaths {
+					if path == p {
+						want = 1
+					}
+				}
+				if n := count.Load(); n != want {
+					t.Errorf("%s 请求 %d 次, want %d", path, n, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchDerivativesLogsFailures(t *testing.T) {
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	t.Cleanup(func() { SetLogger(nil) })
+	useTestServer(t, map[string]http.HandlerFunc{
+		// 持仓量接口未注册，返回404
+		"/fapi/v1/premiumIndex": jsonHandler(`{"symbol":"BTCUSDT","markPrice":"100","indexPrice":"100","lastFundingRate":"0.0001"}`),
+	})
+
+	d := fetchDerivatives(context.Background(), "BTCUSDT", DefaultOptions())
+	if d.oi == nil || d.oi.Latest != 0 {
+		t.Errorf("OI 获取失败时应为全零的 OIData，got %+v", d.oi)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	var oiWarned bool
+	for _, w := range rec.warns {
+		if strings.Contains(w, "BTCUSDT 持仓量 失败") {
+			oiWarned = true
+		}
+		if strings.Contains(w, "资金费率 失败") {
+			t.Errorf("成功的请求不应记录警告: %s", w)
+		}
+	}
+	if !oiWarned {
+		t.Errorf("OI 获取失败未记录警告，warns = %q", rec.warns)
+	}
+}
@@ -0,0 +1,44 @@
+package market
+
+import "math"
+
+// FieldDelta 单个字段在两次快照之间的变化
+type FieldDelta struct {
+	Abs float64 `json:"abs"` // 当前值 - 上次值
+	Pct float64 `json:"pct"` // 相对上次值绝对值的百分比变化，上次值为0时为0
+}
+
+// DataDelta 两次市场数据快照之间关键指标的变化
+type DataDelta struct {
+	Price        FieldDelta `json:"price"`
+	RSI7         FieldDelta `json:"rsi7"`
+	MACD         FieldDelta `json:"macd"`
+	OpenInterest FieldDelta `json:"open_interest"` // 持仓量最新值，任一快照缺少OI数据时为零值
+	FundingRate  FieldDelta `json:"funding_rate"`
+}
+
+// Diff 计算相对上一次快照 prev 的变化，便于轮询方在显著变动时告警而无需自行保存历史
+// prev 或 d 为 nil 时返回全零的变化
+func (d *Data) Diff(prev *Data) *DataDelta {
+	delta := &DataDelta{}
+	if d == nil || prev == nil {
+		return delta
+	}
+	delta.Price = fieldDelta(d.CurrentPrice, prev.CurrentPrice)
+	delta.RSI7 = fieldDelta(d.CurrentRSI7, prev.CurrentRSI7)
+	delta.MACD = fieldDelta(d.CurrentMACD, prev.CurrentMACD)
+	delta.FundingRate = fieldDelta(d.FundingRate, prev.FundingRate)
+	if d.OpenInterest != nil && prev.OpenInterest != nil {
+		delta.OpenInterest = fieldDelta(d.OpenInterest.Latest, prev.OpenInterest.Latest)
+	}
+	return delta
+}
+
+// fieldDelta 计算 current 相对 previous 的绝对与百分比变化
+func fieldDelta(current, previous float64) FieldDelta {
+	delta := FieldDelta{Abs: current - previous}
+	if previous != 0 {
+		delta.Pct = delta.Abs / math.Abs(previous) * 100
+	}
+	return delta
+}
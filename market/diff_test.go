@@ -0,0 +1,37 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	prev := &Data{CurrentPrice: 100, CurrentRSI7: 40, CurrentMACD: -2, FundingRate: 0.0001, OpenInterest: &OIData{Latest: 1000}}
+	curr := &Data{CurrentPrice: 110, CurrentRSI7: 50, CurrentMACD: 1, FundingRate: 0.0001, OpenInterest: &OIData{Latest: 900}}
+
+	tests := []struct {
+		name string
+		curr *Data
+		prev *Data
+		want *DataDelta
+	}{
+		{"各字段变化", curr, prev, &DataDelta{
+			Price:        FieldDelta{Abs: 10, Pct: 10},
+			RSI7:         FieldDelta{Abs: 10, Pct: 25},
+			MACD:         FieldDelta{Abs: 3, Pct: 150}, // 相对上次值的绝对值
+			OpenInterest: FieldDelta{Abs: -100, Pct: -10},
+			FundingRate:  FieldDelta{},
+		}},
+		{"上次值为0时百分比为0", &Data{CurrentPrice: 5}, &Data{}, &DataDelta{Price: FieldDelta{Abs: 5}}},
+		{"缺少OI", &Data{OpenInterest: &OIData{Latest: 1}}, &Data{}, &DataDelta{}},
+		{"prev 为 nil", curr, nil, &DataDelta{}},
+		{"当前为 nil", nil, prev, &DataDelta{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.curr.Diff(tt.prev); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package market
+
+import "math"
+
+// DivergenceWindow 背离检测使用的波段窗口（K线根数）
+// 窗口被等分为前后两段，分别取两段内的价格与指标极值进行比较
+var DivergenceWindow = 30
+
+// DetectMACDDivergence 检测MACD柱状图与价格的常规背离
+// 看涨背离：后半段价格创出更低低点，而柱状图低点抬高（且前一低点位于零轴下方）
+// 看跌背离：后半段价格创出更高高点，而柱状图高点降低（且前一高点位于零轴上方）
+// 柱状图通常先于RSI出现背离，两者可配合使用
+func DetectMACDDivergence(klines []Kline, short, long, signal int) (bullish, bearish bool) {
+	_, _, hist, start := macdSeries(klines, short, long, signal)
+	if start >= len(klines) {
+		return false, false
+	}
+	return detectDivergence(klines[start:], hist[start:], DivergenceWindow, true)
+}
+
+// DetectRSIDivergence 检测RSI与价格的常规背离，波段窗口为 DivergenceWindow
+// 看涨背离：后半段价格创出更低低点，而对应RSI低点抬高；看跌背离：价格创出更高高点，而RSI高点降低
+func DetectRSIDivergence(klines []Kline, period int) (bullish, bearish bool) {
+	if period <= 0 || len(klines) <= period {
+		return false, false
+	}
+	return rsiDivergence(klines, rsiSeries(klines, period), period)
+}
+
+// rsiDivergence 基于已算好的RSI序列（与 klines 对齐、前 period 个点无效）检测背离
+func rsiDivergence(klines []Kline, rsi []float64, period int) (bullish, bearish bool) {
+	if len(klines) <= period {
+		return false, false
+	}
+	return detectDivergence(klines[period:], rsi[period:], DivergenceWindow, false)
+}
+
+// detectDivergence 比较窗口前后两段的价格极值与振荡指标极值
+// osc 与 klines 逐点对齐；zeroCentered 为 true 时要求前一极值位于零轴另一侧（适用于MACD柱状图）
+func detectDivergence(klines []Kline, osc []float64, window int, zeroCentered bool) (bullish, bearish bool) {
+	if window < 4 || len(klines) < window || len(osc) != len(klines) {
+		return false, false
+	}
+
+	begin := len(klines) - window
+	mid := begin + window/2
+
+	prevLow, prevLowOsc := math.Inf(1), 0.0
+	prevHigh, prevHighOsc := math.Inf(-1), 0.0
+	for i := begin; i < mid; i++ {
+		if klines[i].Low < prevLow {
+			prevLow, prevLowOsc = klines[i].Low, osc[i]
+		}
+		if klines[i].High > prevHigh {
+			prevHigh, prevHighOsc = klines[i].High, osc[i]
+		}
+	}
+
+	recentLow, recentLowOsc := math.Inf(1), 0.0
+	recentHigh, recentHighOsc := math.Inf(-1), 0.0
+	for i := mid; i < len(klines); i++ {
+		if klines[i].Low < recentLow {
+			recentLow, recentLowOsc = klines[i].Low, osc[i]
+		}
+		if klines[i].High > recentHigh {
+			recentHigh, recentHighOsc = klines[i].High, osc[i]
+		}
+	}
+
+	bullish = recentLow < prevLow && recentLowOsc > prevLowOsc
+	bearish = recentHigh > prevHigh && recentHighOsc < prevHighOsc
+	if zeroCentered {
+		bullish = bullish && prevLowOsc < 0
+		bearish = bearish && prevHighOsc > 0
+	}
+	return bullish, bearish
+}
@@ -0,0 +1,40 @@
+package market
+
+import "testing"
+
+// divergenceCloses 预热后先急跌创出低点，反弹后缓跌创出更低低点：
+// 价格低点下移而缓跌段的RSI低点高于急跌段，构成看涨背离；mirror 为 true 时上下翻转得到看跌背离
+func divergenceCloses(mirror bool) []float64 {
+	closes := stepCloses(14, 100, 0.1)                  // RSI 预热
+	closes = append(closes, stepCloses(15, 100, -3)...) // 急跌至58
+	closes = append(closes, stepCloses(7, 59, 2)...)    // 反弹至71
+	closes = append(closes, stepCloses(8, 70, -2)...)   // 缓跌至56，低于前低
+	if mirror {
+		for i, c := range closes {
+			closes[i] = 200 - c
+		}
+	}
+	return closes
+}
+
+func TestDetectRSIDivergence(t *testing.T) {
+	tests := []struct {
+		name        string
+		closes      []float64
+		wantBullish bool
+		wantBearish bool
+	}{
+		{"价格更低低点、RSI低点抬高", divergenceCloses(false), true, false},
+		{"价格更高高点、RSI高点降低", divergenceCloses(true), false, true},
+		{"单边下跌无背离", stepCloses(60, 200, -1), false, false},
+		{"K线不足", stepCloses(30, 100, -1), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bullish, bearish := DetectRSIDivergence(closeKlines(0.5, tt.closes...), 14)
+			if bullish != tt.wantBullish || bearish != tt.wantBearish {
+				t.Errorf("DetectRSIDivergence = %v, %v; want %v, %v", bullish, bearish, tt.wantBullish, tt.wantBearish)
+			}
+		})
+	}
+}
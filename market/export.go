@@ -0,0 +1,145 @@
+package market
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON 将完整的市场数据（含各周期序列、OI与资金费率）序列化为紧凑的 JSON
+// 字段键统一为 snake_case（Klines 中的 Kline 沿用原有 camelCase 键），供程序化消费；人工阅读请使用 Format
+func (d *Data) JSON() ([]byte, error) {
+	if d == nil {
+		return nil, fmt.Errorf("市场数据为空")
+	}
+	return json.Marshal(d)
+}
+
+// JSONIndent 与 JSON 相同，但以两个空格缩进输出，便于日志与调试查看
+func (d *Data) JSONIndent() ([]byte, error) {
+	if d == nil {
+		return nil, fmt.Errorf("市场数据为空")
+	}
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// SummaryOptions 控制 Summary 输出的字段；交易对与当前价格总是输出
+type SummaryOptions struct {
+	RSI           bool // 7期RSI
+	MACD          bool // 3分钟MACD(DIF)
+	PriceChange1h bool // 1小时涨跌幅
+	PriceChange4h bool // 4小时涨跌幅
+	Funding       bool // 资金费率
+	OIChange1h    bool // 1小时持仓量变化率
+	Trend         bool // 多周期加权趋势(DominantTrend)
+}
+
+// DefaultSummaryOptions Summary 默认输出的字段：RSI、MACD、1小时涨跌幅与资金费率
+func DefaultSummaryOptions() SummaryOptions {
+	return SummaryOptions{RSI: true, MACD: true, PriceChange1h: true, Funding: true}
+}
+
+// Summary 返回单行摘要，便于看板与日志，例如：
+// "BTCUSDT 63250.00 | RSI7 62.1 | MACD +12.3 | 1h +0.8% | funding 1.2e-04"
+func (d *Data) Summary() string {
+	return d.SummaryWith(DefaultSummaryOptions())
+}
+
+// SummaryWith 按 opts 选择字段生成单行摘要
+func (d *Data) SummaryWith(opts SummaryOptions) string {
+	if d == nil {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("%s %.2f", d.Symbol, d.CurrentPrice)}
+	if opts.RSI {
+		parts = append(parts, fmt.Sprintf("RSI7 %.1f", d.CurrentRSI7))
+	}
+	if opts.MACD {
+		parts = append(parts, fmt.Sprintf("MACD %+.1f", d.CurrentMACD))
+	}
+	if opts.PriceChange1h {
+		parts = append(parts, fmt.Sprintf("1h %+.1f%%", d.PriceChange1h))
+	}
+	if opts.PriceChange4h {
+		parts = append(parts, fmt.Sprintf("4h %+.1f%%", d.PriceChange4h))
+	}
+	if opts.Funding {
+		parts = append(parts, fmt.Sprintf("funding %.1e", d.FundingRate))
+	}
+	if opts.OIChange1h && d.OpenInterest != nil {
+		parts = append(parts, fmt.Sprintf("OI 1h %+.2f%%", d.OpenInterest.Change1h*100))
+	}
+	if opts.Trend {
+		parts = append(parts, "trend "+DominantTrend(d))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// csvHeader CSV 导出的列
+var csvHeader = []string{"timeframe", "index", "mid_price", "ema20", "macd", "macd_hist", "rsi7", "rsi14"}
+
+// CSV 以字符串形式返回 WriteCSV 的结果，写入失败时返回空字符串
+func (d *Data) CSV() string {
+	var sb strings.Builder
+	if err := d.WriteCSV(&sb); err != nil {
+		return ""
+	}
+	return sb.String()
+}
+
+// WriteCSV 将各周期的日内指标序列按行写出（含表头），周期按时长升序分块
+// 每行对应一个中间价点（从旧到新）；EMA/MACD/RSI 序列较短时与中间价右对齐，缺失处留空
+func (d *Data) WriteCSV(w io.Writer) error {
+	if d == nil {
+		return fmt.Errorf("市场数据为空")
+	}
+
+	series := d.Timeframes
+	if len(series) == 0 {
+		series = map[string]*IntradayData{"3m": d.IntradaySeries, "15m": d.Intraday15m, "1h": d.Intraday1h}
+	}
+	timeframes := make([]string, 0, len(series))
+	for tf, s := range series {
+		if s != nil {
+			timeframes = append(timeframes, tf)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, tf := range sortedTimeframes(timeframes) {
+		s := series[tf]
+		n := len(s.MidPrices)
+		for i := 0; i < n; i++ {
+			row := []string{
+				tf,
+				strconv.Itoa(i),
+				csvCell(s.MidPrices, n, i),
+				csvCell(s.EMA20Values, n, i),
+				csvCell(s.MACDValues12269, n, i),
+				csvCell(s.MACDHist12269, n, i),
+				csvCell(s.RSI7Values, n, i),
+				csvCell(s.RSI14Values, n, i),
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvCell 取与长度为 n 的中间价序列右对齐后第 i 行的值，缺失时返回空字符串
+func csvCell(values []float64, n, i int) string {
+	j := i - (n - len(values))
+	if j < 0 || j >= len(values) {
+		return ""
+	}
+	return strconv.FormatFloat(values[j], 'f', -1, 64)
+}
@@ -0,0 +1,95 @@
+package market
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	data := &Data{
+		Symbol:        "BTCUSDT",
+		CurrentPrice:  63250,
+		CurrentRSI7:   62.14,
+		CurrentMACD:   12.34,
+		PriceChange1h: 0.81,
+		PriceChange4h: -2.45,
+		FundingRate:   0.00012,
+		OpenInterest:  &OIData{Change1h: 0.0125},
+		LongerTerm1d:  &LongerTermData{Trend: TrendUp},
+	}
+	tests := []struct {
+		name string
+		opts SummaryOptions
+		want string
+	}{
+		{"默认字段", DefaultSummaryOptions(), "BTCUSDT 63250.00 | RSI7 62.1 | MACD +12.3 | 1h +0.8% | funding 1.2e-04"},
+		{"仅交易对与价格", SummaryOptions{}, "BTCUSDT 63250.00"},
+		{"全部字段", SummaryOptions{RSI: true, MACD: true, PriceChange1h: true, PriceChange4h: true, Funding: true, OIChange1h: true, Trend: true},
+			"BTCUSDT 63250.00 | RSI7 62.1 | MACD +12.3 | 1h +0.8% | 4h -2.5% | funding 1.2e-04 | OI 1h +1.25% | trend up"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := data.SummaryWith(tt.opts); got != tt.want {
+				t.Errorf("SummaryWith =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	if got := data.Summary(); got != data.SummaryWith(DefaultSummaryOptions()) {
+		t.Errorf("Summary() = %q，应与默认选项一致", got)
+	}
+	// 缺少 OI 时跳过该字段
+	noOI := *data
+	noOI.OpenInterest = nil
+	if got, want := noOI.SummaryWith(SummaryOptions{OIChange1h: true}), "BTCUSDT 63250.00"; got != want {
+		t.Errorf("无OI摘要 = %q, want %q", got, want)
+	}
+	if got := (*Data)(nil).Summary(); got != "" {
+		t.Errorf("nil Summary = %q, want 空字符串", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	data := &Data{Timeframes: map[string]*IntradayData{
+		"1h": {MidPrices: []float64{200, 201}, RSI7Values: []float64{55, 56}},
+		"3m": {
+			MidPrices:   []float64{100, 101, 102, 103, 104},
+			EMA20Values: []float64{100.5, 101.5, 102.5}, // 较短序列与中间价右对齐
+			RSI7Values:  []float64{40, 45, 50, 55, 60},
+		},
+	}}
+
+	records, err := csv.NewReader(strings.NewReader(data.CSV())).ReadAll()
+	if err != nil {
+		t.Fatalf("解析CSV失败: %v", err)
+	}
+	if len(records) != 1+5+2 {
+		t.Fatalf("行数 = %d, want 8", len(records))
+	}
+	for i, row := range records {
+		if len(row) != len(csvHeader) {
+			t.Errorf("第 %d 行 %d 列, want %d", i, len(row), len(csvHeader))
+		}
+	}
+
+	tests := []struct {
+		row  int
+		want []string
+	}{
+		{0, csvHeader},
+		{1, []string{"3m", "0", "100", "", "", "", "40", ""}},
+		{3, []string{"3m", "2", "102", "100.5", "", "", "50", ""}},
+		{5, []string{"3m", "4", "104", "102.5", "", "", "60", ""}},
+		{6, []string{"1h", "0", "200", "", "", "", "55", ""}},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(records[tt.row], ","); got != strings.Join(tt.want, ",") {
+			t.Errorf("第 %d 行 = %q, want %q", tt.row, got, strings.Join(tt.want, ","))
+		}
+	}
+
+	if err := (*Data)(nil).WriteCSV(&strings.Builder{}); err == nil {
+		t.Error("nil Data 应返回错误")
+	}
+}
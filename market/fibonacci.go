@@ -0,0 +1,68 @@
+package market
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fibRatios 斐波那契回撤比例（按从小到大排列，也是 Format 的输出顺序）
+var fibRatios = []float64{0.236, 0.382, 0.5, 0.618, 0.786}
+
+// fibLookback LongerTermData 斐波那契回撤使用的回看K线数
+const fibLookback = 50
+
+// calculateFibLevels 计算最近 lookback 根K线摆动高低点之间的斐波那契回撤价位，键为比例(如 "0.618")
+// 低点早于高点(上涨波段)时自高点向下回撤：高点 - 比例*(高-低)；
+// 高点早于低点(下跌波段)时自低点向上反弹：低点 + 比例*(高-低)
+// K线不足 lookback 根或高低点相同时返回 nil
+func calculateFibLevels(klines []Kline, lookback int) map[string]float64 {
+	if lookback <= 0 || len(klines) < lookback {
+		return nil
+	}
+
+	window := klines[len(klines)-lookback:]
+	highIdx, lowIdx := 0, 0
+	for i, k := range window {
+		if k.High > window[highIdx].High {
+			highIdx = i
+		}
+		if k.Low < window[lowIdx].Low {
+			lowIdx = i
+		}
+	}
+	high, low := window[highIdx].High, window[lowIdx].Low
+	swing := high - low
+	if swing <= 0 {
+		return nil
+	}
+
+	levels := make(map[string]float64, len(fibRatios))
+	for _, ratio := range fibRatios {
+		if lowIdx <= highIdx {
+			levels[fibKey(ratio)] = high - ratio*swing
+		} else {
+			levels[fibKey(ratio)] = low + ratio*swing
+		}
+	}
+	return levels
+}
+
+// fibKey 回撤比例对应的 map 键
+func fibKey(ratio float64) string {
+	return strconv.FormatFloat(ratio, 'f', -1, 64)
+}
+
+// fibNote 按比例顺序输出斐波那契回撤价位，无数据时返回空字符串
+func fibNote(levels map[string]float64, nf string) string {
+	if len(levels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(fibRatios))
+	for _, ratio := range fibRatios {
+		if price, ok := levels[fibKey(ratio)]; ok {
+			parts = append(parts, fmt.Sprintf("%s="+nf, fibKey(ratio), price))
+		}
+	}
+	return fmt.Sprintf("斐波那契回撤(%d): %s\n\n", fibLookback, strings.Join(parts, ", "))
+}
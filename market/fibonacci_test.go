@@ -0,0 +1,44 @@
+package market
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCalculateFibLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		klines  []Kline
+		want618 float64
+		want500 float64
+		wantNil bool
+	}{
+		// 100 → 200 上涨波段：自高点回撤
+		{"上涨波段", closeKlines(0, stepCloses(5, 100, 25)...), 200 - 0.618*100, 150, false},
+		// 200 → 100 下跌波段：自低点反弹
+		{"下跌波段", closeKlines(0, stepCloses(5, 200, -25)...), 100 + 0.618*100, 150, false},
+		{"高低点相同", closeKlines(0, stepCloses(5, 100, 0)...), 0, 0, true},
+		{"K线不足", closeKlines(0, stepCloses(4, 100, 25)...), 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := calculateFibLevels(tt.klines, 5)
+			if tt.wantNil {
+				if levels != nil {
+					t.Errorf("levels = %v, want nil", levels)
+				}
+				return
+			}
+			if len(levels) != len(fibRatios) {
+				t.Fatalf("levels = %v, want %d 个价位", levels, len(fibRatios))
+			}
+			assertFloatEqual(t, "0.618", levels["0.618"], tt.want618)
+			assertFloatEqual(t, "0.5", levels["0.5"], tt.want500)
+		})
+	}
+
+	levels := calculateFibLevels(closeKlines(0, stepCloses(5, 100, 25)...), 5)
+	if got := fibNote(levels, "%.1f"); !strings.Contains(got, "0.5=150.0, 0.618=138.2") {
+		t.Errorf("fibNote = %q, want 按比例顺序输出", got)
+	}
+}
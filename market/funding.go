@@ -0,0 +1,117 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// 资金费率趋势标签
+const (
+	FundingRising  = "rising"
+	FundingFalling = "falling"
+	FundingFlat    = "flat"
+)
+
+// FundingData 资金费率及溢价指数（premiumIndex）数据
+type FundingData struct {
+	Rate            float64   `json:"rate"`              // 最近一次资金费率
+	MarkPrice       float64   `json:"mark_price"`        // 标记价格
+	IndexPrice      float64   `json:"index_price"`       // 指数价格
+	NextFundingTime Timestamp `json:"next_funding_time"` // 下次资金费结算时间
+}
+
+// Basis 基差 = 标记价格 - 指数价格，偏离较大时提示合约与现货出现错位
+func (f *FundingData) Basis() float64 {
+	return f.MarkPrice - f.IndexPrice
+}
+
+// BasisPercent 基差占指数价格的百分比，指数价格为0时返回0
+func (f *FundingData) BasisPercent() float64 {
+	if f.IndexPrice == 0 {
+		return 0
+	}
+	return f.Basis() / f.IndexPrice * 100
+}
+
+// TimeUntilNextFunding 距下次资金费结算的剩余时间，结算时间未知或已过时返回0
+func (f *FundingData) TimeUntilNextFunding(now time.Time) time.Duration {
+	if f.NextFundingTime.IsZero() || !f.NextFundingTime.After(now) {
+		return 0
+	}
+	return f.NextFundingTime.Sub(now)
+}
+
+// fundingCountdown 将距下次结算的剩余时间格式化为 "3h12m"(不足1小时为 "12m"，不足1分钟为 "<1m")，
+// 结算时间未知或已过时返回空字符串
+func fundingCountdown(f *FundingData, now time.Time) string {
+	if f == nil {
+		return ""
+	}
+	remaining := f.TimeUntilNextFunding(now)
+	switch {
+	case remaining <= 0:
+		return ""
+	case remaining < time.Minute:
+		return "<1m"
+	case remaining < time.Hour:
+		return fmt.Sprintf("%dm", int(remaining/time.Minute))
+	default:
+		return fmt.Sprintf("%dh%dm", int(remaining/time.Hour), int(remaining%time.Hour/time.Minute))
+	}
+}
+
+// FundingHistoryLimit 获取的历史资金费率条数（Binance 通常每8小时结算一次，30条约10天）
+var FundingHistoryLimit = 30
+
+// FundingTrendThreshold 判定资金费率趋势的最小变化幅度（绝对费率，0.00001 即 0.001%）
+var FundingTrendThreshold = 0.00001
+
+// getFundingRateHistory 获取历史资金费率，按结算时间升序
+func getFundingRateHistory(ctx context.Context, symbol string, limit int) ([]float64, error) {
+	url := fmt.Sprintf("%s/fapi/v1/fundingRate?symbol=%s&limit=%d", BaseURL, symbol, limit)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Symbol      string `json:"symbol"`
+		FundingRate string `json:"fundingRate"`
+		FundingTime int64  `json:"fundingTime"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	history := make([]float64, 0, len(result))
+	for _, item := range result {
+		rate, err := strconv.ParseFloat(item.FundingRate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse fundingRate failed: %w", err)
+		}
+		history = append(history, rate)
+	}
+	return history, nil
+}
+
+// classifyFundingTrend 比较历史后半段与前半段的平均费率判断趋势
+// 差值超过 FundingTrendThreshold 为 rising/falling，否则（或历史少于2条）为 flat
+func classifyFundingTrend(history []float64) string {
+	if len(history) < 2 {
+		return FundingFlat
+	}
+	mid := len(history) / 2
+	delta := meanOf(history[mid:]) - meanOf(history[:mid])
+	switch {
+	case delta > FundingTrendThreshold:
+		return FundingRising
+	case delta < -FundingTrendThreshold:
+		return FundingFalling
+	default:
+		return FundingFlat
+	}
+}
@@ -0,0 +1,155 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetFundingRateHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []float64
+		wantErr bool
+	}{
+		{"按结算时间升序", `[` +
+			`{"symbol":"BTCUSDT","fundingRate":"0.0001","fundingTime":1700000000000},` +
+			`{"symbol":"BTCUSDT","fundingRate":"-0.00005","fundingTime":1700028800000},` +
+			`{"symbol":"BTCUSDT","fundingRate":"0.0003","fundingTime":1700057600000}]`,
+			[]float64{0.0001, -0.00005, 0.0003}, false},
+		{"空数组", `[]`, []float64{}, false},
+		{"费率格式错误", `[{"symbol":"BTCUSDT","fundingRate":"x","fundingTime":1}]`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/fundingRate": func(w http.ResponseWriter, r *http.Request) {
+					if got := r.URL.Query().Get("limit"); got != "3" {
+						t.Errorf("limit = %q, want 3", got)
+					}
+					jsonHandler(tt.body)(w, r)
+				},
+			})
+
+			got, err := getFundingRateHistory(context.Background(), "BTCUSDT", 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assertSeriesEqual(t, "history", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyFundingTrend(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []float64
+		want    string
+	}{
+		{"上升", []float64{0.0001, 0.0001, 0.0003, 0.0004}, FundingRising},
+		{"下降", []float64{0.0004, 0.0003, 0.0001, -0.0001}, FundingFalling},
+		{"变化低于阈值", []float64{0.0001, 0.0001, 0.000105, 0.000105}, FundingFlat},
+		{"单条", []float64{0.001}, FundingFlat},
+		{"为空", nil, FundingFlat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyFundingTrend(tt.history); got != tt.want {
+				t.Errorf("classifyFundingTrend(%v) = %q, want %q", tt.history, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFundingRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		want      FundingData
+		wantBasis float64
+		wantErr   bool
+	}{
+		{"解析标记价格与指数价格",
+			`{"symbol":"BTCUSDT","markPrice":"50010.5","indexPrice":"50000","lastFundingRate":"0.0001",` +
+				`"nextFundingTime":1700006400000,"interestRate":"0.0001","time":1700000000000}`,
+			FundingData{Rate: 0.0001, MarkPrice: 50010.5, IndexPrice: 50000, NextFundingTime: Timestamp{time.UnixMilli(1700006400000)}},
+			10.5, false},
+		{"费率为空",
+			`{"symbol":"BTCUSDT_240628","markPrice":"99","indexPrice":"100","lastFundingRate":"","nextFundingTime":0}`,
+			FundingData{}, 0, true},
+		{"交割合约费率为0",
+			`{"symbol":"BTCUSDT_240628","markPrice":"99","indexPrice":"100","lastFundingRate":"0","nextFundingTime":0}`,
+			FundingData{MarkPrice: 99, IndexPrice: 100}, -1, false},
+		{"指数价格格式错误",
+			`{"symbol":"BTCUSDT","markPrice":"1","indexPrice":"abc","lastFundingRate":"0.0001"}`,
+			FundingData{}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/premiumIndex": jsonHandler(tt.body),
+			})
+
+			got, err := getFundingRate(context.Background(), "BTCUSDT")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assertFloatEqual(t, "Rate", got.Rate, tt.want.Rate)
+			assertFloatEqual(t, "MarkPrice", got.MarkPrice, tt.want.MarkPrice)
+			assertFloatEqual(t, "IndexPrice", got.IndexPrice, tt.want.IndexPrice)
+			assertFloatEqual(t, "Basis", got.Basis(), tt.wantBasis)
+			if !got.NextFundingTime.Equal(tt.want.NextFundingTime.Time) {
+				t.Errorf("NextFundingTime = %v, want %v", got.NextFundingTime, tt.want.NextFundingTime)
+			}
+		})
+	}
+}
+
+func TestFundingCountdown(t *testing.T) {
+	frozen := time.Date(2024, 1, 1, 4, 47, 30, 0, time.UTC)
+	tests := []struct {
+		name    string
+		next    time.Time
+		want    string
+		wantDur time.Duration
+	}{
+		{"超过1小时", frozen.Add(3*time.Hour + 12*time.Minute + 30*time.Second), "3h12m", 3*time.Hour + 12*time.Minute + 30*time.Second},
+		{"不足1小时", frozen.Add(12 * time.Minute), "12m", 12 * time.Minute},
+		{"不足1分钟", frozen.Add(30 * time.Second), "<1m", 30 * time.Second},
+		{"已过结算时间", frozen.Add(-time.Minute), "", 0},
+		{"结算时间未知", time.Time{}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &FundingData{NextFundingTime: Timestamp{tt.next}}
+			if got := f.TimeUntilNextFunding(frozen); got != tt.wantDur {
+				t.Errorf("TimeUntilNextFunding = %v, want %v", got, tt.wantDur)
+			}
+			if got := fundingCountdown(f, frozen); got != tt.want {
+				t.Errorf("fundingCountdown = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := fundingCountdown(nil, frozen); got != "" {
+		t.Errorf("fundingCountdown(nil) = %q, want 空", got)
+	}
+
+	// Format 使用 now 计算倒计时
+	useFakeClock(t, frozen)
+	data := &Data{
+		Symbol:       "BTCUSDT",
+		CurrentPrice: 100,
+		Funding:      &FundingData{MarkPrice: 100, IndexPrice: 100, NextFundingTime: Timestamp{frozen.Add(3*time.Hour + 12*time.Minute)}},
+	}
+	if out := Format(data); !strings.Contains(out, "下次资金费用: 3h12m后") {
+		t.Errorf("输出缺少资金费倒计时:\n%s", out)
+	}
+}
@@ -0,0 +1,26 @@
+package market
+
+import "math"
+
+// ToHeikinAshi 将原始K线转换为平均K线(Heikin-Ashi)，返回新切片，不修改入参
+// 收盘 = (开+高+低+收)/4；开盘 = (上一根HA开盘 + 上一根HA收盘)/2，首根取原始 (开+收)/2；
+// 最高/最低取原始最高/最低与HA开盘、收盘中的极值。时间与成交量等字段保持不变
+func ToHeikinAshi(klines []Kline) []Kline {
+	if len(klines) == 0 {
+		return nil
+	}
+	result := make([]Kline, len(klines))
+	copy(result, klines)
+	for i, k := range klines {
+		haClose := (k.Open + k.High + k.Low + k.Close) / 4
+		haOpen := (k.Open + k.Close) / 2
+		if i > 0 {
+			haOpen = (result[i-1].Open + result[i-1].Close) / 2
+		}
+		result[i].Open = haOpen
+		result[i].Close = haClose
+		result[i].High = math.Max(k.High, math.Max(haOpen, haClose))
+		result[i].Low = math.Min(k.Low, math.Min(haOpen, haClose))
+	}
+	return result
+}
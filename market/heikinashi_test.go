@@ -0,0 +1,36 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToHeikinAshi(t *testing.T) {
+	raw := []Kline{
+		{OpenTime: 0, Open: 10, High: 12, Low: 9, Close: 11, Volume: 1},
+		{OpenTime: 60000, Open: 11, High: 14, Low: 10, Close: 13, Volume: 5},
+		{OpenTime: 120000, Open: 13, High: 13.5, Low: 8, Close: 9, Volume: 2},
+	}
+	input := append([]Kline(nil), raw...)
+
+	// 收盘 = OHLC 均值；开盘 = 上一根HA (开+收)/2，首根为原始 (开+收)/2
+	want := []Kline{
+		{OpenTime: 0, Open: 10.5, High: 12, Low: 9, Close: 10.5, Volume: 1},
+		{OpenTime: 60000, Open: 10.5, High: 14, Low: 10, Close: 12, Volume: 5},
+		{OpenTime: 120000, Open: 11.25, High: 13.5, Low: 8, Close: 10.875, Volume: 2},
+	}
+	if got := ToHeikinAshi(input); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToHeikinAshi =\n%+v\nwant\n%+v", got, want)
+	}
+	if !reflect.DeepEqual(input, raw) {
+		t.Error("ToHeikinAshi 修改了入参")
+	}
+
+	// HA 开盘高于原始最高价时最高价取HA开盘
+	gap := ToHeikinAshi([]Kline{{Open: 20, High: 21, Low: 19, Close: 20}, {Open: 10, High: 11, Low: 9, Close: 10}})
+	assertFloatEqual(t, "跳空后最高价", gap[1].High, 20)
+
+	if got := ToHeikinAshi(nil); got != nil {
+		t.Errorf("ToHeikinAshi(nil) = %v, want nil", got)
+	}
+}
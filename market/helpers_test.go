@@ -0,0 +1,187 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeSource 返回固定K线并统计调用次数的 KlineSource
+type fakeSource struct {
+	klines []Kline
+	delay  time.Duration
+	calls  atomic.Int32
+}
+
+func (s *fakeSource) GetCurrentKlines(symbol, interval string) ([]Kline, error) {
+	s.calls.Add(1)
+	if s.delay > 0 {
+		time.Sleep(s.delay)
+	}
+	return append([]Kline(nil), s.klines...), nil
+}
+
+// testKlines 生成 n 根从0时刻开始、间隔为 interval 的波动上涨K线
+func testKlines(n int, interval time.Duration) []Kline {
+	step := interval.Milliseconds()
+	klines := make([]Kline, n)
+	for i := range klines {
+		price := 100 + float64(i)*0.5 + float64(i%5)
+		klines[i] = Kline{
+			OpenTime:  int64(i) * step,
+			CloseTime: int64(i+1)*step - 1,
+			Open:      price - 0.5,
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price,
+			Volume:    10 + float64(i%3),
+		}
+	}
+	return klines
+}
+
+// testExchangeInfo 仅包含 BTCUSDT/ETHUSDT(TRADING) 与 OLDUSDT(SETTLING) 的 exchangeInfo 响应
+const testExchangeInfo = `{"symbols":[` +
+	`{"symbol":"BTCUSDT","status":"TRADING","pricePrecision":2},` +
+	`{"symbol":"ETHUSDT","status":"TRADING","pricePrecision":2},` +
+	`{"symbol":"OLDUSDT","status":"SETTLING","pricePrecision":2}]}`
+
+// useTestServer 启动 httptest 服务并将 BaseURL 指向它，关闭重试与限速、清空交易对缓存；
+// 测试结束时恢复全部全局配置。未匹配的路径返回 404
+func useTestServer(t *testing.T, routes map[string]http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := routes[r.URL.Path]; ok {
+			h(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+
+	prevBase, prevRetries, prevDelay := BaseURL, MaxRetries, retryBaseDelay
+	BaseURL, MaxRetries, retryBaseDelay = srv.URL, 0, time.Millisecond
+	useRequestRate(t, 0, 1)
+	resetPrecisionCache()
+	t.Cleanup(func() {
+		srv.Close()
+		BaseURL, MaxRetries, retryBaseDelay = prevBase, prevRetries, prevDelay
+		resetPrecisionCache()
+	})
+	return srv
+}
+
+// useRequestRate 临时设置 REST 限速，测试结束时恢复
+func useRequestRate(t *testing.T, rps float64, burst int) {
+	t.Helper()
+	prevLimit, prevBurst := limiter().Limit(), limiter().Burst()
+	SetRequestRate(rps, burst)
+	t.Cleanup(func() { SetRequestRate(float64(prevLimit), prevBurst) })
+}
+
+// useTestMonitor 将 WSMonitorCli 替换为未连接的监控器：缓存未命中时经 REST(BaseURL) 获取K线，
+// 测试结束时关闭并恢复
+func useTestMonitor(t *testing.T) *WSMonitor {
+	t.Helper()
+	prev := WSMonitorCli
+	m := NewWSMonitor(1)
+	t.Cleanup(func() {
+		m.Close()
+		WSMonitorCli = prev
+	})
+	return m
+}
+
+// klinesHandler 按请求的 interval 返回 n 根 Binance REST 格式的K线
+func klinesHandler(n int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		interval, ok := intervalDuration(r.URL.Query().Get("interval"))
+		if !ok {
+			http.Error(w, `{"code":-1120,"msg":"Invalid interval."}`, http.StatusBadRequest)
+			return
+		}
+		rows := make([][]interface{}, 0, n)
+		for _, k := range testKlines(n, interval) {
+			f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+			rows = append(rows, []interface{}{
+				k.OpenTime, f(k.Open), f(k.High), f(k.Low), f(k.Close), f(k.Volume),
+				k.CloseTime, f(k.Close * k.Volume), 10, f(k.Volume / 2), f(k.Close * k.Volume / 2), "0",
+			})
+		}
+		json.NewEncoder(w).Encode(rows)
+	}
+}
+
+// jsonHandler 固定返回 body 的处理函数
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}
+}
+
+// resetPrecisionCache 清空交易对精度/状态缓存及查询失败冷却
+func resetPrecisionCache() {
+	precisionCache.Range(func(key, _ interface{}) bool {
+		precisionCache.Delete(key)
+		return true
+	})
+	precisionMu.Lock()
+	precisionErr, precisionErrAt = nil, time.Time{}
+	precisionMu.Unlock()
+}
+
+// countingHandler 统计请求次数后委托给 next
+func countingHandler(count *atomic.Int32, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		next(w, r)
+	}
+}
+
+// recordingLogger 记录 Warnf 输出的 Logger
+type recordingLogger struct {
+	mu    sync.Mutex
+	warns []string
+}
+
+func (l *recordingLogger) Debugf(string, ...interface{}) {}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, fmt.Sprintf(format, args...))
+}
+
+// fakeClock 可手动推进的固定时钟，并发安全
+type fakeClock struct {
+	mu      sync.Mutex
+	current time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+// Advance 将时钟向前推进 d
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = c.current.Add(d)
+}
+
+// useFakeClock 将包内时钟 now 替换为停在 start 的 fakeClock，测试结束时恢复
+func useFakeClock(t *testing.T, start time.Time) *fakeClock {
+	t.Helper()
+	clock := &fakeClock{current: start}
+	prev := now
+	now = clock.Now
+	t.Cleanup(func() { now = prev })
+	return clock
+}
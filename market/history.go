@@ -0,0 +1,39 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetAt 获取指定代币在 endTime 时刻的市场数据，指标计算与 Get 完全一致，用于回测
+// 各周期只使用在 endTime 之前已收盘的K线；OI、资金费率等合约数据无法按时间回溯，保持为空
+func GetAt(ctx context.Context, symbol string, endTime time.Time) (*Data, error) {
+	if endTime.IsZero() {
+		return nil, fmt.Errorf("未指定历史数据的结束时间")
+	}
+	opts := DefaultOptions()
+	opts.EndTime = endTime
+	return GetWithOptions(ctx, symbol, opts)
+}
+
+// fetchKlinesAt 通过 REST klines 接口获取截至 endTime 的最近 limit 根已收盘K线
+// 接口按开盘时间筛选，会返回 endTime 时尚未收盘的那根K线的最终数据，因此多取一根并丢弃未收盘的K线
+func fetchKlinesAt(ctx context.Context, market Market, symbol, interval string, limit int, endTime time.Time) ([]Kline, error) {
+	endMs := endTime.UnixMilli()
+	url := fmt.Sprintf("%s/fapi/v1/klines?symbol=%s&interval=%s&limit=%d&endTime=%d", BaseURL, symbol, interval, limit+1, endMs)
+	if market == Spot {
+		url = fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&limit=%d&endTime=%d", SpotBaseURL, symbol, interval, limit+1, endMs)
+	}
+	klines, err := fetchRESTKlines(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	for len(klines) > 0 && klines[len(klines)-1].CloseTime > endMs {
+		klines = klines[:len(klines)-1]
+	}
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
@@ -0,0 +1,76 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// historicalKlinesHandler 按 Binance 语义返回开盘时间不晚于 endTime 的最近 limit 根K线（含尚未收盘的一根）
+func historicalKlinesHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		interval, ok := intervalDuration(q.Get("interval"))
+		endMs, err1 := strconv.ParseInt(q.Get("endTime"), 10, 64)
+		limit, err2 := strconv.Atoi(q.Get("limit"))
+		if !ok || err1 != nil || err2 != nil {
+			t.Errorf("历史K线请求参数无效: %s", r.URL.RawQuery)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		count := int(endMs/interval.Milliseconds()) + 1
+		klines := testKlines(count, interval)
+		if len(klines) > limit {
+			klines = klines[len(klines)-limit:]
+		}
+		f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+		rows := make([][]interface{}, 0, len(klines))
+		for _, k := range klines {
+			rows = append(rows, []interface{}{k.OpenTime, f(k.Open), f(k.High), f(k.Low), f(k.Close), f(k.Volume),
+				k.CloseTime, "0", 0, "0", "0", "0"})
+		}
+		json.NewEncoder(w).Encode(rows)
+	}
+}
+
+func TestGetAt(t *testing.T) {
+	var other atomic.Int32
+	notCalled := countingHandler(&other, func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) })
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/klines":       historicalKlinesHandler(t),
+		"/fapi/v1/exchangeInfo": notCalled,
+		"/fapi/v1/openInterest": notCalled,
+		"/fapi/v1/premiumIndex": notCalled,
+	})
+
+	step := 3 * time.Minute
+	endTime := time.UnixMilli(0).Add(120*24*time.Hour + time.Minute) // 位于一根3m K线中间
+	data, err := GetAt(context.Background(), "BTCUSDT", endTime)
+	if err != nil {
+		t.Fatalf("GetAt: %v", err)
+	}
+
+	// 最后一根(endTime 时未收盘)被丢弃，当前价格为上一根已收盘K线的收盘价
+	lastClosed := int(endTime.UnixMilli()/step.Milliseconds()) - 1
+	want := testKlines(lastClosed+1, step)[lastClosed]
+	if data.CurrentPrice != want.Close {
+		t.Errorf("CurrentPrice = %v, want %v", data.CurrentPrice, want.Close)
+	}
+	klines3m := data.Klines["3m"]
+	if len(klines3m) != defaultKlineLimit || klines3m[len(klines3m)-1].CloseTime > endTime.UnixMilli() {
+		t.Errorf("3m K线 %d 根，最后收盘时间 %d，want %d 根且不晚于 %d",
+			len(klines3m), klines3m[len(klines3m)-1].CloseTime, defaultKlineLimit, endTime.UnixMilli())
+	}
+	// 历史模式不做交易对预检，也不获取无法回溯的合约数据
+	if n := other.Load(); n != 0 || data.OpenInterest != nil || data.Funding != nil {
+		t.Errorf("历史模式请求了合约接口 %d 次，OI=%v Funding=%v", n, data.OpenInterest, data.Funding)
+	}
+
+	if _, err := GetAt(context.Background(), "BTCUSDT", time.Time{}); err == nil {
+		t.Error("未指定结束时间应返回错误")
+	}
+}
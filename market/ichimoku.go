@@ -0,0 +1,83 @@
+package market
+
+import (
+	"fmt"
+	"math"
+)
+
+// 一目均衡表标准参数
+const (
+	ichimokuTenkanPeriod  = 9
+	ichimokuKijunPeriod   = 26
+	ichimokuSenkouBPeriod = 52
+	ichimokuDisplacement  = 26
+)
+
+// 价格相对云层的位置
+const (
+	CloudAbove  = "above"
+	CloudBelow  = "below"
+	CloudInside = "inside"
+)
+
+// Ichimoku 一目均衡表(9,26,52)
+type Ichimoku struct {
+	Tenkan  float64 `json:"tenkan"`   // 转换线：最近9根K线最高价与最低价的中值
+	Kijun   float64 `json:"kijun"`    // 基准线：最近26根K线最高价与最低价的中值
+	SenkouA float64 `json:"senkou_a"` // 先行带A：(转换线+基准线)/2，前移26根绘制
+	SenkouB float64 `json:"senkou_b"` // 先行带B：最近52根K线最高价与最低价的中值，前移26根绘制
+	Chikou  float64 `json:"chikou"`   // 迟行线：最新收盘价，后移26根绘制
+
+	// 当前K线所在位置的云层（26根前计算的先行带A/B），K线不足78根时为0
+	CloudTop    float64 `json:"cloud_top"`
+	CloudBottom float64 `json:"cloud_bottom"`
+	// 最新收盘价相对当前云层的位置: above/below/inside，云层不可用时为空
+	Position string `json:"position"`
+}
+
+// calculateIchimoku 按标准参数 9/26/52 计算一目均衡表，K线不足52根时返回错误
+func calculateIchimoku(klines []Kline) (*Ichimoku, error) {
+	if len(klines) < ichimokuSenkouBPeriod {
+		return nil, fmt.Errorf("K线数量不足: 一目均衡表需要至少%d根，当前%d根", ichimokuSenkouBPeriod, len(klines))
+	}
+
+	last := len(klines) - 1
+	tenkan := midpointAt(klines, last, ichimokuTenkanPeriod)
+	kijun := midpointAt(klines, last, ichimokuKijunPeriod)
+	ichimoku := &Ichimoku{
+		Tenkan:  tenkan,
+		Kijun:   kijun,
+		SenkouA: (tenkan + kijun) / 2,
+		SenkouB: midpointAt(klines, last, ichimokuSenkouBPeriod),
+		Chikou:  klines[last].Close,
+	}
+
+	// 当前云层由 ichimokuDisplacement 根K线之前的先行带构成
+	origin := last - ichimokuDisplacement
+	if origin >= ichimokuSenkouBPeriod-1 {
+		senkouA := (midpointAt(klines, origin, ichimokuTenkanPeriod) + midpointAt(klines, origin, ichimokuKijunPeriod)) / 2
+		senkouB := midpointAt(klines, origin, ichimokuSenkouBPeriod)
+		ichimoku.CloudTop = math.Max(senkouA, senkouB)
+		ichimoku.CloudBottom = math.Min(senkouA, senkouB)
+		switch price := klines[last].Close; {
+		case price > ichimoku.CloudTop:
+			ichimoku.Position = CloudAbove
+		case price < ichimoku.CloudBottom:
+			ichimoku.Position = CloudBelow
+		default:
+			ichimoku.Position = CloudInside
+		}
+	}
+	return ichimoku, nil
+}
+
+// midpointAt 以下标 end 结尾的 period 根K线最高价与最低价的中值，调用方保证 end+1 >= period
+func midpointAt(klines []Kline, end, period int) float64 {
+	window := klines[end+1-period : end+1]
+	highest, lowest := window[0].High, window[0].Low
+	for _, k := range window {
+		highest = math.Max(highest, k.High)
+		lowest = math.Min(lowest, k.Low)
+	}
+	return (highest + lowest) / 2
+}
@@ -0,0 +1,45 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCalculateIchimoku(t *testing.T) {
+	// 收盘价 100+i、最高/最低价为收盘价±1 的单边上涨：窗口中值可手工推算
+	// 最后一根 i=79：转换线 (180+170)/2=175，基准线 (180+153)/2=166.5，先行带B (180+127)/2=153.5
+	// 当前云层来自 i=53：转换线149、基准线140.5 → 先行带A 144.75；先行带B (154+101)/2=127.5
+	tests := []struct {
+		name    string
+		n       int
+		want    *Ichimoku
+		wantErr bool
+	}{
+		{"完整云层", 80, &Ichimoku{
+			Tenkan: 175, Kijun: 166.5, SenkouA: 170.75, SenkouB: 153.5, Chikou: 179,
+			CloudTop: 144.75, CloudBottom: 127.5, Position: CloudAbove,
+		}, false},
+		// i=59：转换线 (160+150)/2=155，基准线 (160+133)/2=146.5，先行带B (160+107)/2=133.5
+		{"云层K线不足", 60, &Ichimoku{
+			Tenkan: 155, Kijun: 146.5, SenkouA: 150.75, SenkouB: 133.5, Chikou: 159,
+		}, false},
+		{"K线不足52根", 51, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateIchimoku(closeKlines(1, stepCloses(tt.n, 100, 1)...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("calculateIchimoku =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	// 急跌至云层下方
+	closes := append(stepCloses(79, 100, 1), 100)
+	if got, _ := calculateIchimoku(closeKlines(1, closes...)); got.Position != CloudBelow {
+		t.Errorf("Position = %q, want %q", got.Position, CloudBelow)
+	}
+}
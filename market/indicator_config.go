@@ -0,0 +1,182 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+)
+
+// defaultKlineLimit 每个周期获取/缓存的K线数量（REST 初始化与 WebSocket 缓存均为100根）
+const defaultKlineLimit = 100
+
+// IndicatorConfig 指标周期配置
+// EMAPeriod/MACD*/RSIPeriod 控制 Data 中基于3分钟K线计算的当前指标（CurrentEMA20/CurrentMACD/CurrentRSI7），
+// Intraday/LongerTerm 控制各周期 IntradayData 与 4h/1d LongerTermData 中的 ATR/RSI/MACD 序列。
+// 字段名沿用默认周期，配置其他周期时这些字段保存的是对应周期的值
+type IndicatorConfig struct {
+	EMAPeriod  int // 默认20
+	MACDShort  int // 默认12
+	MACDLong   int // 默认26
+	MACDSignal int // 默认9
+	RSIPeriod  int // 默认7
+
+	Intraday   IntradayPeriods
+	LongerTerm LongerTermPeriods
+}
+
+// MACDPeriods MACD 快线/慢线/信号线周期
+type MACDPeriods struct {
+	Short  int
+	Long   int
+	Signal int
+}
+
+// IntradayPeriods 日内序列指标周期（3m/15m/1h 等所有周期的 IntradayData 共用）
+// EMA 对应 EMA20Values(为0时取 IndicatorConfig.EMAPeriod)，
+// 其余依次对应 ATR6/ATR10/ATR12/ATR14、RSI7Values/RSI9Values/RSI10Values/RSI14Values、
+// MACDValues10208/MACDValues12269(及对应的 DEA/Hist) 字段
+type IntradayPeriods struct {
+	EMA  int
+	ATR  [4]int
+	RSI  [4]int
+	MACD [2]MACDPeriods
+}
+
+// LongerTermPeriods 4h/1d 长期指标周期
+// 依次对应 ATR3/ATR10/ATR12/ATR14、RSI14Values/RSI21Values、
+// MACDValues142810/MACDValues12269(及对应的 DEA/Hist) 字段
+type LongerTermPeriods struct {
+	ATR  [4]int
+	RSI  [2]int
+	MACD [2]MACDPeriods
+}
+
+// DefaultIndicatorConfig 返回与 Get 一致的默认指标配置
+func DefaultIndicatorConfig() IndicatorConfig {
+	return IndicatorConfig{
+		EMAPeriod:  20,
+		MACDShort:  12,
+		MACDLong:   26,
+		MACDSignal: 9,
+		RSIPeriod:  7,
+		Intraday: IntradayPeriods{
+			EMA:  20,
+			ATR:  [4]int{6, 10, 12, 14},
+			RSI:  [4]int{7, 9, 10, 14},
+			MACD: [2]MACDPeriods{{10, 20, 8}, {12, 26, 9}},
+		},
+		LongerTerm: LongerTermPeriods{
+			ATR:  [4]int{3, 10, 12, 14},
+			RSI:  [2]int{14, 21},
+			MACD: [2]MACDPeriods{{14, 28, 10}, {12, 26, 9}},
+		},
+	}
+}
+
+// withDefaults 返回补全后的配置：Intraday/LongerTerm 未设置(零值)时使用默认周期，
+// Intraday.EMA 未设置时跟随 EMAPeriod，兼容只设置当前指标周期的旧配置
+func (c IndicatorConfig) withDefaults() IndicatorConfig {
+	defaults := DefaultIndicatorConfig()
+	if ema := c.Intraday.EMA; c.Intraday == (IntradayPeriods{EMA: ema}) {
+		c.Intraday = defaults.Intraday
+		c.Intraday.EMA = ema
+	}
+	if c.Intraday.EMA == 0 {
+		c.Intraday.EMA = c.EMAPeriod
+	}
+	if c.LongerTerm == (LongerTermPeriods{}) {
+		c.LongerTerm = defaults.LongerTerm
+	}
+	return c
+}
+
+// Validate 检查配置是否合理，在发起任何网络请求前发现错误配置
+// 规则：所有周期为正；MACD 短周期小于长周期；信号线周期不超过长周期；
+// 各指标所需K线数不超过每个周期默认获取的K线数量(defaultKlineLimit)。
+// 通过 Options.Indicators 使用时按 Options 实际获取的K线数量检查
+func (c *IndicatorConfig) Validate() error {
+	return c.validate(defaultKlineLimit)
+}
+
+// validate 按每个周期可用的K线数量 limit 检查配置
+func (c *IndicatorConfig) validate(limit int) error {
+	var errs []error
+	for _, p := range []struct {
+		name  string
+		value int
+	}{
+		{"EMAPeriod", c.EMAPeriod},
+		{"MACDShort", c.MACDShort},
+		{"MACDLong", c.MACDLong},
+		{"MACDSignal", c.MACDSignal},
+		{"RSIPeriod", c.RSIPeriod},
+	} {
+		if p.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s 必须为正数，当前为 %d", p.name, p.value))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if c.MACDShort >= c.MACDLong {
+		errs = append(errs, fmt.Errorf("MACD 短周期(%d)必须小于长周期(%d)", c.MACDShort, c.MACDLong))
+	}
+	if c.MACDSignal > c.MACDLong {
+		errs = append(errs, fmt.Errorf("MACD 信号线周期(%d)不应超过长周期(%d)", c.MACDSignal, c.MACDLong))
+	}
+	if c.EMAPeriod > limit {
+		errs = append(errs, fmt.Errorf("EMA 周期(%d)超过可用K线数量(%d)", c.EMAPeriod, limit))
+	}
+	if c.RSIPeriod >= limit {
+		errs = append(errs, fmt.Errorf("RSI 周期(%d)需要至少 %d 根K线，超过可用数量(%d)", c.RSIPeriod, c.RSIPeriod+1, limit))
+	}
+	if need := c.MACDLong + c.MACDSignal - 1; need > limit {
+		errs = append(errs, fmt.Errorf("MACD(%d,%d,%d) 需要至少 %d 根K线，超过可用数量(%d)",
+			c.MACDShort, c.MACDLong, c.MACDSignal, need, limit))
+	}
+
+	// 序列指标周期
+	if c.Intraday.EMA <= 0 || c.Intraday.EMA > limit {
+		errs = append(errs, fmt.Errorf("Intraday.EMA 必须在 1 到 %d 之间，当前为 %d", limit, c.Intraday.EMA))
+	}
+	for _, set := range []struct {
+		name    string
+		periods []int
+	}{
+		{"Intraday.ATR", c.Intraday.ATR[:]},
+		{"Intraday.RSI", c.Intraday.RSI[:]},
+		{"LongerTerm.ATR", c.LongerTerm.ATR[:]},
+		{"LongerTerm.RSI", c.LongerTerm.RSI[:]},
+	} {
+		for i, p := range set.periods {
+			if p <= 0 || p >= limit {
+				errs = append(errs, fmt.Errorf("%s[%d] 必须在 1 到 %d 之间，当前为 %d", set.name, i, limit-1, p))
+			}
+		}
+	}
+	for _, m := range [...]MACDPeriods{c.Intraday.MACD[0], c.Intraday.MACD[1], c.LongerTerm.MACD[0], c.LongerTerm.MACD[1]} {
+		if err := m.validate(limit); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// difStart 返回 DIF 序列第一个有效点的K线下标（长短周期 EMA 均形成后）
+func (m MACDPeriods) difStart() int {
+	return max(m.Short, m.Long) - 1
+}
+
+// validate 检查一组 MACD 周期：均为正、短周期小于长周期、所需K线数不超过可用数量
+func (m MACDPeriods) validate(limit int) error {
+	if m.Short <= 0 || m.Long <= 0 || m.Signal <= 0 {
+		return fmt.Errorf("MACD(%d,%d,%d) 周期必须为正数", m.Short, m.Long, m.Signal)
+	}
+	if m.Short >= m.Long {
+		return fmt.Errorf("MACD(%d,%d,%d) 短周期必须小于长周期", m.Short, m.Long, m.Signal)
+	}
+	if need := m.Long + m.Signal - 1; need > limit {
+		return fmt.Errorf("MACD(%d,%d,%d) 需要至少 %d 根K线，超过可用数量(%d)", m.Short, m.Long, m.Signal, need, limit)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCustomIndicatorPeriods(t *testing.T) {
+	klines := wavyKlines(100)
+
+	intraday := IntradayPeriods{
+		EMA:  5,
+		ATR:  [4]int{2, 3, 4, 5},
+		RSI:  [4]int{2, 3, 4, 5},
+		MACD: [2]MACDPeriods{{3, 6, 2}, {5, 10, 3}},
+	}
+	data := calculateIntradaySeries(klines, intraday)
+	assertSeriesEqual(t, "EMA20Values", data.EMA20Values, refSeries(klines, 4, func(k []Kline) float64 { return refEMA(k, 5) }))
+	assertFloatEqual(t, "ATR6", data.ATR6, refATR(klines, 2))
+	assertFloatEqual(t, "ATR14", data.ATR14, refATR(klines, 5))
+	assertSeriesEqual(t, "RSI7Values", data.RSI7Values, refSeries(klines, 2, refRSIOf(2)))
+	assertSeriesEqual(t, "RSI14Values", data.RSI14Values, refSeries(klines, 5, refRSIOf(5)))
+	assertSeriesEqual(t, "MACDValues10208", data.MACDValues10208, refSeries(klines, 5, refDIF(3, 6, 2)))
+	assertSeriesEqual(t, "MACDValues12269", data.MACDValues12269, refSeries(klines, 9, refDIF(5, 10, 3)))
+
+	longer := LongerTermPeriods{
+		ATR:  [4]int{2, 3, 4, 5},
+		RSI:  [2]int{3, 6},
+		MACD: [2]MACDPeriods{{4, 8, 3}, {6, 12, 4}},
+	}
+	lt := calculateLongerTermData(klines, longer)
+	assertFloatEqual(t, "ATR3", lt.ATR3, refATR(klines, 2))
+	assertFloatEqual(t, "ATR14", lt.ATR14, refATR(klines, 5))
+	assertSeriesEqual(t, "RSI14Values", lt.RSI14Values, refSeries(klines, 3, refRSIOf(3)))
+	assertSeriesEqual(t, "RSI21Values", lt.RSI21Values, refSeries(klines, 6, refRSIOf(6)))
+	assertSeriesEqual(t, "MACDValues142810", lt.MACDValues142810, refSeries(klines, 7, refDIF(4, 8, 3)))
+	assertSeriesEqual(t, "MACDValues12269", lt.MACDValues12269, refSeries(klines, 11, refDIF(6, 12, 4)))
+}
+
+func TestSeriesStartFollowsConfiguredPeriods(t *testing.T) {
+	intraday := IntradayPeriods{
+		EMA:  40,
+		ATR:  [4]int{6, 10, 12, 14},
+		RSI:  [4]int{7, 9, 10, 14},
+		MACD: [2]MACDPeriods{{20, 40, 9}, {12, 26, 9}},
+	}
+	longer := LongerTermPeriods{
+		ATR:  [4]int{3, 10, 12, 14},
+		RSI:  [2]int{14, 21},
+		MACD: [2]MACDPeriods{{20, 45, 9}, {12, 26, 9}},
+	}
+	// 长周期超过26时，K线不足的点不应以0输出
+	for _, n := range []int{30, 42, 48, 100} {
+		klines := wavyKlines(n)
+		data := calculateIntradaySeries(klines, intraday)
+		assertSeriesEqual(t, "EMA20Values", data.EMA20Values, refSeries(klines, 39, func(k []Kline) float64 { return refEMA(k, 40) }))
+		assertSeriesEqual(t, "MACDValues10208", data.MACDValues10208, refSeries(klines, 39, refDIF(20, 40, 9)))
+		assertSeriesEqual(t, "MACDValues12269", data.MACDValues12269, refSeries(klines, 25, refDIF(12, 26, 9)))
+		for _, v := range append(data.EMA20Values, data.MACDValues10208...) {
+			if v == 0 {
+				t.Errorf("n=%d: 序列包含未形成的0值", n)
+				break
+			}
+		}
+
+		lt := calculateLongerTermData(klines, longer)
+		assertSeriesEqual(t, "MACDValues142810", lt.MACDValues142810, refSeries(klines, 44, refDIF(20, 45, 9)))
+	}
+}
+
+func TestEMAPeriodAppliesToIntradaySeries(t *testing.T) {
+	cfg := IndicatorConfig{EMAPeriod: 50, MACDShort: 12, MACDLong: 26, MACDSignal: 9, RSIPeriod: 7}.withDefaults()
+	if cfg.Intraday.EMA != 50 {
+		t.Errorf("Intraday.EMA = %d, want 跟随 EMAPeriod(50)", cfg.Intraday.EMA)
+	}
+	if cfg.Intraday.RSI != DefaultIndicatorConfig().Intraday.RSI {
+		t.Errorf("Intraday.RSI = %v, want 默认周期", cfg.Intraday.RSI)
+	}
+}
+
+func TestIndicatorConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *IndicatorConfig)
+		wantErr string // 为空表示配置合法
+	}{
+		{"默认配置", func(c *IndicatorConfig) {}, ""},
+		{"RSI(2)", func(c *IndicatorConfig) { c.RSIPeriod = 2 }, ""},
+		{"周期为0", func(c *IndicatorConfig) { c.EMAPeriod = 0 }, "EMAPeriod 必须为正数"},
+		{"短周期不小于长周期", func(c *IndicatorConfig) { c.MACDShort = 26 }, "MACD 短周期(26)必须小于长周期(26)"},
+		{"信号线超过长周期", func(c *IndicatorConfig) { c.MACDSignal = 30 }, "信号线周期(30)不应超过长周期(26)"},
+		{"EMA 超过K线数量", func(c *IndicatorConfig) { c.EMAPeriod = 101 }, "EMA 周期(101)超过可用K线数量"},
+		{"序列RSI周期越界", func(c *IndicatorConfig) { c.Intraday.RSI[2] = 100 }, "Intraday.RSI[2] 必须在 1 到 99 之间"},
+		{"序列EMA周期越界", func(c *IndicatorConfig) { c.Intraday.EMA = 101 }, "Intraday.EMA 必须在 1 到 100 之间"},
+		{"序列MACD周期无效", func(c *IndicatorConfig) { c.LongerTerm.MACD[0] = MACDPeriods{20, 10, 5} }, "MACD(20,10,5) 短周期必须小于长周期"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultIndicatorConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want 包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetWithConfigRejectsInvalidConfig(t *testing.T) {
+	var requests atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": countingHandler(&requests, jsonHandler(testExchangeInfo)),
+		"/fapi/v1/klines":       countingHandler(&requests, klinesHandler(100)),
+	})
+
+	cfg := DefaultIndicatorConfig()
+	cfg.MACDShort, cfg.MACDLong = 30, 20
+	if _, err := GetWithConfig("BTCUSDT", cfg); err == nil || !strings.Contains(err.Error(), "指标配置无效") {
+		t.Fatalf("GetWithConfig() error = %v, want 指标配置无效", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("配置无效时仍发出 %d 次请求", n)
+	}
+}
+
+func TestGetWithOptionsIndicators(t *testing.T) {
+	var requests atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": countingHandler(&requests, jsonHandler(testExchangeInfo)),
+	})
+	klines := testKlines(100, 3*time.Minute)
+
+	// MACD(12,26,9) 需要34根K线：按默认100根合法，但 KlineLimit=30 时不足
+	cfg := IndicatorConfig{EMAPeriod: 20, MACDShort: 12, MACDLong: 26, MACDSignal: 9, RSIPeriod: 7}
+	if full := cfg.withDefaults(); full.Validate() != nil {
+		t.Fatal("默认K线数量下配置应合法")
+	}
+	opts := DefaultOptions()
+	opts.KlineLimit = 30
+	opts.Indicators = &cfg
+	opts.Source = &fakeSource{klines: klines}
+	if _, err := GetWithOptions(context.Background(), "BTCUSDT", opts); err == nil || !strings.Contains(err.Error(), "需要至少 34 根K线，超过可用数量(30)") {
+		t.Fatalf("GetWithOptions() error = %v, want 按 KlineLimit 校验失败", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("配置无效时仍发出 %d 次请求", n)
+	}
+
+	// 合法配置生效：当前EMA按 EMAPeriod 计算
+	cfg.EMAPeriod = 50
+	opts.KlineLimit = 0
+	data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+	if err != nil {
+		t.Fatalf("GetWithOptions() error = %v", err)
+	}
+	assertFloatEqual(t, "CurrentEMA20", data.CurrentEMA20, refEMA(klines, 50))
+	assertSeriesEqual(t, "EMA20Values", data.IntradaySeries.EMA20Values, refSeries(klines, 49, func(k []Kline) float64 { return refEMA(k, 50) }))
+}
+
+func TestOptionsKlineLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *Options)
+		want   int
+	}{
+		{"默认", func(o *Options) {}, 100},
+		{"未设置", func(o *Options) { o.KlineLimit = 0 }, 100},
+		{"小于缓存", func(o *Options) { o.KlineLimit = 30 }, 30},
+		{"合约超过缓存", func(o *Options) { o.KlineLimit = 200 }, 100},
+		{"现货按REST获取", func(o *Options) { o.KlineLimit, o.Market = 200, Spot }, 200},
+		{"历史模式按REST获取", func(o *Options) { o.KlineLimit, o.EndTime = 200, time.Unix(1700000000, 0) }, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.modify(&opts)
+			if got := opts.klineLimit(); got != tt.want {
+				t.Errorf("klineLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 以下为基于普通 float64 切片的公开指标函数，便于调用方对自有数据计算指标
+// 计算口径与 Get 内部一致（SMA 作为EMA初值、Wilder 平滑的 RSI/ATR），但不受 UseLogReturns 影响
+// 数据不足时返回的错误满足 errors.Is(err, ErrInsufficientData)，以区分“指标恰好为0”与“无法计算”；
+// Get/Format 内部仍使用返回0的容错版本
+
+// ErrInsufficientData 数据点数量不足以计算指标
+var ErrInsufficientData = errors.New("insufficient kline data")
+
+// insufficientData 构造带指标名称与数量信息的 ErrInsufficientData
+func insufficientData(indicator string, need, have int) error {
+	return fmt.Errorf("%w: %s 需要至少%d个数据点，当前%d个", ErrInsufficientData, indicator, need, have)
+}
+
+// EMA 计算收盘价序列的指数移动平均（最后一个点），数据不足 period 个时返回 ErrInsufficientData
+func EMA(closes []float64, period int) (float64, error) {
+	if period <= 0 {
+		return 0, fmt.Errorf("EMA 周期必须为正数，当前为 %d", period)
+	}
+	if len(closes) < period {
+		return 0, insufficientData(fmt.Sprintf("EMA(%d)", period), period, len(closes))
+	}
+	return calculateEMA(closesToKlines(closes), period), nil
+}
+
+// RSI 计算收盘价序列的相对强弱指数（最后一个点），数据不足 period+1 个时返回 ErrInsufficientData
+func RSI(closes []float64, period int) (float64, error) {
+	if period <= 0 {
+		return 0, fmt.Errorf("RSI 周期必须为正数，当前为 %d", period)
+	}
+	if len(closes) < period+1 {
+		return 0, insufficientData(fmt.Sprintf("RSI(%d)", period), period+1, len(closes))
+	}
+	return calculateRSI(closesToKlines(closes), period), nil
+}
+
+// MACD 计算收盘价序列最后一个点的 DIF(快线)、DEA(信号线) 与柱状图(DIF-DEA)
+// 信号线需要 long+signal-1 个数据点，不足时返回 ErrInsufficientData
+func MACD(closes []float64, short, long, signal int) (dif, dea, hist float64, err error) {
+	if short <= 0 || long <= 0 || signal <= 0 {
+		return 0, 0, 0, fmt.Errorf("MACD(%d,%d,%d) 周期必须为正数", short, long, signal)
+	}
+	if need := long + signal - 1; len(closes) < need {
+		return 0, 0, 0, insufficientData(fmt.Sprintf("MACD(%d,%d,%d)", short, long, signal), need, len(closes))
+	}
+	dif, dea, hist = calculateMACD(closesToKlines(closes), short, long, signal)
+	return dif, dea, hist, nil
+}
+
+// ATR 计算平均真实波幅（最后一个点），三个序列需逐点对齐且等长
+// 长度不一致时返回错误，数据不足 period+1 个时返回 ErrInsufficientData
+func ATR(highs, lows, closes []float64, period int) (float64, error) {
+	if period <= 0 {
+		return 0, fmt.Errorf("ATR 周期必须为正数，当前为 %d", period)
+	}
+	if len(highs) != len(closes) || len(lows) != len(closes) {
+		return 0, fmt.Errorf("ATR 输入序列长度不一致: highs=%d, lows=%d, closes=%d", len(highs), len(lows), len(closes))
+	}
+	if len(closes) < period+1 {
+		return 0, insufficientData(fmt.Sprintf("ATR(%d)", period), period+1, len(closes))
+	}
+	klines := make([]Kline, len(closes))
+	for i := range closes {
+		klines[i] = Kline{High: highs[i], Low: lows[i], Close: closes[i]}
+	}
+	return calculateATR(klines, period), nil
+}
+
+// closesToKlines 将收盘价序列包装为只含 Close 的K线，供内部指标函数复用
+func closesToKlines(closes []float64) []Kline {
+	klines := make([]Kline, len(closes))
+	for i, c := range closes {
+		klines[i] = Kline{Close: c}
+	}
+	return klines
+}
@@ -0,0 +1,882 @@
+package market
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// 以下 ref* 为单次遍历重构前的逐点重算实现，作为回归基准
+
+func refEMA(klines []Kline, period int) float64 {
+	if len(klines) < period {
+		return 0
+	}
+	sum := 0.0
+	for i := 0; i < period; i++ {
+		sum += klines[i].Close
+	}
+	ema := sum / float64(period)
+	multiplier := 2.0 / float64(period+1)
+	for i := period; i < len(klines); i++ {
+		ema = (klines[i].Close-ema)*multiplier + ema
+	}
+	return ema
+}
+
+func refMACD(klines []Kline, shortPeriod, longPeriod, signalPeriod int) (float64, float64, float64) {
+	total := longPeriod
+	if shortPeriod > longPeriod {
+		total = shortPeriod
+	}
+	if len(klines) < total {
+		return 0, 0, 0
+	}
+	dif := refEMA(klines, shortPeriod) - refEMA(klines, longPeriod)
+
+	var difSeries []float64
+	for i := longPeriod - 1; i < len(klines); i++ {
+		difSeries = append(difSeries, refEMA(klines[:i+1], shortPeriod)-refEMA(klines[:i+1], longPeriod))
+	}
+	if len(difSeries) < signalPeriod {
+		return dif, 0, 0
+	}
+	sum := 0.0
+	for i := 0; i < signalPeriod; i++ {
+		sum += difSeries[i]
+	}
+	dea := sum / float64(signalPeriod)
+	multiplier := 2.0 / float64(signalPeriod+1)
+	for i := signalPeriod; i < len(difSeries); i++ {
+		dea = (difSeries[i]-dea)*multiplier + dea
+	}
+	return dif, dea, dif - dea
+}
+
+func refRSI(klines []Kline, period int) float64 {
+	if len(klines) <= period {
+		return 0
+	}
+	gains, losses := 0.0, 0.0
+	for i := 1; i <= period; i++ {
+		if change := klines[i].Close - klines[i-1].Close; change > 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+	avgGain, avgLoss := gains/float64(period), losses/float64(period)
+	for i := period + 1; i < len(klines); i++ {
+		change := klines[i].Close - klines[i-1].Close
+		if change > 0 {
+			avgGain = (avgGain*float64(period-1) + change) / float64(period)
+			avgLoss = (avgLoss * float64(period-1)) / float64(period)
+		} else {
+			avgGain = (avgGain * float64(period-1)) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) - change) / float64(period)
+		}
+	}
+	if avgLoss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
+
+func refATR(klines []Kline, period int) float64 {
+	if len(klines) <= period {
+		return 0
+	}
+	trs := make([]float64, len(klines))
+	for i := 1; i < len(klines); i++ {
+		prevClose := klines[i-1].Close
+		trs[i] = math.Max(klines[i].High-klines[i].Low, math.Max(math.Abs(klines[i].High-prevClose), math.Abs(klines[i].Low-prevClose)))
+	}
+	sum := 0.0
+	for i := 1; i <= period; i++ {
+		sum += trs[i]
+	}
+	atr := sum / float64(period)
+	for i := period + 1; i < len(klines); i++ {
+		atr = (atr*float64(period-1) + trs[i]) / float64(period)
+	}
+	return atr
+}
+
+// refSeries 按原实现逐点重算最近10个点，gate 为起始下标
+func refSeries(klines []Kline, gate int, f func([]Kline) float64) []float64 {
+	start := len(klines) - 10
+	if start < 0 {
+		start = 0
+	}
+	var result []float64
+	for i := start; i < len(klines); i++ {
+		if i >= gate {
+			result = append(result, f(klines[:i+1]))
+		}
+	}
+	return result
+}
+
+func refDIF(short, long, signal int) func([]Kline) float64 {
+	return func(k []Kline) float64 {
+		dif, _, _ := refMACD(k, short, long, signal)
+		return dif
+	}
+}
+
+func refRSIOf(period int) func([]Kline) float64 {
+	return func(k []Kline) float64 { return refRSI(k, period) }
+}
+
+// wavyKlines 固定的震荡K线数据集，涨跌交替以覆盖 RSI/MACD 的正负分支
+func wavyKlines(n int) []Kline {
+	klines := testKlines(n, 3*time.Minute)
+	for i := range klines {
+		price := 100 + 10*math.Sin(float64(i)/5) + float64(i)*0.1
+		klines[i].Open = price - 0.3
+		klines[i].Close = price
+		klines[i].High = price + 0.5 + float64(i%4)*0.2
+		klines[i].Low = price - 0.6 - float64(i%3)*0.2
+	}
+	return klines
+}
+
+func assertSeriesEqual(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s 长度 = %d, want %d", name, len(got), len(want))
+		return
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("%s[%d] = %v, want %v", name, i, got[i], want[i])
+			return
+		}
+	}
+}
+
+func assertFloatEqual(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestIntradaySeriesMatchesReference(t *testing.T) {
+	p := DefaultIndicatorConfig().Intraday
+	for _, n := range []int{20, 30, 35, 40, 100} {
+		klines := wavyKlines(n)
+		data := calculateIntradaySeries(klines, p)
+
+		assertSeriesEqual(t, "EMA20Values", data.EMA20Values, refSeries(klines, 19, func(k []Kline) float64 { return refEMA(k, 20) }))
+		assertSeriesEqual(t, "MACDValues10208", data.MACDValues10208, refSeries(klines, 19, refDIF(10, 20, 8)))
+		assertSeriesEqual(t, "MACDValues12269", data.MACDValues12269, refSeries(klines, 25, refDIF(12, 26, 9)))
+		assertSeriesEqual(t, "RSI7Values", data.RSI7Values, refSeries(klines, 7, refRSIOf(7)))
+		assertSeriesEqual(t, "RSI9Values", data.RSI9Values, refSeries(klines, 9, refRSIOf(9)))
+		assertSeriesEqual(t, "RSI10Values", data.RSI10Values, refSeries(klines, 10, refRSIOf(10)))
+		assertSeriesEqual(t, "RSI14Values", data.RSI14Values, refSeries(klines, 14, refRSIOf(14)))
+		assertFloatEqual(t, "ATR6", data.ATR6, refATR(klines, 6))
+		assertFloatEqual(t, "ATR14", data.ATR14, refATR(klines, 14))
+	}
+}
+
+func TestLongerTermDataMatchesReference(t *testing.T) {
+	p := DefaultIndicatorConfig().LongerTerm
+	for _, n := range []int{20, 30, 35, 60, 100} {
+		klines := wavyKlines(n)
+		data := calculateLongerTermData(klines, p)
+
+		assertFloatEqual(t, "EMA20", data.EMA20, refEMA(klines, 20))
+		assertFloatEqual(t, "EMA50", data.EMA50, refEMA(klines, 50))
+		assertFloatEqual(t, "ATR3", data.ATR3, refATR(klines, 3))
+		assertFloatEqual(t, "ATR14", data.ATR14, refATR(klines, 14))
+		assertSeriesEqual(t, "MACDValues142810", data.MACDValues142810, refSeries(klines, 27, refDIF(14, 28, 10)))
+		assertSeriesEqual(t, "MACDValues12269", data.MACDValues12269, refSeries(klines, 25, refDIF(12, 26, 9)))
+		assertSeriesEqual(t, "RSI14Values", data.RSI14Values, refSeries(klines, 14, refRSIOf(14)))
+		assertSeriesEqual(t, "RSI21Values", data.RSI21Values, refSeries(klines, 21, refRSIOf(21)))
+	}
+}
+
+func BenchmarkCalculateIntradaySeries(b *testing.B) {
+	klines := wavyKlines(defaultKlineLimit)
+	p := DefaultIndicatorConfig().Intraday
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		calculateIntradaySeries(klines, p)
+	}
+}
+
+// BenchmarkIntradaySeriesReference 原逐点重算实现的 EMA/MACD/RSI 序列部分，用于对比
+func BenchmarkIntradaySeriesReference(b *testing.B) {
+	klines := wavyKlines(defaultKlineLimit)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		refSeries(klines, 19, func(k []Kline) float64 { return refEMA(k, 20) })
+		refSeries(klines, 19, refDIF(10, 20, 8))
+		refSeries(klines, 25, refDIF(12, 26, 9))
+		for _, period := range []int{7, 9, 10, 14} {
+			refSeries(klines, period, refRSIOf(period))
+		}
+	}
+}
+
+func TestIntradayMACDSignalStartsWhenFormed(t *testing.T) {
+	p := DefaultIndicatorConfig().Intraday
+	tests := []struct {
+		n       int
+		wantDEA int // 12/26/9 的信号线从下标 26-1+9-1=33 开始
+		wantDIF int
+	}{
+		{30, 0, 5},
+		{35, 2, 10},
+		{100, 10, 10},
+	}
+	for _, tt := range tests {
+		klines := wavyKlines(tt.n)
+		data := calculateIntradaySeries(klines, p)
+		if len(data.MACDValues12269) != tt.wantDIF || len(data.MACDDEA12269) != tt.wantDEA || len(data.MACDHist12269) != tt.wantDEA {
+			t.Errorf("n=%d: DIF/DEA/Hist 长度 = %d/%d/%d, want %d/%d/%d", tt.n,
+				len(data.MACDValues12269), len(data.MACDDEA12269), len(data.MACDHist12269), tt.wantDIF, tt.wantDEA, tt.wantDEA)
+			continue
+		}
+		// DEA/柱状图与 DIF 末尾对齐，且柱状图 = DIF - DEA
+		offset := len(data.MACDValues12269) - len(data.MACDDEA12269)
+		for i, dea := range data.MACDDEA12269 {
+			end := tt.n - len(data.MACDDEA12269) + i + 1
+			_, wantDEA, _ := refMACD(klines[:end], 12, 26, 9)
+			assertFloatEqual(t, "DEA", dea, wantDEA)
+			assertFloatEqual(t, "Hist", data.MACDHist12269[i], data.MACDValues12269[offset+i]-dea)
+		}
+	}
+}
+
+// fixtureCloses 固定的收盘价数据集(60根)，用于 EMA/MACD 新旧实现的回归比较
+var fixtureCloses = []float64{
+	103.0, 104.62, 105.29, 105.33, 105.22, 105.4, 106.1, 107.24, 108.45, 109.22,
+	109.05, 107.65, 105.07, 101.7, 98.18, 95.2, 93.35, 92.87, 93.69, 95.4,
+	97.42, 99.21, 100.45, 101.1, 101.42, 101.87, 102.87, 104.7, 107.29, 110.28,
+	113.08, 115.06, 115.71, 114.87, 112.71, 109.75, 106.64, 104.02, 102.29, 101.55,
+	101.55, 101.86, 101.99, 101.65, 100.8, 99.7, 98.85, 98.79, 99.91, 102.3,
+	105.71, 109.57, 113.19, 115.92, 117.35, 117.43, 116.46, 114.95, 113.46, 112.43,
+}
+
+func TestEMAAndMACDRegression(t *testing.T) {
+	klines := make([]Kline, len(fixtureCloses))
+	for i, c := range fixtureCloses {
+		klines[i] = Kline{OpenTime: int64(i) * 60000, Open: c, High: c + 1, Low: c - 1, Close: c}
+	}
+
+	for _, period := range []int{9, 20, 50} {
+		series := emaSeries(klines, period)
+		for i := range klines {
+			assertFloatEqual(t, "emaSeries", series[i], refEMA(klines[:i+1], period))
+			assertFloatEqual(t, "calculateEMA", calculateEMA(klines[:i+1], period), refEMA(klines[:i+1], period))
+		}
+	}
+
+	for _, m := range []MACDPeriods{{10, 20, 8}, {12, 26, 9}, {14, 28, 10}} {
+		dif, dea, hist, _ := macdSeries(klines, m.Short, m.Long, m.Signal)
+		for i := range klines {
+			wantDIF, wantDEA, wantHist := refMACD(klines[:i+1], m.Short, m.Long, m.Signal)
+			assertFloatEqual(t, "macdSeries DIF", dif[i], wantDIF)
+			assertFloatEqual(t, "macdSeries DEA", dea[i], wantDEA)
+			assertFloatEqual(t, "macdSeries Hist", hist[i], wantHist)
+
+			gotDIF, gotDEA, gotHist := calculateMACD(klines[:i+1], m.Short, m.Long, m.Signal)
+			assertFloatEqual(t, "calculateMACD DIF", gotDIF, wantDIF)
+			assertFloatEqual(t, "calculateMACD DEA", gotDEA, wantDEA)
+			assertFloatEqual(t, "calculateMACD Hist", gotHist, wantHist)
+		}
+	}
+}
+
+// closeKlines 由收盘价构造K线，最高/最低价为收盘价 ± spread
+func closeKlines(spread float64, closes ...float64) []Kline {
+	klines := make([]Kline, len(closes))
+	for i, c := range closes {
+		klines[i] = Kline{OpenTime: int64(i) * 60000, Open: c, High: c + spread, Low: c - spread, Close: c, Volume: 1}
+	}
+	return klines
+}
+
+func TestCalculateBollingerBands(t *testing.T) {
+	tests := []struct {
+		name                       string
+		closes                     []float64
+		period                     int
+		mult                       float64
+		wantUpper, wantMid, wantLo float64
+	}{
+		{"1..5", []float64{1, 2, 3, 4, 5}, 5, 2, 3 + 2*math.Sqrt2, 3, 3 - 2*math.Sqrt2},
+		{"只取最近 period 根", []float64{100, 1, 2, 3, 4, 5}, 5, 1, 3 + math.Sqrt2, 3, 3 - math.Sqrt2},
+		{"无波动", []float64{7, 7, 7}, 3, 2, 7, 7, 7},
+		{"K线不足", []float64{1, 2}, 5, 2, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upper, mid, lower := calculateBollingerBands(closeKlines(0, tt.closes...), tt.period, tt.mult)
+			assertFloatEqual(t, "upper", upper, tt.wantUpper)
+			assertFloatEqual(t, "middle", mid, tt.wantMid)
+			assertFloatEqual(t, "lower", lower, tt.wantLo)
+		})
+	}
+
+	data := calculateIntradaySeries(wavyKlines(100), DefaultIndicatorConfig().Intraday)
+	if !(data.BBUpper > data.BBMiddle && data.BBMiddle > data.BBLower && data.BBLower > 0) {
+		t.Errorf("IntradayData 布林带 = %v/%v/%v，应满足 上轨>中轨>下轨>0", data.BBUpper, data.BBMiddle, data.BBLower)
+	}
+}
+
+func TestCalculateStochastic(t *testing.T) {
+	rising := make([]float64, 20)
+	for i := range rising {
+		rising[i] = 100 + float64(i)
+	}
+	falling := make([]float64, 20)
+	for i := range falling {
+		falling[i] = 100 - float64(i)
+	}
+	tests := []struct {
+		name   string
+		klines []Kline
+		wantK  float64
+		wantD  float64
+	}{
+		{"收于新高", closeKlines(0, rising...), 100, 100},
+		{"收于新低", closeKlines(0, falling...), 0, 0},
+		{"无波动取50", closeKlines(0, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5), 50, 50},
+		{"K线不足", closeKlines(0, rising[:10]...), 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, d := calculateStochastic(tt.klines, 14, 3)
+			assertFloatEqual(t, "%K", k, tt.wantK)
+			assertFloatEqual(t, "%D", d, tt.wantD)
+		})
+	}
+
+	// 回落后再创新高：%K 回到100，%D 为最近3个 %K 的平均
+	closes := append(append([]float64(nil), rising...), 110, 125)
+	k, d := calculateStochastic(closeKlines(0, closes...), 14, 3)
+	assertFloatEqual(t, "新高 %K", k, 100)
+	if d >= 100 || d <= 0 {
+		t.Errorf("%%D = %v，回落后应低于100", d)
+	}
+}
+
+func TestCalculateVWAP(t *testing.T) {
+	tests := []struct {
+		name   string
+		klines []Kline
+		want   float64
+	}{
+		{"按成交量加权", []Kline{
+			{High: 11, Low: 9, Close: 10, Volume: 1},  // 典型价格10
+			{High: 21, Low: 19, Close: 20, Volume: 3}, // 典型价格20
+		}, (10*1 + 20*3) / 4.0},
+		{"成交量为0取最后收盘价", []Kline{{High: 11, Low: 9, Close: 10}, {High: 13, Low: 11, Close: 12.5}}, 12.5},
+		{"无K线", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "VWAP", calculateVWAP(tt.klines), tt.want)
+		})
+	}
+
+	data := calculateLongerTermData(wavyKlines(60), DefaultIndicatorConfig().LongerTerm)
+	if data.VWAP <= 0 {
+		t.Errorf("LongerTermData.VWAP = %v, want > 0", data.VWAP)
+	}
+}
+
+// stepCloses 生成 n 个从 start 开始、每步变化 step 的收盘价
+func stepCloses(n int, start, step float64) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = start + float64(i)*step
+	}
+	return closes
+}
+
+func TestCalculateADX(t *testing.T) {
+	tests := []struct {
+		name                    string
+		klines                  []Kline
+		wantADX, wantPDI, wantM float64
+	}{
+		// 单边上涨：TR 恒为2、+DM 恒为1、-DM 为0，DX 恒为100
+		{"单边上涨", closeKlines(1, stepCloses(40, 100, 1)...), 100, 50, 0},
+		{"单边下跌", closeKlines(1, stepCloses(40, 200, -1)...), 100, 0, 50},
+		{"K线不足", closeKlines(1, stepCloses(14, 100, 1)...), 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adx, pdi, mdi := calculateADX(tt.klines, 14)
+			assertFloatEqual(t, "ADX", adx, tt.wantADX)
+			assertFloatEqual(t, "+DI", pdi, tt.wantPDI)
+			assertFloatEqual(t, "-DI", mdi, tt.wantM)
+		})
+	}
+
+	// 震荡行情的趋势强度应明显低于单边行情
+	if adx, _, _ := calculateADX(wavyKlines(120), 14); adx <= 0 || adx >= 100 {
+		t.Errorf("震荡行情 ADX = %v, want (0, 100)", adx)
+	}
+	// 仅满足 period+1 根时 DI 已可计算而 ADX 仍为0
+	adx, pdi, _ := calculateADX(closeKlines(1, stepCloses(20, 100, 1)...), 14)
+	if adx != 0 || pdi <= 0 {
+		t.Errorf("20根K线 ADX=%v +DI=%v，want ADX=0、+DI>0", adx, pdi)
+	}
+}
+
+func TestCalculateOBV(t *testing.T) {
+	withVolumes := func(klines []Kline, volumes ...float64) []Kline {
+		for i, v := range volumes {
+			klines[i].Volume = v
+		}
+		return klines
+	}
+	tests := []struct {
+		name       string
+		klines     []Kline
+		want       float64
+		wantSeries []float64
+	}{
+		{"上涨/下跌/上涨", withVolumes(closeKlines(0, 10, 11, 10.5, 12), 1, 2, 3, 4), 3, []float64{0, 2, -1, 3}},
+		{"持平不变", withVolumes(closeKlines(0, 10, 10, 11), 5, 6, 7), 7, []float64{0, 0, 7}},
+		{"单根K线", closeKlines(0, 10), 0, []float64{0}},
+		{"无K线", nil, 0, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "OBV", calculateOBV(tt.klines), tt.want)
+			assertSeriesEqual(t, "obvSeries", obvSeries(tt.klines), tt.wantSeries)
+		})
+	}
+
+	// IntradayData.OBVValues 为最近10个点，末值与 OBV 一致
+	data := calculateIntradaySeries(wavyKlines(60), DefaultIndicatorConfig().Intraday)
+	if n := len(data.OBVValues); n != 10 || data.OBVValues[n-1] != data.OBV {
+		t.Errorf("OBVValues = %v, OBV = %v", data.OBVValues, data.OBV)
+	}
+}
+
+func TestCalculateStochRSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []float64
+		want   float64
+	}{
+		// 下跌后持续反弹：RSI 单调上升，当前值为窗口最高
+		{"反弹至窗口最高", append(stepCloses(20, 120, -1), stepCloses(10, 102, 1)...), 100},
+		{"回落至窗口最低", append(stepCloses(20, 100, 1), stepCloses(10, 118, -1)...), 0},
+		{"RSI持平取50", stepCloses(30, 100, 1), 50},
+		{"K线不足", stepCloses(27, 100, 1), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "StochRSI", calculateStochRSI(closeKlines(0, tt.closes...), 14, 14), tt.want)
+		})
+	}
+
+	klines := wavyKlines(200)
+	for i := 28; i <= len(klines); i++ {
+		if v := calculateStochRSI(klines[:i], 14, 14); v < 0 || v > 100 {
+			t.Fatalf("%d 根K线 StochRSI = %v，超出[0,100]", i, v)
+		}
+	}
+}
+
+func TestCalculateWilliamsR(t *testing.T) {
+	tests := []struct {
+		name   string
+		klines []Kline
+		want   float64
+	}{
+		{"收于区间高点", closeKlines(0, stepCloses(20, 100, 1)...), 0},
+		{"收于区间低点", closeKlines(0, stepCloses(20, 100, -1)...), -100},
+		{"收于区间中点", closeKlines(0, 100, 110, 120, 110), -50},
+		{"无波动", closeKlines(0, 5, 5, 5, 5), -50},
+		{"K线不足", closeKlines(0, 1, 2, 3), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "%R", calculateWilliamsR(tt.klines, 4), tt.want)
+		})
+	}
+
+	// 只取最近 period 根：更早的高点不影响结果
+	if got := calculateWilliamsR(closeKlines(0, 500, 100, 110, 120, 130), 4); got != 0 {
+		t.Errorf("%%R = %v, want 0", got)
+	}
+}
+
+func TestCalculateCCI(t *testing.T) {
+	tests := []struct {
+		name   string
+		klines []Kline
+		period int
+		want   float64
+	}{
+		// TP=1,2,3，SMA=2，平均绝对偏差=2/3：(3-2)/(0.015*2/3)=100
+		{"线性上涨", closeKlines(1, 1, 2, 3), 3, 100},
+		// TP=2,4,6,5，SMA=4.25，平均绝对偏差=1.25：(5-4.25)/(0.015*1.25)=40
+		{"冲高回落", closeKlines(1, 2, 4, 6, 5), 4, 40},
+		{"只取最近 period 根", closeKlines(1, 100, 1, 2, 3), 3, 100},
+		{"无波动", closeKlines(1, 5, 5, 5), 3, 0},
+		{"K线不足", closeKlines(1, 1, 2), 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "CCI", calculateCCI(tt.klines, tt.period), tt.want)
+		})
+	}
+}
+
+func TestCalculateDonchian(t *testing.T) {
+	klines := []Kline{
+		{High: 200, Low: 1}, // 窗口外
+		{High: 12, Low: 9},
+		{High: 15, Low: 11},
+		{High: 13, Low: 7},
+		{High: 14, Low: 10},
+	}
+	tests := []struct {
+		name                   string
+		klines                 []Kline
+		period                 int
+		wantUp, wantLo, wantMd float64
+	}{
+		{"最近4根", klines, 4, 15, 7, 11},
+		{"全部K线", klines, 5, 200, 1, 100.5},
+		{"单根", klines, 1, 14, 10, 12},
+		{"K线不足", klines[:3], 4, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upper, lower, mid := calculateDonchian(tt.klines, tt.period)
+			assertFloatEqual(t, "upper", upper, tt.wantUp)
+			assertFloatEqual(t, "lower", lower, tt.wantLo)
+			assertFloatEqual(t, "mid", mid, tt.wantMd)
+		})
+	}
+}
+
+func TestCalculateSupertrend(t *testing.T) {
+	tests := []struct {
+		name        string
+		closes      []float64
+		wantBullish bool
+	}{
+		{"持续上涨", stepCloses(40, 100, 1), true},
+		{"持续下跌", stepCloses(40, 200, -1), false},
+		{"上涨后急跌翻空", append(stepCloses(30, 100, 1), stepCloses(10, 120, -3)...), false},
+		{"下跌后急涨翻多", append(stepCloses(30, 200, -1), stepCloses(10, 180, 3)...), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			klines := closeKlines(1, tt.closes...)
+			value, bullish := calculateSupertrend(klines, 10, 3)
+			if bullish != tt.wantBullish {
+				t.Fatalf("bullish = %v, want %v", bullish, tt.wantBullish)
+			}
+			// 多头时跟踪止损位于价格下方，空头时位于上方
+			last := tt.closes[len(tt.closes)-1]
+			if (bullish && value >= last) || (!bullish && value <= last) {
+				t.Errorf("Supertrend = %v, 收盘价 = %v, bullish = %v", value, last, bullish)
+			}
+		})
+	}
+
+	if value, bullish := calculateSupertrend(closeKlines(1, stepCloses(10, 100, 1)...), 10, 3); value != 0 || bullish {
+		t.Errorf("K线不足时 = %v, %v; want 0, false", value, bullish)
+	}
+}
+
+func TestExportedIndicators(t *testing.T) {
+	klines := closeKlines(1, fixtureCloses...)
+	highs := make([]float64, len(klines))
+	lows := make([]float64, len(klines))
+	for i, k := range klines {
+		highs[i], lows[i] = k.High, k.Low
+	}
+
+	t.Run("EMA", func(t *testing.T) {
+		got, err := EMA([]float64{1, 2, 3, 4, 5}, 3) // SMA(1,2,3)=2 → 3 → 4
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFloatEqual(t, "EMA(1..5,3)", got, 4)
+		got, err = EMA(fixtureCloses, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFloatEqual(t, "EMA(20)", got, refEMA(klines, 20))
+	})
+
+	t.Run("RSI", func(t *testing.T) {
+		got, err := RSI(stepCloses(20, 100, 1), 14)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFloatEqual(t, "单边上涨 RSI", got, 100)
+		got, err = RSI(fixtureCloses, 14)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFloatEqual(t, "RSI(14)", got, refRSI(klines, 14))
+	})
+
+	t.Run("MACD", func(t *testing.T) {
+		dif, dea, hist, err := MACD(fixtureCloses, 12, 26, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantDIF, wantDEA, wantHist := refMACD(klines, 12, 26, 9)
+		assertFloatEqual(t, "DIF", dif, wantDIF)
+		assertFloatEqual(t, "DEA", dea, wantDEA)
+		assertFloatEqual(t, "Hist", hist, wantHist)
+		assertFloatEqual(t, "Hist=DIF-DEA", hist, dif-dea)
+	})
+
+	t.Run("ATR", func(t *testing.T) {
+		// TR[1..2] = max(高-低, |高-前收|, |低-前收|) = 3, 4
+		got, err := ATR([]float64{11, 13, 12}, []float64{9, 10, 8}, []float64{10, 12, 9}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFloatEqual(t, "ATR(2)", got, 3.5)
+		got, err = ATR(highs, lows, fixtureCloses, 14)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertFloatEqual(t, "ATR(14)", got, refATR(klines, 14))
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		if _, err := EMA(fixtureCloses, 0); err == nil {
+			t.Error("EMA 周期为0应返回错误")
+		}
+		if _, err := RSI(fixtureCloses, -1); err == nil {
+			t.Error("RSI 周期为负应返回错误")
+		}
+		if _, _, _, err := MACD(fixtureCloses, 12, 0, 9); err == nil {
+			t.Error("MACD 周期为0应返回错误")
+		}
+		if _, err := ATR(highs[1:], lows, fixtureCloses, 14); err == nil {
+			t.Error("ATR 序列长度不一致应返回错误")
+		}
+	})
+}
+
+func TestExportedIndicatorsInsufficientData(t *testing.T) {
+	ema := func(c []float64) error { _, err := EMA(c, 20); return err }
+	rsi := func(c []float64) error { _, err := RSI(c, 14); return err }
+	macd := func(c []float64) error { _, _, _, err := MACD(c, 12, 26, 9); return err }
+	atr := func(c []float64) error { _, err := ATR(c, c, c, 14); return err }
+	tests := []struct {
+		name string
+		need int // 恰好足够的数据点数
+		calc func([]float64) error
+	}{
+		{"EMA(20)", 20, ema},
+		{"RSI(14)", 15, rsi},
+		{"MACD(12,26,9)", 34, macd},
+		{"ATR(14)", 15, atr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closes := stepCloses(tt.need, 100, 1)
+			if err := tt.calc(closes[:tt.need-1]); !errors.Is(err, ErrInsufficientData) {
+				t.Errorf("%d 个数据点: err = %v, want ErrInsufficientData", tt.need-1, err)
+			}
+			if err := tt.calc(closes); err != nil {
+				t.Errorf("%d 个数据点: err = %v, want nil", tt.need, err)
+			}
+		})
+	}
+	if _, err := EMA(fixtureCloses, 0); errors.Is(err, ErrInsufficientData) {
+		t.Error("周期无效不应返回 ErrInsufficientData")
+	}
+}
+
+func TestCalculateVolumeProfile(t *testing.T) {
+	// 100~124 之间均匀分布的少量成交，叠加 110~111 的大量成交
+	var klines []Kline
+	for i := 0; i < 24; i++ {
+		p := 100 + float64(i)
+		klines = append(klines, Kline{Low: p, High: p + 1, Close: p + 0.5, Volume: 1})
+	}
+	for i := 0; i < 5; i++ {
+		klines = append(klines, Kline{Low: 110, High: 111, Close: 110.5, Volume: 50})
+	}
+
+	poc, vah, val := calculateVolumeProfile(klines, 24)
+	assertFloatEqual(t, "POC", poc, 110.5)
+	if !(val <= 110 && vah >= 111 && val < vah) {
+		t.Errorf("价值区域 [%v, %v] 应覆盖成交密集区 [110, 111]", val, vah)
+	}
+
+	tests := []struct {
+		name                  string
+		klines                []Kline
+		wantPOC, wantH, wantL float64
+	}{
+		{"价格无波动", []Kline{{Low: 5, High: 5, Close: 5, Volume: 3}}, 5, 5, 5},
+		{"成交量为0", []Kline{{Low: 1, High: 2, Close: 1.5}, {Low: 2, High: 3, Close: 2.5}}, 0, 0, 0},
+		{"无K线", nil, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poc, vah, val := calculateVolumeProfile(tt.klines, 24)
+			assertFloatEqual(t, "POC", poc, tt.wantPOC)
+			assertFloatEqual(t, "VAH", vah, tt.wantH)
+			assertFloatEqual(t, "VAL", val, tt.wantL)
+		})
+	}
+}
+
+func TestCalculateROC(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []float64
+		period int
+		want   float64
+	}{
+		{"上涨10%", []float64{100, 105, 103, 110}, 3, 10},
+		{"下跌20%", []float64{50, 100, 90, 80}, 2, -20},
+		{"只比较 period 根前", []float64{1, 100, 120}, 1, 20},
+		{"K线不足", []float64{100, 110}, 2, 0},
+		{"基准价格为0", []float64{0, 1, 2}, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "ROC", calculateROC(closeKlines(0, tt.closes...), tt.period), tt.want)
+		})
+	}
+}
+
+func TestIntradayEMA20IsContinuous(t *testing.T) {
+	klines := wavyKlines(100)
+
+	// 参考实现：以前20根SMA为种子只初始化一次，此后逐根递推
+	continuous := make([]float64, len(klines))
+	seed := 0.0
+	for i := 0; i < 20; i++ {
+		seed += klines[i].Close
+	}
+	continuous[19] = seed / 20
+	for i := 20; i < len(klines); i++ {
+		continuous[i] = continuous[i-1] + 2.0/21*(klines[i].Close-continuous[i-1])
+	}
+
+	data := calculateIntradaySeries(klines, DefaultIndicatorConfig().Intraday)
+	assertSeriesEqual(t, "EMA20Values", data.EMA20Values, continuous[len(klines)-10:])
+	assertFloatEqual(t, "calculateEMA", calculateEMA(klines, 20), continuous[len(klines)-1])
+
+	// 序列增长时已输出的点保持不变
+	longer := calculateIntradaySeries(wavyKlines(101), DefaultIndicatorConfig().Intraday)
+	assertSeriesEqual(t, "增长后的 EMA20Values", longer.EMA20Values[:9], data.EMA20Values[1:])
+}
+
+func TestCalculateATRPercent(t *testing.T) {
+	// 价格整体放大1000倍、相对波动不变时，ATR% 相同
+	base := wavyKlines(60)
+	scaled := make([]Kline, len(base))
+	for i, k := range base {
+		k.Open, k.High, k.Low, k.Close = k.Open*1000, k.High*1000, k.Low*1000, k.Close*1000
+		scaled[i] = k
+	}
+	assertFloatEqual(t, "放大后的ATR14%", calculateATRPercent(scaled, 14), calculateATRPercent(base, 14))
+
+	tests := []struct {
+		name   string
+		klines []Kline
+		want   float64
+	}{
+		// 收盘价恒为100、振幅±1：TR=2，ATR%=2
+		{"固定振幅", closeKlines(1, stepCloses(20, 100, 0)...), 2},
+		{"收盘价为0", closeKlines(1, stepCloses(20, 0, 0)...), 0},
+		{"无K线", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "ATR14%", calculateATRPercent(tt.klines, 14), tt.want)
+		})
+	}
+}
+
+func TestCalculateCMF(t *testing.T) {
+	// 资金流乘数：(8-0-2)/10=0.6，(2-0-8)/10=-0.6，最高等于最低时为0
+	klines := []Kline{
+		{High: 10, Low: 0, Close: 8, Volume: 100},
+		{High: 10, Low: 0, Close: 2, Volume: 50},
+		{High: 5, Low: 5, Close: 5, Volume: 50},
+	}
+	tests := []struct {
+		name   string
+		klines []Kline
+		period int
+		want   float64
+	}{
+		{"全部3根", klines, 3, (60 - 30) / 200.0},
+		{"最近2根", klines, 2, -30 / 100.0},
+		{"总成交量为0", []Kline{{High: 10, Low: 0, Close: 8}}, 1, 0},
+		{"K线不足", klines, 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "CMF", calculateCMF(tt.klines, tt.period), tt.want)
+		})
+	}
+}
+
+func TestTrueRangeSeries(t *testing.T) {
+	klines := []Kline{
+		{High: 12, Low: 9, Close: 10},   // 首根：高-低=3
+		{High: 13, Low: 11, Close: 12},  // 高-低=2，|高-前收|=3
+		{High: 12.5, Low: 8, Close: 9},  // 高-低=4.5
+		{High: 9.5, Low: 9.2, Close: 9}, // 高-低=0.3，|高-前收|=0.5
+		{High: 8.5, Low: 7, Close: 8},   // 跳空低开：|低-前收|=2
+	}
+	assertSeriesEqual(t, "TrueRangeSeries", TrueRangeSeries(klines), []float64{3, 3, 4.5, 0.5, 2})
+	if got := TrueRangeSeries(nil); len(got) != 0 {
+		t.Errorf("TrueRangeSeries(nil) = %v, want 空", got)
+	}
+}
+
+func TestCalculateVWAPBands(t *testing.T) {
+	klines := []Kline{
+		{High: 11, Low: 9, Close: 10, Volume: 1},  // 典型价格10
+		{High: 21, Low: 19, Close: 20, Volume: 3}, // 典型价格20
+	}
+	// VWAP=17.5，加权方差=(1×7.5²+3×2.5²)/4=18.75
+	stdDev := math.Sqrt(18.75)
+	tests := []struct {
+		name string
+		mult float64
+	}{
+		{"1倍", 1},
+		{"2倍", 2},
+		{"3倍", 3},
+	}
+	prevWidth := 0.0
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vwap, upper, lower := calculateVWAPBands(klines, tt.mult)
+			assertFloatEqual(t, "VWAP", vwap, 17.5)
+			assertFloatEqual(t, "上轨", upper, 17.5+tt.mult*stdDev)
+			assertFloatEqual(t, "上下轨对称", upper-vwap, vwap-lower)
+			if width := upper - lower; width <= prevWidth {
+				t.Errorf("mult=%v 带宽 %v 未大于较小倍数的 %v", tt.mult, width, prevWidth)
+			} else {
+				prevWidth = width
+			}
+		})
+	}
+
+	if vwap, upper, lower := calculateVWAPBands([]Kline{{High: 11, Low: 9, Close: 10}}, 2); upper != vwap || lower != vwap {
+		t.Errorf("成交量为0: VWAP=%v, 上轨=%v, 下轨=%v; want 上下轨等于VWAP", vwap, upper, lower)
+	}
+}
@@ -0,0 +1,28 @@
+package market
+
+import "time"
+
+// intervalDurations Binance 支持的K线周期及其时长（1M 按30天近似）
+var intervalDurations = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+	"1M":  30 * 24 * time.Hour,
+}
+
+// intervalDuration 返回K线周期对应的时长，不支持的周期返回 false
+func intervalDuration(interval string) (time.Duration, bool) {
+	d, ok := intervalDurations[interval]
+	return d, ok
+}
@@ -0,0 +1,81 @@
+package market
+
+import "sort"
+
+// LevelClusterPercent 相邻摆动点价格相差不超过该百分比时合并为同一价位
+var LevelClusterPercent = 0.5
+
+// levelLookback Data 中支撑/阻力检测使用的摆动点左右确认K线数（基于1小时K线）
+const levelLookback = 5
+
+// DetectLevels 基于摆动点识别支撑位与阻力位
+// 摆动高点：最高价高于左侧 lookback 根且不低于右侧 lookback 根K线的最高价；摆动低点同理。
+// 价格相近(LevelClusterPercent 以内)的摆动点合并为一个价位(取均值)，结果按价格升序返回。
+// 最近 lookback 根K线右侧确认不足，不参与识别
+func DetectLevels(klines []Kline, lookback int) (supports, resistances []float64) {
+	if lookback <= 0 || len(klines) < 2*lookback+1 {
+		return nil, nil
+	}
+
+	var lows, highs []float64
+	for i := lookback; i < len(klines)-lookback; i++ {
+		isHigh, isLow := true, true
+		for j := i - lookback; j <= i+lookback && (isHigh || isLow); j++ {
+			if j == i {
+				continue
+			}
+			if j < i {
+				isHigh = isHigh && klines[i].High > klines[j].High
+				isLow = isLow && klines[i].Low < klines[j].Low
+			} else {
+				isHigh = isHigh && klines[i].High >= klines[j].High
+				isLow = isLow && klines[i].Low <= klines[j].Low
+			}
+		}
+		if isHigh {
+			highs = append(highs, klines[i].High)
+		}
+		if isLow {
+			lows = append(lows, klines[i].Low)
+		}
+	}
+	return clusterLevels(lows), clusterLevels(highs)
+}
+
+// clusterLevels 将价格升序排列后，把与当前簇均值相差在 LevelClusterPercent 以内的价格合并
+func clusterLevels(prices []float64) []float64 {
+	if len(prices) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), prices...)
+	sort.Float64s(sorted)
+
+	var levels []float64
+	sum, count := sorted[0], 1
+	for _, p := range sorted[1:] {
+		mean := sum / float64(count)
+		if mean > 0 && (p-mean)/mean*100 <= LevelClusterPercent {
+			sum += p
+			count++
+			continue
+		}
+		levels = append(levels, mean)
+		sum, count = p, 1
+	}
+	return append(levels, sum/float64(count))
+}
+
+// nearestLevels 返回低于当前价格的最高支撑位与高于当前价格的最低阻力位，不存在时为0
+func nearestLevels(price float64, supports, resistances []float64) (support, resistance float64) {
+	for _, s := range supports {
+		if s < price && s > support {
+			support = s
+		}
+	}
+	for _, r := range resistances {
+		if r > price && (resistance == 0 || r < resistance) {
+			resistance = r
+		}
+	}
+	return support, resistance
+}
@@ -0,0 +1,36 @@
+package market
+
+import "testing"
+
+// zigzagCloses 在相邻拐点之间按 steps 步线性插值，生成之字形收盘价
+func zigzagCloses(steps int, pivots ...float64) []float64 {
+	closes := []float64{pivots[0]}
+	for i := 1; i < len(pivots); i++ {
+		from, to := pivots[i-1], pivots[i]
+		for k := 1; k <= steps; k++ {
+			closes = append(closes, from+(to-from)*float64(k)/float64(steps))
+		}
+	}
+	return closes
+}
+
+func TestDetectLevels(t *testing.T) {
+	// 高点 110/120/110.2，低点 95/100.3；110 与 110.2 相差不足0.5%合并为110.1
+	// 首尾拐点缺少一侧确认，不参与识别
+	klines := closeKlines(0, zigzagCloses(5, 100, 110, 95, 120, 100.3, 110.2, 100)...)
+	supports, resistances := DetectLevels(klines, 3)
+	assertSeriesEqual(t, "supports", supports, []float64{95, 100.3})
+	assertSeriesEqual(t, "resistances", resistances, []float64{110.1, 120})
+
+	support, resistance := nearestLevels(105, supports, resistances)
+	assertFloatEqual(t, "最近支撑", support, 100.3)
+	assertFloatEqual(t, "最近阻力", resistance, 110.1)
+
+	// 单边行情没有摆动点
+	if s, r := DetectLevels(closeKlines(0, stepCloses(30, 100, 1)...), 3); s != nil || r != nil {
+		t.Errorf("单边上涨 = %v, %v; want nil, nil", s, r)
+	}
+	if s, r := DetectLevels(klines[:6], 3); s != nil || r != nil {
+		t.Errorf("K线不足 = %v, %v; want nil, nil", s, r)
+	}
+}
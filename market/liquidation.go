@@ -0,0 +1,75 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LiquidationLimit 获取的最近强平订单条数
+var LiquidationLimit = 100
+
+// LiquidationData 最近强平订单汇总（名义价值按成交均价 * 成交数量计算，单位为计价资产）
+type LiquidationData struct {
+	LongVolume  float64   `json:"long_volume"`  // 多头被强平的名义价值（强平单方向为 SELL）
+	ShortVolume float64   `json:"short_volume"` // 空头被强平的名义价值（强平单方向为 BUY）
+	LongCount   int       `json:"long_count"`
+	ShortCount  int       `json:"short_count"`
+	Since       Timestamp `json:"since"` // 统计窗口内最早一笔强平时间
+}
+
+// getLiquidations 获取最近的强平订单并按多空汇总
+// 说明：Binance 已限制公开的 allForceOrders 接口，部分地区/账户可能返回错误，调用方应按非致命处理
+func getLiquidations(ctx context.Context, symbol string) (*LiquidationData, error) {
+	url := fmt.Sprintf("%s/fapi/v1/allForceOrders?symbol=%s&limit=%d", BaseURL, symbol, LiquidationLimit)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseLiquidations(body)
+}
+
+// parseLiquidations 解析强平订单列表并汇总多空强平量
+func parseLiquidations(body []byte) (*LiquidationData, error) {
+	var orders []struct {
+		Symbol       string `json:"symbol"`
+		Side         string `json:"side"`
+		AveragePrice string `json:"averagePrice"`
+		ExecutedQty  string `json:"executedQty"`
+		Time         int64  `json:"time"`
+	}
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, err
+	}
+
+	data := &LiquidationData{}
+	var earliest int64
+	for _, o := range orders {
+		price, err := strconv.ParseFloat(o.AveragePrice, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse averagePrice failed: %w", err)
+		}
+		qty, err := strconv.ParseFloat(o.ExecutedQty, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse executedQty failed: %w", err)
+		}
+		switch o.Side {
+		case "SELL":
+			data.LongVolume += price * qty
+			data.LongCount++
+		case "BUY":
+			data.ShortVolume += price * qty
+			data.ShortCount++
+		}
+		if earliest == 0 || (o.Time > 0 && o.Time < earliest) {
+			earliest = o.Time
+		}
+	}
+	if earliest > 0 {
+		data.Since = Timestamp{time.UnixMilli(earliest)}
+	}
+	return data, nil
+}
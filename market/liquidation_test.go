@@ -0,0 +1,37 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLiquidations(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *LiquidationData
+		wantErr bool
+	}{
+		{"按方向汇总", `[` +
+			`{"symbol":"BTCUSDT","side":"SELL","averagePrice":"100","executedQty":"2","time":1700000300000},` +
+			`{"symbol":"BTCUSDT","side":"BUY","averagePrice":"110","executedQty":"1","time":1700000100000},` +
+			`{"symbol":"BTCUSDT","side":"SELL","averagePrice":"90","executedQty":"0.5","time":1700000200000}]`,
+			&LiquidationData{LongVolume: 245, ShortVolume: 110, LongCount: 2, ShortCount: 1,
+				Since: Timestamp{time.UnixMilli(1700000100000)}}, false},
+		{"无强平", `[]`, &LiquidationData{}, false},
+		{"数量格式错误", `[{"side":"SELL","averagePrice":"100","executedQty":"x","time":1}]`, nil, true},
+		{"非数组响应", `{"code":-1,"msg":"x"}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLiquidations([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLiquidations = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package market
+
+import "time"
+
+// Logger 行情获取过程的日志接口，可接入调用方自己的日志系统
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// nopLogger 默认的空日志实现
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+
+// logger Get 系列函数使用的日志实现，默认不输出
+var logger Logger = nopLogger{}
+
+// SetLogger 设置日志实现，传入 nil 时恢复为不输出；应在发起请求前调用
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	logger = l
+}
+
+// logFetch 记录一次上游获取的结果与耗时：成功为 Debug，失败为 Warn
+func logFetch(symbol, what string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	if err != nil {
+		logger.Warnf("获取 %s %s 失败(耗时 %v): %v", symbol, what, elapsed, err)
+		return
+	}
+	logger.Debugf("获取 %s %s 成功(耗时 %v)", symbol, what, elapsed)
+}
@@ -0,0 +1,100 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LongShortRatioPeriod 多空账户比统计周期，可选 5m/15m/30m/1h/2h/4h/6h/12h/1d
+var LongShortRatioPeriod = "5m"
+
+// LongShortData 全市场多空账户比（Binance globalLongShortAccountRatio 最新一条）
+type LongShortData struct {
+	Ratio        float64   `json:"ratio"`         // 多空账户数比值 = LongAccount / ShortAccount
+	LongAccount  float64   `json:"long_account"`  // 持多仓账户占比(0-1)
+	ShortAccount float64   `json:"short_account"` // 持空仓账户占比(0-1)
+	Timestamp    Timestamp `json:"timestamp"`     // 统计时间
+}
+
+// getLongShortRatio 获取最新的多空账户比
+func getLongShortRatio(ctx context.Context, symbol string) (*LongShortData, error) {
+	url := fmt.Sprintf("%s/futures/data/globalLongShortAccountRatio?symbol=%s&period=%s&limit=1",
+		BaseURL, symbol, LongShortRatioPeriod)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		Symbol         string `json:"symbol"`
+		LongShortRatio string `json:"longShortRatio"`
+		LongAccount    string `json:"longAccount"`
+		ShortAccount   string `json:"shortAccount"`
+		Timestamp      int64  `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("多空账户比数据为空")
+	}
+
+	latest := result[len(result)-1]
+	ratio, err := strconv.ParseFloat(latest.LongShortRatio, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse longShortRatio failed: %w", err)
+	}
+	longAccount, err := strconv.ParseFloat(latest.LongAccount, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse longAccount failed: %w", err)
+	}
+	shortAccount, err := strconv.ParseFloat(latest.ShortAccount, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse shortAccount failed: %w", err)
+	}
+
+	return &LongShortData{
+		Ratio:        ratio,
+		LongAccount:  longAccount,
+		ShortAccount: shortAccount,
+		Timestamp:    Timestamp{time.UnixMilli(latest.Timestamp)},
+	}, nil
+}
+
+// TakerBuySellPeriod 主动买卖量比统计周期，取值同 LongShortRatioPeriod
+var TakerBuySellPeriod = "5m"
+
+// getTakerBuySellRatio 获取最新的主动买入量/主动卖出量比值(buySellRatio)
+// 大于1表示主动买盘占优，可与资金费率、OI配合判断订单流方向
+func getTakerBuySellRatio(ctx context.Context, symbol, period string) (float64, error) {
+	url := fmt.Sprintf("%s/futures/data/takerlongshortRatio?symbol=%s&period=%s&limit=1",
+		BaseURL, symbol, period)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	var result []struct {
+		BuySellRatio string `json:"buySellRatio"`
+		BuyVol       string `json:"buyVol"`
+		SellVol      string `json:"sellVol"`
+		Timestamp    int64  `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, fmt.Errorf("主动买卖量比数据为空")
+	}
+
+	ratio, err := strconv.ParseFloat(result[len(result)-1].BuySellRatio, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse buySellRatio failed: %w", err)
+	}
+	return ratio, nil
+}
@@ -0,0 +1,84 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGetLongShortRatio(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantRatio float64
+		wantLong  float64
+		wantErr   bool
+	}{
+		{"取最新一条", `[` +
+			`{"symbol":"BTCUSDT","longShortRatio":"1.2","longAccount":"0.5455","shortAccount":"0.4545","timestamp":1700000000000},` +
+			`{"symbol":"BTCUSDT","longShortRatio":"1.5","longAccount":"0.6","shortAccount":"0.4","timestamp":1700000300000}]`,
+			1.5, 0.6, false},
+		{"空数组", `[]`, 0, 0, true},
+		{"比值格式错误", `[{"longShortRatio":"abc","longAccount":"0.6","shortAccount":"0.4","timestamp":1}]`, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/futures/data/globalLongShortAccountRatio": func(w http.ResponseWriter, r *http.Request) {
+					if got := r.URL.Query().Get("period"); got != LongShortRatioPeriod {
+						t.Errorf("period = %q, want %q", got, LongShortRatioPeriod)
+					}
+					jsonHandler(tt.body)(w, r)
+				},
+			})
+
+			data, err := getLongShortRatio(context.Background(), "BTCUSDT")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assertFloatEqual(t, "Ratio", data.Ratio, tt.wantRatio)
+			assertFloatEqual(t, "LongAccount", data.LongAccount, tt.wantLong)
+			assertFloatEqual(t, "ShortAccount", data.ShortAccount, 1-tt.wantLong)
+			if got := data.Timestamp.UnixMilli(); got != 1700000300000 {
+				t.Errorf("Timestamp = %d, want 1700000300000", got)
+			}
+		})
+	}
+}
+
+func TestGetTakerBuySellRatio(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    float64
+		wantErr bool
+	}{
+		{"取最新一条", `[` +
+			`{"buySellRatio":"0.8","buyVol":"80","sellVol":"100","timestamp":1700000000000},` +
+			`{"buySellRatio":"1.25","buyVol":"125","sellVol":"100","timestamp":1700000300000}]`, 1.25, false},
+		{"空数组", `[]`, 0, true},
+		{"比值格式错误", `[{"buySellRatio":"","buyVol":"1","sellVol":"1","timestamp":1}]`, 0, true},
+		{"非JSON响应", `not json`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/futures/data/takerlongshortRatio": func(w http.ResponseWriter, r *http.Request) {
+					if got := r.URL.Query().Get("period"); got != "15m" {
+						t.Errorf("period = %q, want 15m", got)
+					}
+					jsonHandler(tt.body)(w, r)
+				},
+			})
+
+			got, err := getTakerBuySellRatio(context.Background(), "BTCUSDT", "15m")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			assertFloatEqual(t, "buySellRatio", got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package market
+
+import (
+	"net/url"
+	"path"
+	"time"
+)
+
+// Metrics 上游请求的监控指标接口，可接入 Prometheus 等监控系统而无需本包依赖其客户端库
+// endpoint 为接口名：REST 请求取URL路径最后一段(如 "openInterest"、"premiumIndex")，
+// WebSocket K线缓存读取为 "wsKlines"
+type Metrics interface {
+	ObserveLatency(endpoint string, d time.Duration)
+	IncError(endpoint string)
+}
+
+// nopMetrics 默认的空指标实现
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveLatency(string, time.Duration) {}
+func (nopMetrics) IncError(string)                      {}
+
+// metrics 上游请求使用的指标实现，默认不记录
+var metrics Metrics = nopMetrics{}
+
+// SetMetrics 设置指标实现，传入 nil 时恢复为不记录；应在发起请求前调用
+func SetMetrics(m Metrics) {
+	if m == nil {
+		m = nopMetrics{}
+	}
+	metrics = m
+}
+
+// wsKlinesEndpoint 从 KlineSource 读取K线时上报的接口名
+const wsKlinesEndpoint = "wsKlines"
+
+// endpointName 从请求URL提取接口名，如 .../fapi/v1/openInterest?symbol=X → "openInterest"
+func endpointName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return "unknown"
+	}
+	return path.Base(u.Path)
+}
+
+// observeFetch 上报一次上游请求的耗时，失败时同时累计错误数
+func observeFetch(endpoint string, start time.Time, err error) {
+	metrics.ObserveLatency(endpoint, time.Since(start))
+	if err != nil {
+		metrics.IncError(endpoint)
+	}
+}
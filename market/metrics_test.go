@@ -0,0 +1,85 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMetrics 记录上报的耗时与错误次数
+type fakeMetrics struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func (m *fakeMetrics) ObserveLatency(endpoint string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies[endpoint] = append(m.latencies[endpoint], d)
+}
+
+func (m *fakeMetrics) IncError(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[endpoint]++
+}
+
+func TestMetricsObserveREST(t *testing.T) {
+	sink := &fakeMetrics{latencies: map[string][]time.Duration{}, errors: map[string]int{}}
+	SetMetrics(sink)
+	t.Cleanup(func() { SetMetrics(nil) })
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/openInterest": func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(5 * time.Millisecond)
+			jsonHandler(`{"openInterest":"100","symbol":"BTCUSDT"}`)(w, r)
+		},
+	})
+
+	if _, err := doRequest(context.Background(), BaseURL+"/fapi/v1/openInterest?symbol=BTCUSDT"); err != nil {
+		t.Fatalf("doRequest: %v", err)
+	}
+	doRequest(context.Background(), BaseURL+"/fapi/v1/premiumIndex?symbol=BTCUSDT") // 404
+
+	tests := []struct {
+		endpoint     string
+		wantObserved int
+		wantErrors   int
+		minLatency   time.Duration
+	}{
+		{"openInterest", 1, 0, 5 * time.Millisecond},
+		{"premiumIndex", 1, 1, 0},
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for _, tt := range tests {
+		got := sink.latencies[tt.endpoint]
+		if len(got) != tt.wantObserved || sink.errors[tt.endpoint] != tt.wantErrors {
+			t.Errorf("%s: 耗时上报 %d 次、错误 %d 次, want %d、%d",
+				tt.endpoint, len(got), sink.errors[tt.endpoint], tt.wantObserved, tt.wantErrors)
+			continue
+		}
+		if got[0] < tt.minLatency {
+			t.Errorf("%s 耗时 = %v, want >= %v", tt.endpoint, got[0], tt.minLatency)
+		}
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://fapi.binance.com/fapi/v1/openInterest?symbol=BTCUSDT", "openInterest"},
+		{"https://fapi.binance.com/futures/data/openInterestHist?period=5m", "openInterestHist"},
+		{"https://fapi.binance.com", "unknown"},
+		{"://bad", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := endpointName(tt.url); got != tt.want {
+			t.Errorf("endpointName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
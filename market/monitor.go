@@ -25,6 +25,10 @@ type WSMonitor struct {
 	filterSymbols  sync.Map // 使用sync.Map来存储需要监控的币种和其状态
 	symbolStats    sync.Map // 存储币种统计信息
 	FilterSymbol   []string //经过筛选的币种
+
+	closeMu   sync.Mutex                         // 保护 closeSubs
+	closeSubs map[string]map[chan Kline]struct{} // K线收盘事件订阅者，key 为 symbol@interval
+	closeOnce sync.Once                          // 保证 Close 只执行一次
 }
 type SymbolStats struct {
 	LastActiveTime   time.Time
@@ -179,7 +183,7 @@ func (m *WSMonitor) subscribeSymbol(symbol, st string) []string {
 	stream := fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), st)
 	ch := m.combinedClient.AddSubscriber(stream, 100)
 	streams = append(streams, stream)
-	go m.handleKlineData(symbol, ch, st)
+	goBackground(func() { m.handleKlineData(symbol, ch, st) })
 
 	return streams
 }
@@ -274,6 +278,52 @@ func (m *WSMonitor) processKlineUpdate(symbol string, wsData KlineWSData, _time
 	}
 
 	klineDataMap.Store(symbol, klines)
+
+	if wsData.Kline.IsFinal {
+		m.notifyCandleClose(symbol, _time, kline)
+	}
+}
+
+// SubscribeCandleClose 订阅 symbol 在 interval 周期的K线收盘事件，返回事件通道与取消函数
+// 订阅者处理不及时时丢弃新事件而不阻塞 WebSocket 处理；取消或 Close 后通道被关闭
+func (m *WSMonitor) SubscribeCandleClose(symbol, interval string) (<-chan Kline, func()) {
+	key := strings.ToUpper(symbol) + "@" + interval
+	ch := make(chan Kline, 1)
+
+	m.closeMu.Lock()
+	if m.closeSubs == nil {
+		m.closeSubs = make(map[string]map[chan Kline]struct{})
+	}
+	if m.closeSubs[key] == nil {
+		m.closeSubs[key] = make(map[chan Kline]struct{})
+	}
+	m.closeSubs[key][ch] = struct{}{}
+	m.closeMu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			m.closeMu.Lock()
+			defer m.closeMu.Unlock()
+			if _, ok := m.closeSubs[key][ch]; ok {
+				delete(m.closeSubs[key], ch)
+				close(ch)
+			}
+		})
+	}
+	return ch, cancel
+}
+
+// notifyCandleClose 向订阅者推送已收盘K线
+func (m *WSMonitor) notifyCandleClose(symbol, interval string, kline Kline) {
+	m.closeMu.Lock()
+	defer m.closeMu.Unlock()
+	for ch := range m.closeSubs[strings.ToUpper(symbol)+"@"+interval] {
+		select {
+		case ch <- kline:
+		default:
+		}
+	}
 }
 
 func (m *WSMonitor) GetCurrentKlines(symbol string, _time string) ([]Kline, error) {
@@ -311,7 +361,20 @@ func (m *WSMonitor) GetCurrentKlines(symbol string, _time string) ([]Kline, erro
 	return result, nil
 }
 
+// Close 关闭 WebSocket 连接、告警通道与K线收盘订阅；可重复调用（如 Shutdown 之后再次调用），只执行一次
 func (m *WSMonitor) Close() {
-	m.wsClient.Close()
-	close(m.alertsChan)
+	m.closeOnce.Do(func() {
+		m.wsClient.Close()
+		m.combinedClient.Close()
+		close(m.alertsChan)
+
+		m.closeMu.Lock()
+		for _, subs := range m.closeSubs {
+			for ch := range subs {
+				close(ch)
+			}
+		}
+		m.closeSubs = nil
+		m.closeMu.Unlock()
+	})
 }
@@ -0,0 +1,220 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Market 行情市场类型
+type Market string
+
+const (
+	Futures Market = "futures" // U本位合约（默认）
+	Spot    Market = "spot"    // 现货：无持仓量/资金费率等合约数据
+)
+
+// DefaultTimeframes Get 默认获取的K线周期
+var DefaultTimeframes = []string{"3m", "15m", "1h", "4h", "1d"}
+
+// Options 控制获取哪些K线周期以及每个周期参与计算的K线数量
+type Options struct {
+	Timeframes []string // K线周期（如 "1m"、"1w"），为空时使用 DefaultTimeframes
+	KlineLimit int      // 每个周期取最近N根K线计算指标，<=0 或超过缓存数量时使用全部缓存K线
+	OmitKlines bool     // 为 true 时不在 Data.Klines 中保留原始K线，减少内存占用
+	Market     Market   // 市场类型，为空时按 Futures 处理
+	DepthLimit int      // 订单簿深度快照档位数（Binance 可选 5/10/20/50/100/500/1000），<=0(默认)时不获取（仅合约）
+	Weekly     bool     // 为 true 时在 Timeframes 之外额外获取1w K线，计算 Data.LongerTerm1w
+	Monthly    bool     // 为 true 时在 Timeframes 之外额外获取1M K线，计算 Data.LongerTerm1Mo
+
+	// HeikinAshi 为 true 时日内指标(Data.Timeframes 及3m/15m/1h字段)基于平均K线计算，
+	// 当前价格、价格变化、长期指标与 Data.Klines 仍使用原始K线
+	HeikinAshi bool
+
+	// Liquidations 为 true 时获取最近强平订单汇总(Data.Liquidations)。
+	// 对应的 /fapi/v1/allForceOrders 接口已被 Binance 限制，多数情况下只会返回错误，默认不获取
+	Liquidations bool
+
+	// 以下合约情绪数据各需一次额外的 REST 请求，默认不获取以免增加每次 Get 的请求权重与延迟：
+	// FundingHistory 获取历史资金费率(Data.FundingHistory/FundingTrend/FundingPercentile)；
+	// LongShortRatio 获取多空账户比(Data.LongShort)；TakerBuySellRatio 获取主动买卖量比(Data.TakerBuySellRatio)；
+	// Ticker24h 获取24小时行情统计(Data.Ticker)
+	FundingHistory    bool
+	LongShortRatio    bool
+	TakerBuySellRatio bool
+	Ticker24h         bool
+
+	// Indicators 指标周期配置，为 nil 时使用 DefaultIndicatorConfig()；未设置 Intraday/LongerTerm 时这两组使用默认周期。
+	// 配置按每个周期实际获取的K线数量(见 KlineLimit)检查，非法时在发起请求前返回错误
+	Indicators *IndicatorConfig
+
+	// Source 合约K线数据源，为 nil 时使用 WSMonitorCli
+	Source KlineSource
+
+	// KlineTimeout 单个周期从 Source 获取K线的超时时间，超时后改用合约 REST klines 接口；
+	// <=0 时使用 DefaultKlineTimeout
+	KlineTimeout time.Duration
+
+	// EndTime 非零时获取截至该时间的历史K线（REST klines 接口，忽略 Source），用于回测；
+	// 此时不获取 OI、资金费率、订单簿等只有当前值的合约数据
+	EndTime time.Time
+
+	// DerivedTimeframes 由基础周期K线重采样得到的周期（目标周期 → 基础周期，如 {"30m": "3m"}），
+	// 用于数据源不提供的周期；目标周期须为基础周期的整数倍且不能是1w/1M，基础周期不能再是派生周期。
+	// 派生K线数量约为基础K线数量除以倍数（如100根3m只能得到约10根30m）
+	DerivedTimeframes map[string]string
+
+	// SkipInvalidKlines 为 true 时，未通过 ValidateKlines 的周期记录日志后跳过（对应字段为空），
+	// 否则直接返回错误；所有周期均被跳过时仍返回错误
+	SkipInvalidKlines bool
+}
+
+// DefaultKlineTimeout Options.KlineTimeout 未设置时的默认超时
+var DefaultKlineTimeout = 5 * time.Second
+
+// DefaultOptions 返回 Get 使用的默认选项
+func DefaultOptions() Options {
+	return Options{
+		Timeframes: append([]string(nil), DefaultTimeframes...),
+		KlineLimit: defaultKlineLimit,
+		Market:     Futures,
+	}
+}
+
+// GetWithOptions 按指定周期、K线数量与指标配置(Options.Indicators)获取市场数据
+// 每个周期的日内指标写入 Data.Timeframes；3m/15m/1h/4h/1d 同时填充对应的固定字段以保持兼容，
+// 未请求的周期对应字段保持零值。当前价格与headline指标取自3m，未请求3m时取自最短周期
+func GetWithOptions(ctx context.Context, symbol string, opts Options) (*Data, error) {
+	timeframes, err := opts.timeframes()
+	if err != nil {
+		return nil, err
+	}
+	opts.Timeframes = timeframes
+	if opts.Market != "" && opts.Market != Futures && opts.Market != Spot {
+		return nil, fmt.Errorf("不支持的市场类型: %s", opts.Market)
+	}
+	cfg := DefaultIndicatorConfig()
+	if opts.Indicators != nil {
+		cfg = opts.Indicators.withDefaults()
+		if err := cfg.validate(opts.klineLimit()); err != nil {
+			return nil, fmt.Errorf("指标配置无效: %w", err)
+		}
+	}
+	return getWithConfig(ctx, symbol, cfg, opts)
+}
+
+// klineLimit 返回每个周期最多参与计算的K线数量：从数据源(WebSocket 缓存)获取时不超过 defaultKlineLimit，
+// 历史模式(EndTime)与现货按 KlineLimit 经 REST 获取；KlineLimit<=0 时为 defaultKlineLimit
+func (o Options) klineLimit() int {
+	limit := o.KlineLimit
+	if limit <= 0 {
+		limit = defaultKlineLimit
+	}
+	if o.EndTime.IsZero() && o.market() == Futures && limit > defaultKlineLimit {
+		limit = defaultKlineLimit
+	}
+	return limit
+}
+
+// market 返回实际使用的市场类型，为空时为 Futures
+func (o Options) market() Market {
+	if o.Market == "" {
+		return Futures
+	}
+	return o.Market
+}
+
+// klineSource 返回实际使用的K线数据源，未指定时为 WSMonitorCli
+func (o Options) klineSource() KlineSource {
+	if o.Source != nil {
+		return o.Source
+	}
+	return WSMonitorCli
+}
+
+// klineTimeout 返回实际使用的K线数据源超时时间
+func (o Options) klineTimeout() time.Duration {
+	if o.KlineTimeout > 0 {
+		return o.KlineTimeout
+	}
+	return DefaultKlineTimeout
+}
+
+// timeframes 校验并去重周期列表，保持输入顺序；Weekly/Monthly 对应的周期追加在末尾
+func (o Options) timeframes() ([]string, error) {
+	requested := o.Timeframes
+	if len(requested) == 0 {
+		requested = DefaultTimeframes
+	}
+	if o.Weekly {
+		requested = append(append([]string(nil), requested...), "1w")
+	}
+	if o.Monthly {
+		requested = append(append([]string(nil), requested...), "1M")
+	}
+	for target, base := range o.DerivedTimeframes {
+		if err := validateDerivedTimeframe(target, base, o.DerivedTimeframes); err != nil {
+			return nil, err
+		}
+	}
+	seen := make(map[string]bool, len(requested))
+	result := make([]string, 0, len(requested))
+	for _, tf := range requested {
+		if _, ok := intervalDuration(tf); !ok {
+			return nil, fmt.Errorf("不支持的K线周期: %s", tf)
+		}
+		if !seen[tf] {
+			seen[tf] = true
+			result = append(result, tf)
+		}
+	}
+	return result, nil
+}
+
+// validateDerivedTimeframe 校验派生周期配置
+func validateDerivedTimeframe(target, base string, derived map[string]string) error {
+	to, ok := intervalDuration(target)
+	if !ok || target == "1w" || target == "1M" {
+		return fmt.Errorf("不支持的派生K线周期: %s", target)
+	}
+	from, ok := intervalDuration(base)
+	if !ok {
+		return fmt.Errorf("派生周期 %s 的基础周期不支持: %s", target, base)
+	}
+	if _, nested := derived[base]; nested {
+		return fmt.Errorf("派生周期 %s 的基础周期 %s 本身也是派生周期", target, base)
+	}
+	if to <= from || to%from != 0 {
+		return fmt.Errorf("派生周期 %s 不是基础周期 %s 的整数倍", target, base)
+	}
+	return nil
+}
+
+// limitKlines 按 KlineLimit 截取最近的K线
+func (o Options) limitKlines(klines []Kline) []Kline {
+	if o.KlineLimit > 0 && len(klines) > o.KlineLimit {
+		return klines[len(klines)-o.KlineLimit:]
+	}
+	return klines
+}
+
+// shortestTimeframe 返回周期最短的一项
+func shortestTimeframe(timeframes []string) string {
+	sorted := sortedTimeframes(timeframes)
+	if len(sorted) == 0 {
+		return ""
+	}
+	return sorted[0]
+}
+
+// sortedTimeframes 按周期时长升序排列（不修改入参）
+func sortedTimeframes(timeframes []string) []string {
+	sorted := append([]string(nil), timeframes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		di, _ := intervalDuration(sorted[i])
+		dj, _ := intervalDuration(sorted[j])
+		return di < dj
+	})
+	return sorted
+}
@@ -0,0 +1,79 @@
+package market
+
+import "math"
+
+// K线形态名称
+const (
+	PatternDoji             = "doji"              // 十字星（中性）
+	PatternBullishEngulfing = "bullish_engulfing" // 看涨吞没
+	PatternBearishEngulfing = "bearish_engulfing" // 看跌吞没
+	PatternHammer           = "hammer"            // 锤子线（长下影）
+	PatternShootingStar     = "shooting_star"     // 射击之星（长上影）
+)
+
+// 形态识别阈值，均以K线振幅(最高-最低)或实体为基准
+var (
+	// PatternLookback 扫描最近多少根K线
+	PatternLookback = 3
+	// DojiBodyRatio 实体不超过振幅的该比例视为十字星
+	DojiBodyRatio = 0.1
+	// HammerBodyRatio 锤子线/射击之星的实体不超过振幅的该比例
+	HammerBodyRatio = 0.35
+	// HammerWickMultiple 锤子线下影线(射击之星上影线)至少为实体的该倍数
+	HammerWickMultiple = 2.0
+	// HammerOppositeWickRatio 锤子线上影线(射击之星下影线)不超过振幅的该比例
+	HammerOppositeWickRatio = 0.1
+)
+
+// Pattern 识别出的K线形态
+type Pattern struct {
+	Name    string `json:"name"`    // 形态名称，见 Pattern* 常量
+	Index   int    `json:"index"`   // 形态最后一根K线在输入切片中的下标
+	Bullish bool   `json:"bullish"` // 是否看涨（十字星为中性，记为 false）
+}
+
+// DetectPatterns 扫描最近 PatternLookback 根K线，识别十字星、吞没、锤子线与射击之星
+// 只根据K线自身比例判断，不校验前置趋势；结果按下标升序，同一根K线可能匹配多个形态
+func DetectPatterns(klines []Kline) []Pattern {
+	var patterns []Pattern
+	start := len(klines) - PatternLookback
+	if start < 0 {
+		start = 0
+	}
+
+	for i := start; i < len(klines); i++ {
+		k := klines[i]
+		rng := k.High - k.Low
+		if rng <= 0 {
+			continue
+		}
+		body := math.Abs(k.Close - k.Open)
+		upperWick := k.High - math.Max(k.Open, k.Close)
+		lowerWick := math.Min(k.Open, k.Close) - k.Low
+
+		if body <= DojiBodyRatio*rng {
+			patterns = append(patterns, Pattern{Name: PatternDoji, Index: i})
+		}
+		if body <= HammerBodyRatio*rng && upperWick <= HammerOppositeWickRatio*rng && lowerWick >= HammerWickMultiple*body && lowerWick > 0 {
+			patterns = append(patterns, Pattern{Name: PatternHammer, Index: i, Bullish: true})
+		}
+		if body <= HammerBodyRatio*rng && lowerWick <= HammerOppositeWickRatio*rng && upperWick >= HammerWickMultiple*body && upperWick > 0 {
+			patterns = append(patterns, Pattern{Name: PatternShootingStar, Index: i})
+		}
+
+		if i == 0 {
+			continue
+		}
+		prev := klines[i-1]
+		prevBody := math.Abs(prev.Close - prev.Open)
+		switch {
+		case prev.Close < prev.Open && k.Close > k.Open &&
+			k.Open <= prev.Close && k.Close >= prev.Open && body > prevBody:
+			patterns = append(patterns, Pattern{Name: PatternBullishEngulfing, Index: i, Bullish: true})
+		case prev.Close > prev.Open && k.Close < k.Open &&
+			k.Open >= prev.Close && k.Close <= prev.Open && body > prevBody:
+			patterns = append(patterns, Pattern{Name: PatternBearishEngulfing, Index: i})
+		}
+	}
+	return patterns
+}
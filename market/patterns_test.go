@@ -0,0 +1,44 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ohlc 构造只含开高低收的K线
+func ohlc(open, high, low, close float64) Kline {
+	return Kline{Open: open, High: high, Low: low, Close: close, Volume: 1}
+}
+
+func TestDetectPatterns(t *testing.T) {
+	neutral := ohlc(100, 106, 94, 105) // 实体占振幅约42%，不匹配任何形态
+	tests := []struct {
+		name    string
+		candles []Kline
+		want    []Pattern
+	}{
+		{"十字星", []Kline{ohlc(100, 105, 95, 100.5)}, []Pattern{{Name: PatternDoji, Index: 2}}},
+		{"锤子线", []Kline{ohlc(100, 101.2, 94, 101)}, []Pattern{{Name: PatternHammer, Index: 2, Bullish: true}}},
+		{"射击之星", []Kline{ohlc(101, 107, 99.8, 100)}, []Pattern{{Name: PatternShootingStar, Index: 2}}},
+		{"看涨吞没", []Kline{ohlc(102, 102.5, 99.5, 100), ohlc(99.5, 103.5, 99, 103)},
+			[]Pattern{{Name: PatternBullishEngulfing, Index: 3, Bullish: true}}},
+		{"看跌吞没", []Kline{ohlc(100, 102.5, 99.5, 102), ohlc(102.5, 103, 98.5, 99)},
+			[]Pattern{{Name: PatternBearishEngulfing, Index: 3}}},
+		{"无形态", []Kline{neutral}, nil},
+		{"振幅为0跳过", []Kline{ohlc(100, 100, 100, 100)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			klines := append([]Kline{neutral, neutral}, tt.candles...)
+			if got := DetectPatterns(klines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectPatterns = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// 超出 PatternLookback 的早期形态不再报告
+	klines := []Kline{ohlc(100, 105, 95, 100.5), neutral, neutral, neutral}
+	if got := DetectPatterns(klines); got != nil {
+		t.Errorf("lookback 之外的十字星被识别: %+v", got)
+	}
+}
@@ -0,0 +1,149 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PrecisionCacheTTL 交易对价格精度缓存的有效期，过期后重新查询 exchangeInfo
+var PrecisionCacheTTL = time.Hour
+
+// SymbolMissCacheTTL exchangeInfo 中不存在的交易对的缓存有效期，较短以便及时识别新上线的交易对
+var SymbolMissCacheTTL = time.Minute
+
+// PrecisionErrorCacheTTL exchangeInfo 查询失败后的冷却时间，期间直接返回上次的错误而不再请求
+var PrecisionErrorCacheTTL = 10 * time.Second
+
+// precisionEntry 单个交易对的缓存信息；missing 表示 exchangeInfo 中不存在该交易对
+// 查询失败记录在 precisionErr 中，PrecisionErrorCacheTTL 内不再重新查询
+type precisionEntry struct {
+	precision int
+	status    string // exchangeInfo 中的交易状态，如 TRADING、SETTLING
+	missing   bool
+	fetchedAt time.Time
+}
+
+var (
+	precisionCache sync.Map   // symbol -> precisionEntry
+	precisionMu    sync.Mutex // 串行化 exchangeInfo 查询，避免并发调用重复请求
+
+	// 最近一次 exchangeInfo 查询失败的错误与时间，由 precisionMu 保护
+	precisionErr   error
+	precisionErrAt time.Time
+)
+
+// getSymbolPrecision 返回交易对的价格精度(exchangeInfo 中的 pricePrecision)
+// 一次 exchangeInfo 查询会缓存全部交易对的精度，缓存在 PrecisionCacheTTL 内有效
+func getSymbolPrecision(symbol string) (int, error) {
+	entry, err := lookupSymbol(context.Background(), symbol)
+	if err != nil {
+		return 0, err
+	}
+	if entry.missing {
+		return 0, fmt.Errorf("exchangeInfo 中不存在交易对 %s", Normalize(symbol))
+	}
+	return entry.precision, nil
+}
+
+// ErrUnknownSymbol 交易对不存在于合约 exchangeInfo 中，或已下架(状态不是 TRADING)
+var ErrUnknownSymbol = errors.New("unknown symbol")
+
+// symbolExists 根据缓存的 exchangeInfo 判断合约交易对是否存在且处于交易状态
+// exchangeInfo 查询失败(含 ctx 取消)时返回 error，此时无法判断
+func symbolExists(ctx context.Context, symbol string) (bool, error) {
+	entry, err := lookupSymbol(ctx, symbol)
+	if err != nil {
+		return false, err
+	}
+	if entry.missing {
+		return false, nil
+	}
+	return entry.status == "" || entry.status == "TRADING", nil
+}
+
+// lookupSymbol 返回交易对的缓存信息，缓存缺失或过期时刷新 exchangeInfo
+func lookupSymbol(ctx context.Context, symbol string) (precisionEntry, error) {
+	symbol = Normalize(symbol)
+	if entry, ok := cachedPrecision(symbol); ok {
+		return entry, nil
+	}
+
+	precisionMu.Lock()
+	defer precisionMu.Unlock()
+	// 等锁期间其他 goroutine 可能已完成刷新
+	if entry, ok := cachedPrecision(symbol); ok {
+		return entry, nil
+	}
+
+	fetchedAt := now()
+	if precisionErr != nil && fetchedAt.Sub(precisionErrAt) < PrecisionErrorCacheTTL {
+		return precisionEntry{}, fmt.Errorf("获取交易对精度失败(冷却中): %w", precisionErr)
+	}
+	if err := refreshSymbolPrecision(ctx, fetchedAt); err != nil {
+		// 调用方 ctx 取消/超时不代表 exchangeInfo 不可用，不进入冷却
+		if ctx.Err() == nil {
+			precisionErr, precisionErrAt = err, fetchedAt
+		}
+		return precisionEntry{}, fmt.Errorf("获取交易对精度失败: %w", err)
+	}
+	precisionErr = nil
+	if entry, ok := cachedPrecision(symbol); ok {
+		return entry, nil
+	}
+	entry := precisionEntry{missing: true, fetchedAt: fetchedAt}
+	precisionCache.Store(symbol, entry)
+	return entry, nil
+}
+
+// cachedPrecision 读取未过期的缓存项，不存在的交易对按 SymbolMissCacheTTL 过期
+func cachedPrecision(symbol string) (precisionEntry, bool) {
+	v, ok := precisionCache.Load(symbol)
+	if !ok {
+		return precisionEntry{}, false
+	}
+	entry := v.(precisionEntry)
+	ttl := PrecisionCacheTTL
+	if entry.missing {
+		ttl = SymbolMissCacheTTL
+	}
+	if now().Sub(entry.fetchedAt) > ttl {
+		return precisionEntry{}, false
+	}
+	return entry, true
+}
+
+// refreshSymbolPrecision 查询 /fapi/v1/exchangeInfo 并缓存所有交易对的价格精度与交易状态
+func refreshSymbolPrecision(ctx context.Context, fetchedAt time.Time) error {
+	body, err := doRequest(ctx, fmt.Sprintf("%s/fapi/v1/exchangeInfo", BaseURL))
+	if err != nil {
+		return err
+	}
+	var info ExchangeInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return fmt.Errorf("parse exchangeInfo failed: %w", err)
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol == "" || s.PricePrecision < 0 {
+			continue
+		}
+		precisionCache.Store(s.Symbol, precisionEntry{precision: s.PricePrecision, status: s.Status, fetchedAt: fetchedAt})
+	}
+	return nil
+}
+
+// formatOptionsFor 返回交易对的默认格式化选项：缓存中有价格精度时按精度输出价格，
+// 指标至少比价格多保留1位；只读缓存(由 Get 的交易对预检填充)，未命中时使用 DefaultFormatOptions，不发起网络请求
+func formatOptionsFor(symbol string) FormatOptions {
+	opts := DefaultFormatOptions()
+	entry, ok := cachedPrecision(Normalize(symbol))
+	if !ok || entry.missing {
+		return opts
+	}
+	opts.PriceDecimals = entry.precision
+	opts.IndicatorDecimals = maxInt(opts.IndicatorDecimals, entry.precision+1)
+	return opts
+}
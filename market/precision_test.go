@@ -0,0 +1,171 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetUnknownSymbolSkipsKlineFetch(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+
+	tests := []struct {
+		name   string
+		symbol string
+	}{
+		{"不存在", "NOPEUSDT"},
+		{"已下架", "OLDUSDT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &fakeSource{klines: testKlines(100, 3*time.Minute)}
+			opts := DefaultOptions()
+			opts.Source = src
+
+			_, err := GetWithOptions(context.Background(), tt.symbol, opts)
+			if !errors.Is(err, ErrUnknownSymbol) {
+				t.Fatalf("err = %v, want ErrUnknownSymbol", err)
+			}
+			if n := src.calls.Load(); n != 0 {
+				t.Errorf("K线数据源被调用 %d 次, want 0", n)
+			}
+		})
+	}
+}
+
+func TestSymbolExistsCache(t *testing.T) {
+	var requests atomic.Int32
+	failing := atomic.Bool{}
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": countingHandler(&requests, func(w http.ResponseWriter, r *http.Request) {
+			if failing.Load() {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			jsonHandler(testExchangeInfo)(w, r)
+		}),
+	})
+
+	clock := useFakeClock(t, time.Unix(1700000000, 0))
+	ctx := context.Background()
+
+	// 查询失败在 PrecisionErrorCacheTTL 内直接返回错误，冷却结束后重新查询
+	failing.Store(true)
+	if _, err := symbolExists(ctx, "BTCUSDT"); err == nil {
+		t.Fatal("exchangeInfo 失败时应返回错误")
+	}
+	failing.Store(false)
+	if _, err := symbolExists(ctx, "BTCUSDT"); err == nil || requests.Load() != 1 {
+		t.Fatalf("冷却期内应返回缓存的错误且不发请求, err = %v, 请求 %d 次", err, requests.Load())
+	}
+	clock.Advance(PrecisionErrorCacheTTL)
+	if ok, err := symbolExists(ctx, "BTCUSDT"); err != nil || !ok {
+		t.Fatalf("symbolExists(BTCUSDT) = %v, %v; want true, nil", ok, err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("exchangeInfo 请求 %d 次, want 2", n)
+	}
+
+	// 不存在的交易对在 SymbolMissCacheTTL 内命中缓存，过期后重新查询
+	if ok, _ := symbolExists(ctx, "NEWUSDT"); ok {
+		t.Fatal("NEWUSDT 不应存在")
+	}
+	if ok, _ := symbolExists(ctx, "NEWUSDT"); ok || requests.Load() != 3 {
+		t.Fatalf("未过期的缺失项应命中缓存, 请求 %d 次", requests.Load())
+	}
+	clock.Advance(SymbolMissCacheTTL + time.Second)
+	symbolExists(ctx, "NEWUSDT")
+	if n := requests.Load(); n != 4 {
+		t.Fatalf("缺失项过期后应重新查询, 请求 %d 次, want 4", n)
+	}
+
+	// 已取消的 ctx 直接返回错误，且不进入冷却
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	resetPrecisionCache()
+	if _, err := symbolExists(cancelled, "BTCUSDT"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if ok, err := symbolExists(ctx, "BTCUSDT"); err != nil || !ok {
+		t.Errorf("ctx 取消后 symbolExists(BTCUSDT) = %v, %v; want true, nil", ok, err)
+	}
+}
+
+func TestSymbolPrecisionFromExchangeInfo(t *testing.T) {
+	var requests atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": countingHandler(&requests, jsonHandler(`{"symbols":[`+
+			`{"symbol":"BTCUSDT","status":"TRADING","pricePrecision":2},`+
+			`{"symbol":"1000SHIBUSDT","status":"TRADING","pricePrecision":7}]}`)),
+	})
+
+	tests := []struct {
+		symbol        string
+		wantPrecision int
+		wantErr       bool
+		wantFormat    FormatOptions
+	}{
+		{"BTCUSDT", 2, false, FormatOptions{PriceDecimals: 2, IndicatorDecimals: 3}},
+		{"1000SHIBUSDT", 7, false, FormatOptions{PriceDecimals: 7, IndicatorDecimals: 8}},
+		{"NOPEUSDT", 0, true, DefaultFormatOptions()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			got, err := getSymbolPrecision(tt.symbol)
+			if (err != nil) != tt.wantErr || got != tt.wantPrecision {
+				t.Errorf("getSymbolPrecision = %d, %v; want %d, wantErr %v", got, err, tt.wantPrecision, tt.wantErr)
+			}
+			if got := formatOptionsFor(tt.symbol); got != tt.wantFormat {
+				t.Errorf("formatOptionsFor = %+v, want %+v", got, tt.wantFormat)
+			}
+		})
+	}
+
+	// 一次 exchangeInfo 查询缓存全部交易对；不存在的交易对首次查询时刷新一次，之后命中未命中缓存
+	if n := requests.Load(); n != 2 {
+		t.Errorf("exchangeInfo 请求 %d 次, want 2", n)
+	}
+	if out := Format(&Data{Symbol: "1000SHIBUSDT", CurrentPrice: 0.0000123}); !strings.Contains(out, "当前价格 = 0.0000123,") {
+		t.Errorf("Format 未使用交易对精度:\n%s", out)
+	}
+}
+
+func TestFormatReadsPrecisionCacheOnly(t *testing.T) {
+	var requests atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": countingHandler(&requests, jsonHandler(`{"symbols":[`+
+			`{"symbol":"1000SHIBUSDT","status":"TRADING","pricePrecision":7}]}`)),
+		"/fapi/v1/klines": klinesHandler(100),
+	})
+	data := &Data{Symbol: "1000SHIBUSDT", CurrentPrice: 0.0000123}
+
+	// 缓存未命中时使用默认小数位，不发起请求
+	if got := formatOptionsFor("1000SHIBUSDT"); got != DefaultFormatOptions() {
+		t.Errorf("formatOptionsFor(冷缓存) = %+v, want %+v", got, DefaultFormatOptions())
+	}
+	if out := Format(data); !strings.Contains(out, "当前价格 = 0.00,") {
+		t.Errorf("冷缓存时 Format 应使用默认小数位:\n%s", out)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("Format 发起了 %d 次 exchangeInfo 请求, want 0", n)
+	}
+
+	// Get 的交易对预检预热缓存，之后 Format 按交易对精度输出
+	opts := DefaultOptions()
+	opts.Source = &fakeSource{klines: testKlines(100, 3*time.Minute)}
+	if _, err := GetWithOptions(context.Background(), "1000SHIBUSDT", opts); err != nil {
+		t.Fatalf("GetWithOptions() error = %v", err)
+	}
+	if out := Format(data); !strings.Contains(out, "当前价格 = 0.0000123,") {
+		t.Errorf("Get 之后 Format 未使用交易对精度:\n%s", out)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("exchangeInfo 请求 %d 次, want 1", n)
+	}
+}
@@ -0,0 +1,30 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// GetPrice 通过合约 /fapi/v1/ticker/price 接口获取最新成交价
+// 只需要报价时使用，避免 Get 的多周期K线获取与指标计算；与其他 REST 接口共享 BaseURL、HTTPClient、限速与重试
+func GetPrice(ctx context.Context, symbol string) (float64, error) {
+	symbol = Normalize(symbol)
+	url := fmt.Sprintf("%s/fapi/v1/ticker/price?symbol=%s", BaseURL, symbol)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	var ticker PriceTicker
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return 0, err
+	}
+	price, err := strconv.ParseFloat(ticker.Price, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse price failed: %w", err)
+	}
+	return price, nil
+}
@@ -0,0 +1,44 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGetPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		want    float64
+		wantErr bool
+	}{
+		{"正常", `{"symbol":"BTCUSDT","price":"43250.10","time":1700000000000}`, http.StatusOK, 43250.10, false},
+		{"价格格式错误", `{"symbol":"BTCUSDT","price":"abc"}`, http.StatusOK, 0, true},
+		{"无效交易对", `{"code":-1121,"msg":"Invalid symbol."}`, http.StatusBadRequest, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSymbol string
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/ticker/price": func(w http.ResponseWriter, r *http.Request) {
+					gotSymbol = r.URL.Query().Get("symbol")
+					w.WriteHeader(tt.status)
+					jsonHandler(tt.body)(w, r)
+				},
+			})
+
+			price, err := GetPrice(context.Background(), "btc")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPrice err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if price != tt.want {
+				t.Errorf("GetPrice = %v, want %v", price, tt.want)
+			}
+			if gotSymbol != "BTCUSDT" {
+				t.Errorf("请求的 symbol = %q, want BTCUSDT", gotSymbol)
+			}
+		})
+	}
+}
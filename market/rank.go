@@ -0,0 +1,54 @@
+package market
+
+import (
+	"math"
+	"sort"
+)
+
+// RankSymbols 按指定指标对多个交易对的市场数据排序，返回排序后的新切片（不修改入参）
+// metric: 从 Data 中提取排序指标，例如 func(d *Data) float64 { return d.PriceChange1h }
+// descending: true 为从大到小，false 为从小到大
+// 排序规则保证结果稳定可复现：
+//   - 指标相等时按 Symbol 字典序升序排列；
+//   - 指标为 NaN 的交易对无论升降序都排在末尾（其间同样按 Symbol 排列）；
+//   - nil 元素被忽略。
+func RankSymbols(datas []*Data, metric func(*Data) float64, descending bool) []*Data {
+	type ranked struct {
+		data  *Data
+		value float64
+	}
+
+	items := make([]ranked, 0, len(datas))
+	for _, d := range datas {
+		if d == nil {
+			continue
+		}
+		items = append(items, ranked{data: d, value: metric(d)})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		aNaN, bNaN := math.IsNaN(a.value), math.IsNaN(b.value)
+		switch {
+		case aNaN && bNaN:
+			return a.data.Symbol < b.data.Symbol
+		case aNaN:
+			return false
+		case bNaN:
+			return true
+		case a.value != b.value:
+			if descending {
+				return a.value > b.value
+			}
+			return a.value < b.value
+		default:
+			return a.data.Symbol < b.data.Symbol
+		}
+	})
+
+	result := make([]*Data, len(items))
+	for i, item := range items {
+		result[i] = item.data
+	}
+	return result
+}
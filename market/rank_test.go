@@ -0,0 +1,48 @@
+package market
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRankSymbols(t *testing.T) {
+	nan := math.NaN()
+	datas := []*Data{
+		{Symbol: "SOLUSDT", PriceChange1h: nan},
+		{Symbol: "ETHUSDT", PriceChange1h: 2},
+		nil,
+		{Symbol: "BTCUSDT", PriceChange1h: 2},
+		{Symbol: "ADAUSDT", PriceChange1h: nan},
+		{Symbol: "XRPUSDT", PriceChange1h: -1},
+		{Symbol: "BNBUSDT", PriceChange1h: 5},
+	}
+	metric := func(d *Data) float64 { return d.PriceChange1h }
+
+	tests := []struct {
+		name       string
+		descending bool
+		want       []string
+	}{
+		// 指标相等按 Symbol 升序，NaN 排在末尾且同样按 Symbol 排列
+		{"降序", true, []string{"BNBUSDT", "BTCUSDT", "ETHUSDT", "XRPUSDT", "ADAUSDT", "SOLUSDT"}},
+		{"升序", false, []string{"XRPUSDT", "BTCUSDT", "ETHUSDT", "BNBUSDT", "ADAUSDT", "SOLUSDT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := RankSymbols(datas, metric, tt.descending)
+			got := make([]string, len(ranked))
+			for i, d := range ranked {
+				got[i] = d.Symbol
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RankSymbols(descending=%v) = %v, want %v", tt.descending, got, tt.want)
+			}
+		})
+	}
+
+	// 不修改入参顺序
+	if datas[0].Symbol != "SOLUSDT" || datas[2] != nil || datas[6].Symbol != "BNBUSDT" {
+		t.Error("RankSymbols 修改了入参切片")
+	}
+}
@@ -0,0 +1,53 @@
+package market
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultRequestRate REST 请求默认的平均速率上限（次/秒）
+// Binance 合约接口按IP计算请求权重(默认2400/分钟)，默认值留有余量
+const DefaultRequestRate = 20.0
+
+// DefaultRequestBurst 默认的令牌桶容量，即空闲后允许瞬时连续发出的请求数
+const DefaultRequestBurst = 10
+
+// RequestRate REST 请求的平均速率上限（次/秒），rate.Inf 表示不限速
+// 在首个 REST 请求前修改生效，运行中调整请使用 SetRequestRate
+var RequestRate = rate.Limit(DefaultRequestRate)
+
+// RequestBurst 令牌桶容量，生效时机同 RequestRate，<1 按1处理
+var RequestBurst = DefaultRequestBurst
+
+var (
+	restLimiterOnce sync.Once
+	restLimiter     *rate.Limiter
+)
+
+// limiter 返回所有 REST 请求共享的令牌桶，首次使用时按 RequestRate/RequestBurst 创建
+// rate.Limiter 使用单调时钟计时，不受包内可替换时钟 now 的影响
+func limiter() *rate.Limiter {
+	restLimiterOnce.Do(func() {
+		restLimiter = rate.NewLimiter(RequestRate, max(RequestBurst, 1))
+	})
+	return restLimiter
+}
+
+// SetRequestRate 设置 REST 请求速率(次/秒)与突发容量，rps<=0 表示不限速，burst<1 按1处理
+// 可在运行中并发调用
+func SetRequestRate(rps float64, burst int) {
+	limit := rate.Limit(rps)
+	if rps <= 0 {
+		limit = rate.Inf
+	}
+	l := limiter()
+	l.SetLimit(limit)
+	l.SetBurst(max(burst, 1))
+}
+
+// waitForRequest 阻塞直到允许发出下一个 REST 请求；ctx 取消或等待将超过 ctx 截止时间时返回错误
+func waitForRequest(ctx context.Context) error {
+	return limiter().Wait(ctx)
+}
@@ -0,0 +1,109 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRequestsSpacedByLimit(t *testing.T) {
+	var requests atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/openInterest": countingHandler(&requests, jsonHandler(`{"openInterest":"1","symbol":"BTCUSDT"}`)),
+	})
+	// 冻结包内时钟：限速器使用单调时钟，不应因此停止补充令牌
+	useFakeClock(t, time.Unix(1700000000, 0))
+
+	tests := []struct {
+		name  string
+		rps   float64
+		burst int
+		calls int
+	}{
+		{"50次/秒 容量1", 50, 1, 6},
+		{"100次/秒 容量3", 100, 3, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRequestRate(t, tt.rps, tt.burst)
+			requests.Store(0)
+
+			begin := time.Now()
+			for i := 0; i < tt.calls; i++ {
+				if _, err := doRequest(context.Background(), BaseURL+"/fapi/v1/openInterest"); err != nil {
+					t.Fatalf("第 %d 次请求: %v", i+1, err)
+				}
+			}
+			elapsed := time.Since(begin)
+			if n := int(requests.Load()); n != tt.calls {
+				t.Fatalf("服务端收到 %d 次请求, want %d", n, tt.calls)
+			}
+
+			// 首批 burst 个请求立即发出，之后每个请求间隔 1/rps；允许少量计时误差
+			interval := time.Duration(float64(time.Second) / tt.rps)
+			want := time.Duration(tt.calls-tt.burst) * interval
+			if elapsed < want-5*time.Millisecond {
+				t.Errorf("%d 次请求耗时 %v，want 至少 %v", tt.calls, elapsed, want)
+			}
+			if elapsed > want+time.Second {
+				t.Errorf("%d 次请求耗时 %v，远超限速间隔 %v", tt.calls, elapsed, want)
+			}
+		})
+	}
+}
+
+func TestWaitForRequestHonorsContext(t *testing.T) {
+	useRequestRate(t, 0.001, 1)
+	if err := waitForRequest(context.Background()); err != nil {
+		t.Fatalf("首个令牌应立即取得: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitForRequest(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx 取消后 wait = %v, want context.Canceled", err)
+	}
+
+	// 下一个令牌远在截止时间之后，立即返回错误而不是阻塞到超时
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	begin := time.Now()
+	if err := waitForRequest(ctx); err == nil {
+		t.Error("令牌在截止时间前不可用时应返回错误")
+	}
+	if elapsed := time.Since(begin); elapsed > 100*time.Millisecond {
+		t.Errorf("等待 %v 后才返回", elapsed)
+	}
+}
+
+func TestSetRequestRateConcurrent(t *testing.T) {
+	useRequestRate(t, 1000, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetRequestRate(1000, 100)
+		}()
+		go func() {
+			defer wg.Done()
+			waitForRequest(context.Background())
+		}()
+	}
+	wg.Wait()
+	if limit, burst := limiter().Limit(), limiter().Burst(); limit != 1000 || burst != 100 {
+		t.Errorf("限速 = %v, %v; want 1000, 100", limit, burst)
+	}
+
+	SetRequestRate(0, 0)
+	if limit, burst := limiter().Limit(), limiter().Burst(); limit != rate.Inf || burst != 1 {
+		t.Errorf("SetRequestRate(0, 0) 后限速 = %v, %v; want Inf, 1", limit, burst)
+	}
+}
@@ -0,0 +1,55 @@
+package market
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resample 将 from 周期的K线聚合为 to 周期的K线（to 须为 from 的整数倍）：
+// 开盘价取第一根、收盘价取最后一根、最高/最低价取极值，成交量、成交额、笔数与主动买入量求和
+// 区间按 Unix 纪元(UTC)对齐，因此适用于日及以下周期；1w/1M 与 Binance 的周/月划分不一致。
+// 首个区间若缺少开头的K线（数据起点落在区间中间）则丢弃，最后一个区间即使未走完也保留，
+// 与交易所的“当前K线”语义一致
+func Resample(klines []Kline, from, to time.Duration) ([]Kline, error) {
+	if from <= 0 || to <= from || to%from != 0 {
+		return nil, fmt.Errorf("无法将 %v K线重采样为 %v: 目标周期须为源周期的整数倍", from, to)
+	}
+	toMs := to.Milliseconds()
+
+	// 跳过不完整的首个区间
+	start := 0
+	if len(klines) > 0 && klines[0].OpenTime%toMs != 0 {
+		first := klines[0].OpenTime - klines[0].OpenTime%toMs
+		for start < len(klines) && klines[start].OpenTime-klines[start].OpenTime%toMs == first {
+			start++
+		}
+	}
+
+	var result []Kline
+	for _, k := range klines[start:] {
+		bucket := k.OpenTime - k.OpenTime%toMs
+		if len(result) == 0 || result[len(result)-1].OpenTime != bucket {
+			result = append(result, Kline{
+				OpenTime:  bucket,
+				CloseTime: bucket + toMs - 1,
+				Open:      k.Open,
+				High:      k.High,
+				Low:       k.Low,
+			})
+		}
+		agg := &result[len(result)-1]
+		if k.High > agg.High {
+			agg.High = k.High
+		}
+		if k.Low < agg.Low {
+			agg.Low = k.Low
+		}
+		agg.Close = k.Close
+		agg.Volume += k.Volume
+		agg.QuoteVolume += k.QuoteVolume
+		agg.Trades += k.Trades
+		agg.TakerBuyBaseVolume += k.TakerBuyBaseVolume
+		agg.TakerBuyQuoteVolume += k.TakerBuyQuoteVolume
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResample(t *testing.T) {
+	klines := testKlines(13, 3*time.Minute)
+	for i := range klines {
+		klines[i].Trades = 2
+		klines[i].QuoteVolume = klines[i].Volume * klines[i].Close
+	}
+
+	// 10根3m → 1根30m：开盘取第一根、收盘取最后一根、最高/最低取极值、量求和
+	want := Kline{OpenTime: 0, CloseTime: 30*60*1000 - 1, Open: klines[0].Open, High: klines[0].High, Low: klines[0].Low, Close: klines[9].Close}
+	for _, k := range klines[:10] {
+		want.High = max(want.High, k.High)
+		want.Low = min(want.Low, k.Low)
+		want.Volume += k.Volume
+		want.QuoteVolume += k.QuoteVolume
+		want.Trades += k.Trades
+	}
+
+	got, err := Resample(klines[:10], 3*time.Minute, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("Resample: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("得到 %d 根30m K线, want 1", len(got))
+	}
+	if got[0] != want {
+		t.Errorf("30m K线 = %+v, want %+v", got[0], want)
+	}
+
+	// 起点落在区间中间时丢弃首个区间，未走完的最后一个区间保留
+	got, err = Resample(klines[3:], 3*time.Minute, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("Resample: %v", err)
+	}
+	if len(got) != 1 || got[0].OpenTime != klines[10].OpenTime || got[0].Open != klines[10].Open || got[0].Close != klines[12].Close {
+		t.Errorf("Resample(起点不对齐) = %+v, want 仅保留从第10根开始的区间", got)
+	}
+
+	for _, tt := range []struct{ from, to time.Duration }{
+		{3 * time.Minute, 10 * time.Minute},
+		{15 * time.Minute, 3 * time.Minute},
+		{0, time.Hour},
+	} {
+		if _, err := Resample(klines, tt.from, tt.to); err == nil {
+			t.Errorf("Resample(%v → %v) 应返回错误", tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package market
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBaseURL Binance U本位合约 REST 主网地址
+const defaultBaseURL = "https://fapi.binance.com"
+
+// testnetBaseURL Binance U本位合约 REST 测试网地址
+const testnetBaseURL = "https://testnet.binancefuture.com"
+
+// BaseURL 合约 REST 接口(K线、OI、资金费率等)的基础地址，不含末尾斜杠
+// 可通过 SetBaseURL 指向测试网(https://testnet.binancefuture.com)或本地模拟服务
+var BaseURL = defaultBaseURL
+
+// 主网 WebSocket 地址：组合流基础地址与 WebSocket API 地址
+const (
+	defaultStreamBaseURL = "wss://fstream.binance.com"
+	defaultWSAPIURL      = "wss://ws-fapi.binance.com/ws-fapi/v1"
+)
+
+// StreamBaseURL 合约行情 WebSocket(组合流 /stream)的基础地址，由 SetBaseURL 随 BaseURL 一起切换
+var StreamBaseURL = defaultStreamBaseURL
+
+// WSAPIURL 合约 WebSocket API 地址，由 SetBaseURL 随 BaseURL 一起切换
+var WSAPIURL = defaultWSAPIURL
+
+// SetBaseURL 设置 REST 基础地址，自动去除首尾空白与末尾斜杠；传入空字符串时恢复主网地址
+// WebSocket 地址随之切换：主网与测试网使用 Binance 对应的 WebSocket 域名，
+// 其他地址(如本地模拟服务)使用同一主机，http/https 分别替换为 ws/wss。应在发起请求与建立连接前调用
+func SetBaseURL(url string) {
+	url = strings.TrimRight(strings.TrimSpace(url), "/")
+	if url == "" {
+		url = defaultBaseURL
+	}
+	BaseURL = url
+	StreamBaseURL, WSAPIURL = wsURLsFor(url)
+}
+
+// wsURLsFor 返回 REST 基础地址对应的组合流基础地址与 WebSocket API 地址
+func wsURLsFor(restURL string) (stream, api string) {
+	switch restURL {
+	case defaultBaseURL:
+		return defaultStreamBaseURL, defaultWSAPIURL
+	case testnetBaseURL:
+		return "wss://stream.binancefuture.com", "wss://testnet.binancefuture.com/ws-fapi/v1"
+	}
+	base := restURL
+	switch {
+	case strings.HasPrefix(base, "https://"):
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	case strings.HasPrefix(base, "http://"):
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base, base + "/ws-fapi/v1"
+}
+
+// SpotBaseURL 现货 REST 接口基础地址，Options.Market 为 Spot 时用于获取K线
+var SpotBaseURL = "https://api.binance.com"
+
+// defaultHTTPTimeout REST 请求默认超时，避免 Binance 连接挂起导致 Get 永久阻塞
+const defaultHTTPTimeout = 10 * time.Second
+
+// HTTPClient 行情 REST 请求(OI、资金费率等)使用的HTTP客户端
+var HTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
+
+// SetHTTPClient 替换 REST 请求使用的HTTP客户端（自定义 Transport、代理或超时）
+// 传入 nil 时恢复默认客户端；应在发起请求前调用
+func SetHTTPClient(c *http.Client) {
+	if c == nil {
+		c = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	HTTPClient = c
+}
+
+// MaxRetries REST 请求遇到网络错误或5xx响应时的最大重试次数（不含首次请求），4xx不重试
+var MaxRetries = 3
+
+// retryBaseDelay 首次重试前的基础等待时间，之后每次翻倍并叠加随机抖动
+var retryBaseDelay = 200 * time.Millisecond
+
+// doRequest 发起GET请求并返回响应体，对网络错误、5xx与429响应按指数退避重试
+// 429 响应按 Retry-After 等待后重试，418(IP被封禁)与其他4xx不重试；ctx 取消时立即返回
+// 总耗时(含重试)与最终失败上报到 Metrics
+func doRequest(ctx context.Context, url string) ([]byte, error) {
+	start := time.Now()
+	body, err := doRequestWithRetry(ctx, url)
+	observeFetch(endpointName(url), start, err)
+	return body, err
+}
+
+// doRequestWithRetry doRequest 的重试循环
+func doRequestWithRetry(ctx context.Context, url string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt <= MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := retryBaseDelay << (attempt - 1)
+			delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+			var rateErr *RateLimitError
+			if errors.As(lastErr, &rateErr) && rateErr.RetryAfter > delay {
+				delay = rateErr.RetryAfter
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		body, retry, err := doRequestOnce(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+		if !retry || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("请求失败(已重试%d次): %w", MaxRetries, lastErr)
+}
+
+// doRequestOnce 执行单次GET请求，retry 表示该错误是否值得重试
+func doRequestOnce(ctx context.Context, url string) (body []byte, retry bool, err error) {
+	// 每次请求（含重试）都先通过共享限速器，避免超出 Binance IP 权重限制
+	if err := waitForRequest(ctx); err != nil {
+		return nil, false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	body, err = readBody(resp.Body)
+	if errors.Is(err, ErrResponseTooLarge) {
+		return nil, false, err
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		return nil, resp.StatusCode == http.StatusTooManyRequests, &RateLimitError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now()),
+			Body:       string(body),
+		}
+	}
+	retry = resp.StatusCode >= 500
+	if apiErr := parseAPIError(body); apiErr != nil {
+		return nil, retry, apiErr
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, retry, fmt.Errorf("HTTP %d: %s", resp.StatusCode, body)
+	}
+	return body, false, nil
+}
+
+// maxResponseBytes 单个 REST 响应体的读取上限，防止异常或恶意服务端返回超大响应耗尽内存
+// 最大的正常响应为 exchangeInfo（约数MB）
+const maxResponseBytes = 16 << 20
+
+// ErrResponseTooLarge 响应体超过 maxResponseBytes
+var ErrResponseTooLarge = errors.New("响应体超过大小上限")
+
+// readBody 读取响应体，最多 maxResponseBytes 字节，超出时返回 ErrResponseTooLarge
+func readBody(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxResponseBytes {
+		return nil, fmt.Errorf("%w(%d 字节)", ErrResponseTooLarge, maxResponseBytes)
+	}
+	return body, nil
+}
+
+// RateLimitError Binance 返回 429(请求过于频繁) 或 418(IP 因持续超限被封禁)
+// RetryAfter 取自 Retry-After 响应头，缺失或无法解析时为0
+type RateLimitError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.StatusCode == http.StatusTeapot {
+		return fmt.Sprintf("Binance IP 已被封禁(HTTP 418)，%v 后解除: %s", e.RetryAfter, e.Body)
+	}
+	return fmt.Sprintf("Binance 请求频率超限(HTTP %d)，需等待 %v: %s", e.StatusCode, e.RetryAfter, e.Body)
+}
+
+// parseRetryAfter 解析 Retry-After 头：支持秒数或 HTTP 日期格式
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// APIError Binance 返回的错误响应，如 {"code":-1121,"msg":"Invalid symbol."}
+// 调用方可通过 errors.As 区分无效交易对等接口错误与真实的零值数据
+type APIError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Binance API 错误 %d: %s", e.Code, e.Msg)
+}
+
+// parseAPIError 识别响应体中的 code/msg 错误结构，非错误响应返回 nil
+// Binance 部分接口在 HTTP 200 时也可能返回错误结构，因此不依赖状态码判断
+func parseAPIError(body []byte) *APIError {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil
+	}
+	var payload struct {
+		Code *int    `json:"code"`
+		Msg  *string `json:"msg"`
+	}
+	if err := json.Unmarshal(trimmed, &payload); err != nil || payload.Code == nil || payload.Msg == nil {
+		return nil
+	}
+	// 少数接口以 code=200 表示成功
+	if *payload.Code == 0 || *payload.Code == 200 {
+		return nil
+	}
+	return &APIError{Code: *payload.Code, Msg: *payload.Msg}
+}
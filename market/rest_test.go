@@ -0,0 +1,300 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// restoreBaseURL 测试结束时恢复 REST/WebSocket 地址
+func restoreBaseURL(t *testing.T) {
+	prevBase, prevStream, prevAPI := BaseURL, StreamBaseURL, WSAPIURL
+	t.Cleanup(func() { BaseURL, StreamBaseURL, WSAPIURL = prevBase, prevStream, prevAPI })
+}
+
+func TestSetBaseURL(t *testing.T) {
+	restoreBaseURL(t)
+	tests := []struct {
+		in         string
+		wantBase   string
+		wantStream string
+		wantAPI    string
+	}{
+		{"", "https://fapi.binance.com", "wss://fstream.binance.com", "wss://ws-fapi.binance.com/ws-fapi/v1"},
+		{"https://testnet.binancefuture.com/", "https://testnet.binancefuture.com", "wss://stream.binancefuture.com", "wss://testnet.binancefuture.com/ws-fapi/v1"},
+		{" http://127.0.0.1:8080// ", "http://127.0.0.1:8080", "ws://127.0.0.1:8080", "ws://127.0.0.1:8080/ws-fapi/v1"},
+		{"https://mock.local", "https://mock.local", "wss://mock.local", "wss://mock.local/ws-fapi/v1"},
+	}
+	for _, tt := range tests {
+		SetBaseURL(tt.in)
+		if BaseURL != tt.wantBase || StreamBaseURL != tt.wantStream || WSAPIURL != tt.wantAPI {
+			t.Errorf("SetBaseURL(%q) = %q, %q, %q; want %q, %q, %q", tt.in,
+				BaseURL, StreamBaseURL, WSAPIURL, tt.wantBase, tt.wantStream, tt.wantAPI)
+		}
+	}
+}
+
+func TestSetBaseURLRoutesRESTAndStreams(t *testing.T) {
+	restoreBaseURL(t)
+	var upgrader websocket.Upgrader
+	streamed := make(chan struct{}, 1)
+	srv := useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/openInterest": jsonHandler(`{"openInterest":"123.5","symbol":"BTCUSDT"}`),
+		"/stream": func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			streamed <- struct{}{}
+			conn.Close()
+		},
+	})
+	SetBaseURL(srv.URL + "/")
+	if !strings.HasPrefix(StreamBaseURL, "ws://") {
+		t.Fatalf("StreamBaseURL = %q, want ws://...", StreamBaseURL)
+	}
+
+	body, err := doRequest(context.Background(), BaseURL+"/fapi/v1/openInterest?symbol=BTCUSDT")
+	if err != nil || !strings.Contains(string(body), "123.5") {
+		t.Fatalf("REST 请求未到达测试服务: %s, %v", body, err)
+	}
+
+	client := NewCombinedStreamsClient(1)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+	select {
+	case <-streamed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("组合流连接未到达测试服务")
+	}
+}
+
+func TestSetHTTPClientTimeout(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/openInterest": func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		},
+	})
+	prev := HTTPClient
+	SetHTTPClient(&http.Client{Timeout: time.Millisecond})
+	t.Cleanup(func() { HTTPClient = prev })
+
+	start := time.Now()
+	_, err := getOpenInterestData(context.Background(), "BTCUSDT")
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("err = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("超时请求耗时 %v，未按客户端超时返回", elapsed)
+	}
+
+	SetHTTPClient(nil)
+	if HTTPClient.Timeout != defaultHTTPTimeout {
+		t.Errorf("SetHTTPClient(nil) 后 Timeout = %v, want %v", HTTPClient.Timeout, defaultHTTPTimeout)
+	}
+}
+
+func TestDoRequestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		failures     int32
+		status       int
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{"失败两次后成功", 3, 2, http.StatusBadGateway, false, 3},
+		{"重试次数用尽", 1, 5, http.StatusServiceUnavailable, true, 2},
+		{"4xx不重试", 3, 5, http.StatusBadRequest, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/openInterest": func(w http.ResponseWriter, r *http.Request) {
+					if attempts.Add(1) <= tt.failures {
+						http.Error(w, "failed", tt.status)
+						return
+					}
+					fmt.Fprint(w, `{"openInterest":"1","symbol":"BTCUSDT"}`)
+				},
+			})
+			MaxRetries = tt.maxRetries
+
+			_, err := doRequest(context.Background(), BaseURL+"/fapi/v1/openInterest")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n := attempts.Load(); n != tt.wantAttempts {
+				t.Errorf("请求 %d 次, want %d", n, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestDoRequestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode int // 0 表示不应返回 APIError
+	}{
+		{"400无效交易对", http.StatusBadRequest, `{"code":-1121,"msg":"Invalid symbol."}`, -1121},
+		{"200也返回错误结构", http.StatusOK, `{"code":-1003,"msg":"Too many requests."}`, -1003},
+		{"code=200表示成功", http.StatusOK, `{"code":200,"msg":"success"}`, 0},
+		{"正常数组响应", http.StatusOK, `[]`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/klines": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, tt.body)
+				},
+			})
+
+			_, err := doRequest(context.Background(), BaseURL+"/fapi/v1/klines")
+			var apiErr *APIError
+			if got := errors.As(err, &apiErr); got != (tt.wantCode != 0) {
+				t.Fatalf("err = %v, want APIError: %v", err, tt.wantCode != 0)
+			}
+			if apiErr != nil && apiErr.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", apiErr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRateLimitErrorRetryAfter(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		retryAfter     string
+		wantRetryAfter time.Duration
+	}{
+		{"429 秒数", http.StatusTooManyRequests, "5", 5 * time.Second},
+		{"418 封禁", http.StatusTeapot, "120", 2 * time.Minute},
+		{"缺少响应头", http.StatusTooManyRequests, "", 0},
+		{"无法解析", http.StatusTooManyRequests, "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/klines": func(w http.ResponseWriter, r *http.Request) {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, `{"code":-1003,"msg":"Too many requests."}`)
+				},
+			})
+
+			_, err := doRequest(context.Background(), BaseURL+"/fapi/v1/klines")
+			var rateErr *RateLimitError
+			if !errors.As(err, &rateErr) {
+				t.Fatalf("err = %v, want *RateLimitError", err)
+			}
+			if rateErr.StatusCode != tt.status || rateErr.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("RateLimitError = {%d, %v}, want {%d, %v}",
+					rateErr.StatusCode, rateErr.RetryAfter, tt.status, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now); got != 30*time.Second {
+		t.Errorf("HTTP 日期 = %v, want 30s", got)
+	}
+	if got := parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now); got != 0 {
+		t.Errorf("已过去的日期 = %v, want 0", got)
+	}
+	if got := parseRetryAfter("-3", now); got != 0 {
+		t.Errorf("负数秒 = %v, want 0", got)
+	}
+}
+
+func TestTeapotNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/klines": countingHandler(&attempts, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	})
+	MaxRetries = 3
+
+	if _, err := doRequest(context.Background(), BaseURL+"/fapi/v1/klines"); err == nil {
+		t.Fatal("418 应返回错误")
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("418 请求 %d 次, want 1", n)
+	}
+}
+
+func TestResponseTooLarge(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantErr bool
+	}{
+		{"恰好达到上限", maxResponseBytes, false},
+		{"超出上限1字节", maxResponseBytes + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			useTestServer(t, map[string]http.HandlerFunc{
+				"/fapi/v1/exchangeInfo": countingHandler(&attempts, func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte(strings.Repeat(" ", tt.size)))
+				}),
+			})
+			MaxRetries = 3
+
+			body, err := doRequest(context.Background(), BaseURL+"/fapi/v1/exchangeInfo")
+			if got := errors.Is(err, ErrResponseTooLarge); got != tt.wantErr {
+				t.Fatalf("err = %v, want ErrResponseTooLarge: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(body) != tt.size {
+				t.Errorf("读取 %d 字节, want %d", len(body), tt.size)
+			}
+			if n := attempts.Load(); n != 1 {
+				t.Errorf("请求 %d 次, want 1（超大响应不重试）", n)
+			}
+		})
+	}
+}
+
+func TestFetchRESTKlinesLogsMalformedRows(t *testing.T) {
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	t.Cleanup(func() { SetLogger(nil) })
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/klines": jsonHandler(`[[1,"1","2","0.5","1.5","10",2,"15",3,"5","7.5","0"],[1,"1"]]`),
+	})
+
+	klines, err := fetchRESTKlines(context.Background(), BaseURL+"/fapi/v1/klines")
+	if err != nil {
+		t.Fatalf("fetchRESTKlines() error = %v", err)
+	}
+	if len(klines) != 1 || klines[0].Close != 1.5 {
+		t.Errorf("klines = %+v, want 跳过格式错误的一行", klines)
+	}
+	if warns := rec.warns; len(warns) != 1 || !strings.Contains(warns[0], "解析K线数据失败") {
+		t.Errorf("warns = %q, want 一条解析失败警告", warns)
+	}
+}
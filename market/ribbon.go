@@ -0,0 +1,77 @@
+package market
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RibbonPeriods IntradayData.Ribbon 计算的EMA周期(斐波那契数列)
+var RibbonPeriods = []int{8, 13, 21, 34, 55}
+
+// calculateEMARibbon 计算各周期EMA的最新值，键为周期；K线不足的周期不包含在结果中
+func calculateEMARibbon(klines []Kline, periods []int) map[int]float64 {
+	ribbon := make(map[int]float64, len(periods))
+	for _, period := range periods {
+		if period <= 0 || len(klines) < period {
+			continue
+		}
+		ribbon[period] = calculateEMA(klines, period)
+	}
+	return ribbon
+}
+
+// RibbonBullish EMA带是否呈多头排列：按周期从短到长，EMA值严格递减（短期均线位于长期均线上方）
+// 可用的EMA少于2条时返回 false
+func (d *IntradayData) RibbonBullish() bool {
+	return ribbonOrdered(d.Ribbon, func(shorter, longer float64) bool { return shorter > longer })
+}
+
+// RibbonBearish EMA带是否呈空头排列：按周期从短到长，EMA值严格递增
+func (d *IntradayData) RibbonBearish() bool {
+	return ribbonOrdered(d.Ribbon, func(shorter, longer float64) bool { return shorter < longer })
+}
+
+// ribbonOrdered 按周期升序检查相邻两条EMA是否都满足 ordered
+func ribbonOrdered(ribbon map[int]float64, ordered func(shorter, longer float64) bool) bool {
+	periods := ribbonPeriods(ribbon)
+	if len(periods) < 2 {
+		return false
+	}
+	for i := 1; i < len(periods); i++ {
+		if !ordered(ribbon[periods[i-1]], ribbon[periods[i]]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ribbonPeriods 返回EMA带中的周期（升序）
+func ribbonPeriods(ribbon map[int]float64) []int {
+	periods := make([]int, 0, len(ribbon))
+	for period := range ribbon {
+		periods = append(periods, period)
+	}
+	sort.Ints(periods)
+	return periods
+}
+
+// ribbonNote EMA带呈多头/空头排列时的提示文本，排列交错或数据不足时返回空字符串
+func ribbonNote(d *IntradayData) string {
+	var alignment string
+	switch {
+	case d.RibbonBullish():
+		alignment = "多头排列"
+	case d.RibbonBearish():
+		alignment = "空头排列"
+	default:
+		return ""
+	}
+	periods := ribbonPeriods(d.Ribbon)
+	labels := make([]string, len(periods))
+	for i, period := range periods {
+		labels[i] = strconv.Itoa(period)
+	}
+	return fmt.Sprintf("EMA带(%s): %s\n\n", strings.Join(labels, "/"), alignment)
+}
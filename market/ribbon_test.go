@@ -0,0 +1,41 @@
+package market
+
+import "testing"
+
+func TestEMARibbon(t *testing.T) {
+	tests := []struct {
+		name        string
+		closes      []float64
+		wantPeriods int
+		wantBullish bool
+		wantBearish bool
+		wantNote    string
+	}{
+		{"单边上涨", stepCloses(60, 100, 1), 5, true, false, "EMA带(8/13/21/34/55): 多头排列\n\n"},
+		{"单边下跌", stepCloses(60, 200, -1), 5, false, true, "EMA带(8/13/21/34/55): 空头排列\n\n"},
+		{"横盘", stepCloses(60, 100, 0), 5, false, false, ""},
+		{"K线不足55根", stepCloses(40, 100, 1), 4, true, false, "EMA带(8/13/21/34): 多头排列\n\n"},
+		{"仅1条EMA", stepCloses(10, 100, 1), 1, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			klines := closeKlines(1, tt.closes...)
+			data := calculateIntradaySeries(klines, DefaultIndicatorConfig().Intraday)
+			if len(data.Ribbon) != tt.wantPeriods {
+				t.Fatalf("Ribbon = %v, want %d 条EMA", data.Ribbon, tt.wantPeriods)
+			}
+			for period, value := range data.Ribbon {
+				assertFloatEqual(t, "EMA", value, refEMA(klines, period))
+			}
+			if got := data.RibbonBullish(); got != tt.wantBullish {
+				t.Errorf("RibbonBullish = %v, want %v", got, tt.wantBullish)
+			}
+			if got := data.RibbonBearish(); got != tt.wantBearish {
+				t.Errorf("RibbonBearish = %v, want %v", got, tt.wantBearish)
+			}
+			if got := ribbonNote(data); got != tt.wantNote {
+				t.Errorf("ribbonNote = %q, want %q", got, tt.wantNote)
+			}
+		})
+	}
+}
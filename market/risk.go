@@ -0,0 +1,52 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PositionSize 按固定风险比例计算开仓数量
+// accountEquity: 账户权益；riskPct: 单笔愿意承担的权益百分比（1 表示 1%）；
+// entry/stop: 入场价与止损价。返回数量使得触发止损时的亏损恰好为 accountEquity*riskPct/100。
+// 输入无效（非正数或入场价等于止损价）时返回0。
+func PositionSize(accountEquity, riskPct, entry, stop float64) float64 {
+	if accountEquity <= 0 || riskPct <= 0 || entry <= 0 || stop <= 0 {
+		return 0
+	}
+	perUnitRisk := math.Abs(entry - stop)
+	if perUnitRisk == 0 {
+		return 0
+	}
+	return accountEquity * riskPct / 100 / perUnitRisk
+}
+
+// PositionSizeATR 以15分钟 ATR14 推导止损距离并计算开仓数量
+// 入场价取 data.CurrentPrice，止损 = 入场价 ∓ atrMult*ATR14(15m)；side 为 "long" 或 "short"
+func PositionSizeATR(data *Data, accountEquity, riskPct, atrMult float64, side string) (float64, error) {
+	if data == nil || data.Intraday15m == nil {
+		return 0, fmt.Errorf("缺少15分钟市场数据")
+	}
+	atr := data.Intraday15m.ATR14
+	if atr <= 0 {
+		return 0, fmt.Errorf("15分钟ATR无效: %.6f", atr)
+	}
+	if atrMult <= 0 {
+		return 0, fmt.Errorf("ATR倍数必须为正数，当前为 %.2f", atrMult)
+	}
+
+	entry := data.CurrentPrice
+	var stop float64
+	switch strings.ToLower(side) {
+	case "long":
+		stop = entry - atrMult*atr
+	case "short":
+		stop = entry + atrMult*atr
+	default:
+		return 0, fmt.Errorf("无效的方向: %s (应为 long 或 short)", side)
+	}
+	if stop <= 0 {
+		return 0, fmt.Errorf("止损价无效: %.6f (ATR倍数过大)", stop)
+	}
+	return PositionSize(accountEquity, riskPct, entry, stop), nil
+}
@@ -0,0 +1,73 @@
+package market
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPositionSize(t *testing.T) {
+	tests := []struct {
+		name                         string
+		equity, riskPct, entry, stop float64
+		want                         float64
+	}{
+		// 10000 * 1% = 100 的风险，每单位亏损 100-95=5，数量 20
+		{"做多", 10000, 1, 100, 95, 20},
+		{"做空", 10000, 2, 100, 104, 50},
+		{"止损距离为0", 10000, 1, 100, 100, 0},
+		{"止损价为负", 10000, 1, 100, -5, 0},
+		{"入场价为0", 10000, 1, 0, 95, 0},
+		{"权益为0", 0, 1, 100, 95, 0},
+		{"风险比例为负", 10000, -1, 100, 95, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PositionSize(tt.equity, tt.riskPct, tt.entry, tt.stop); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PositionSize(%v, %v, %v, %v) = %v, want %v", tt.equity, tt.riskPct, tt.entry, tt.stop, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionSizeATR(t *testing.T) {
+	withATR := func(price, atr float64) *Data {
+		return &Data{CurrentPrice: price, Intraday15m: &IntradayData{ATR14: atr}}
+	}
+	tests := []struct {
+		name    string
+		data    *Data
+		atrMult float64
+		side    string
+		want    float64
+		wantErr string // 为空表示期望成功
+	}{
+		// 止损距离 2*2.5=5，风险 100，数量 20
+		{"做多", withATR(100, 2.5), 2, "long", 20, ""},
+		{"做空大写", withATR(100, 2.5), 2, "SHORT", 20, ""},
+		{"ATR为0", withATR(100, 0), 2, "long", 0, "ATR无效"},
+		{"ATR为负", withATR(100, -1), 2, "long", 0, "ATR无效"},
+		{"倍数为0", withATR(100, 2.5), 0, "long", 0, "ATR倍数必须为正数"},
+		{"止损价为负", withATR(100, 60), 2, "long", 0, "止损价无效"},
+		{"方向无效", withATR(100, 2.5), 2, "flat", 0, "无效的方向"},
+		{"缺少15分钟数据", &Data{CurrentPrice: 100}, 2, "long", 0, "缺少15分钟市场数据"},
+		{"数据为nil", nil, 2, "long", 0, "缺少15分钟市场数据"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PositionSizeATR(tt.data, 10000, 1, tt.atrMult, tt.side)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PositionSizeATR() = %v, %v; want 错误包含 %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PositionSizeATR() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PositionSizeATR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+package market
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Rule 声明式告警规则
+// Expr 为布尔表达式，例如 "rsi14_15m < 30 && macd_1h > 0 && funding < 0"
+// 支持比较运算 < <= > >= == !=，布尔运算 && || !，以及括号分组；
+// 变量名见 ruleVariables（统一小写，时间框架以后缀区分，如 rsi14_15m、ema50_4h）
+// 使用 NewRule 构造的规则只解析一次表达式；直接构造的 Rule 在每次计算时解析
+type Rule struct {
+	Name string // 可选：规则名称，触发时优先返回；为空时返回 Expr
+	Expr string
+
+	parsed *parsedRule // NewRule 缓存的语法树
+}
+
+// parsedRule 已解析的表达式及其语法树
+type parsedRule struct {
+	expr string
+	node ruleNode
+}
+
+// NewRule 解析表达式并缓存语法树，表达式无效时返回错误
+func NewRule(name, expr string) (Rule, error) {
+	node, err := parseRuleExpr(expr)
+	if err != nil {
+		return Rule{}, err
+	}
+	return Rule{Name: name, Expr: expr, parsed: &parsedRule{expr: expr, node: node}}, nil
+}
+
+// compile 返回规则的语法树：Expr 未被修改时复用 NewRule 缓存的结果，否则重新解析
+func (r Rule) compile() (ruleNode, error) {
+	if r.parsed != nil && r.parsed.expr == r.Expr {
+		return r.parsed.node, nil
+	}
+	return parseRuleExpr(r.Expr)
+}
+
+// label 返回规则触发时对外展示的名称
+func (r Rule) label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Expr
+}
+
+// Evaluate 针对单个市场数据计算规则是否触发
+func (r Rule) Evaluate(data *Data) (bool, error) {
+	if data == nil {
+		return false, fmt.Errorf("市场数据为空")
+	}
+	node, err := r.compile()
+	if err != nil {
+		return false, err
+	}
+	return node.eval(ruleVariables(data))
+}
+
+// EvaluateRules 依次计算规则，返回触发规则的名称（无名称时返回表达式）
+// 表达式解析失败或引用了缺失的指标时，该规则视为未触发并记录日志
+func EvaluateRules(data *Data, rules []Rule) []string {
+	var fired []string
+	for _, rule := range rules {
+		ok, err := rule.Evaluate(data)
+		if err != nil {
+			logger.Warnf("规则 %q 计算失败: %v", rule.label(), err)
+			continue
+		}
+		if ok {
+			fired = append(fired, rule.label())
+		}
+	}
+	return fired
+}
+
+// ruleVariables 将市场数据展开为规则表达式可引用的变量表
+func ruleVariables(data *Data) map[string]float64 {
+	vars := map[string]float64{
+		"price":            data.CurrentPrice,
+		"ema20":            data.CurrentEMA20,
+		"macd":             data.CurrentMACD,
+		"rsi7":             data.CurrentRSI7,
+		"funding":          data.FundingRate,
+		"price_change_3m":  data.PriceChange3m,
+		"price_change_15m": data.PriceChange15m,
+		"price_change_1h":  data.PriceChange1h,
+		"price_change_4h":  data.PriceChange4h,
+		"price_change_1d":  data.PriceChange1d,
+		"effort_3m":        data.EffortResult3m,
+		"effort_15m":       data.EffortResult15m,
+		"effort_1h":        data.EffortResult1h,
+	}
+
+	if len(data.FundingHistory) > 0 {
+		vars["funding_percentile"] = data.FundingPercentile
+	}
+
+	if oi := data.OpenInterest; oi != nil {
+		vars["oi"] = oi.Latest
+		vars["oi_avg"] = oi.Average
+		vars["oi_change_5m"] = oi.Change5m
+		vars["oi_change_15m"] = oi.Change15m
+		vars["oi_change_1h"] = oi.Change1h
+		vars["oi_change_4h"] = oi.Change4h
+		vars["oi_change_1d"] = oi.Change1d
+		vars["oi_trend"] = oi.TrendScore
+	}
+
+	addIntradayRuleVars(vars, "3m", data.IntradaySeries)
+	addIntradayRuleVars(vars, "15m", data.Intraday15m)
+	addIntradayRuleVars(vars, "1h", data.Intraday1h)
+	addLongerTermRuleVars(vars, "4h", data.LongerTermContext)
+	addLongerTermRuleVars(vars, "1d", data.LongerTerm1d)
+
+	return vars
+}
+
+// addIntradayRuleVars 展开日内数据，序列类指标取最新值
+func addIntradayRuleVars(vars map[string]float64, tf string, d *IntradayData) {
+	if d == nil {
+		return
+	}
+	vars["atr6_"+tf] = d.ATR6
+	vars["atr10_"+tf] = d.ATR10
+	vars["atr12_"+tf] = d.ATR12
+	vars["atr14_"+tf] = d.ATR14
+	vars["atr14_pct_"+tf] = d.ATR14Percent
+	vars["volume_avg_"+tf] = d.VolumeAverage
+	vars["volume_spike_"+tf] = d.VolumeSpikeRatio
+	vars["bb_upper_"+tf] = d.BBUpper
+	vars["bb_middle_"+tf] = d.BBMiddle
+	vars["bb_lower_"+tf] = d.BBLower
+	vars["stoch_k_"+tf] = d.StochK
+	vars["stoch_d_"+tf] = d.StochD
+	vars["stoch_rsi_"+tf] = d.StochRSI
+	vars["williams_r_"+tf] = d.WilliamsR
+	vars["roc_"+tf] = d.ROC
+	setLastRuleVar(vars, "close_"+tf, d.MidPrices)
+	setLastRuleVar(vars, "volume_"+tf, d.VolumeValues)
+	setLastRuleVar(vars, "ema20_"+tf, d.EMA20Values)
+	setLastRuleVar(vars, "macd_"+tf, d.MACDValues12269)
+	setLastRuleVar(vars, "macd10208_"+tf, d.MACDValues10208)
+	setLastRuleVar(vars, "macd_signal_"+tf, d.MACDDEA12269)
+	setLastRuleVar(vars, "macd_hist_"+tf, d.MACDHist12269)
+	setLastRuleVar(vars, "rsi7_"+tf, d.RSI7Values)
+	setLastRuleVar(vars, "rsi9_"+tf, d.RSI9Values)
+	setLastRuleVar(vars, "rsi10_"+tf, d.RSI10Values)
+	setLastRuleVar(vars, "rsi14_"+tf, d.RSI14Values)
+}
+
+// addLongerTermRuleVars 展开长期数据，序列类指标取最新值
+func addLongerTermRuleVars(vars map[string]float64, tf string, d *LongerTermData) {
+	if d == nil {
+		return
+	}
+	vars["ema20_"+tf] = d.EMA20
+	vars["ema50_"+tf] = d.EMA50
+	vars["atr3_"+tf] = d.ATR3
+	vars["atr10_"+tf] = d.ATR10
+	vars["atr12_"+tf] = d.ATR12
+	vars["atr14_"+tf] = d.ATR14
+	vars["atr14_pct_"+tf] = d.ATR14Percent
+	vars["vwap_"+tf] = d.VWAP
+	vars["vwap_upper_"+tf] = d.VWAPUpper
+	vars["vwap_lower_"+tf] = d.VWAPLower
+	vars["adx14_"+tf] = d.ADX14
+	vars["plus_di_"+tf] = d.PlusDI
+	vars["minus_di_"+tf] = d.MinusDI
+	vars["cci20_"+tf] = d.CCI20
+	vars["cmf20_"+tf] = d.CMF20
+	vars["donchian_upper_"+tf] = d.DonchianUpper
+	vars["donchian_lower_"+tf] = d.DonchianLower
+	vars["volume_"+tf] = d.CurrentVolume
+	vars["volume_avg_"+tf] = d.AverageVolume
+	setLastRuleVar(vars, "macd_"+tf, d.MACDValues12269)
+	setLastRuleVar(vars, "macd142810_"+tf, d.MACDValues142810)
+	setLastRuleVar(vars, "macd_signal_"+tf, d.MACDDEA12269)
+	setLastRuleVar(vars, "macd_hist_"+tf, d.MACDHist12269)
+	setLastRuleVar(vars, "rsi14_"+tf, d.RSI14Values)
+	setLastRuleVar(vars, "rsi21_"+tf, d.RSI21Values)
+}
+
+// setLastRuleVar 序列非空时写入最新值；空序列不写入，引用时报缺失
+func setLastRuleVar(vars map[string]float64, name string, values []float64) {
+	if len(values) > 0 {
+		vars[name] = values[len(values)-1]
+	}
+}
+
+// --- 表达式解析 ---
+
+type ruleNode interface {
+	eval(vars map[string]float64) (bool, error)
+}
+
+type ruleOperand struct {
+	name  string // 变量名，为空表示常量
+	value float64
+}
+
+func (o ruleOperand) resolve(vars map[string]float64) (float64, error) {
+	if o.name == "" {
+		return o.value, nil
+	}
+	v, ok := vars[o.name]
+	if !ok {
+		return 0, fmt.Errorf("未知或缺失的指标变量: %s", o.name)
+	}
+	return v, nil
+}
+
+type ruleCompare struct {
+	op          string
+	left, right ruleOperand
+}
+
+func (c ruleCompare) eval(vars map[string]float64) (bool, error) {
+	l, err := c.left.resolve(vars)
+	if err != nil {
+		return false, err
+	}
+	r, err := c.right.resolve(vars)
+	if err != nil {
+		return false, err
+	}
+	switch c.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	}
+	return false, fmt.Errorf("不支持的比较运算符: %s", c.op)
+}
+
+type ruleLogic struct {
+	op          string // "&&" 或 "||"
+	left, right ruleNode
+}
+
+func (n ruleLogic) eval(vars map[string]float64) (bool, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	// 短路求值
+	if n.op == "&&" && !l {
+		return false, nil
+	}
+	if n.op == "||" && l {
+		return true, nil
+	}
+	return n.right.eval(vars)
+}
+
+type ruleNot struct {
+	inner ruleNode
+}
+
+func (n ruleNot) eval(vars map[string]float64) (bool, error) {
+	v, err := n.inner.eval(vars)
+	return !v, err
+}
+
+type ruleParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseRuleExpr 将表达式解析为语法树
+// 语法：or := and ('||' and)*; and := unary ('&&' unary)*;
+// unary := '!' unary | '(' or ')' | operand cmp operand
+func parseRuleExpr(expr string) (ruleNode, error) {
+	tokens, err := tokenizeRuleExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("规则表达式为空")
+	}
+	p := &ruleParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("规则表达式存在多余内容: %q", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+func (p *ruleParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *ruleParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleLogic{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = ruleLogic{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return ruleNot{inner: inner}, nil
+	case "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("规则表达式缺少右括号")
+		}
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	switch op {
+	case "<", "<=", ">", ">=", "==", "!=":
+	default:
+		return nil, fmt.Errorf("期望比较运算符，实际为 %q", op)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return ruleCompare{op: op, left: left, right: right}, nil
+}
+
+func (p *ruleParser) parseOperand() (ruleOperand, error) {
+	tok := p.next()
+	if tok == "" {
+		return ruleOperand{}, fmt.Errorf("规则表达式意外结束")
+	}
+	if v, err := strconv.ParseFloat(tok, 64); err == nil {
+		return ruleOperand{value: v}, nil
+	}
+	if isRuleIdent(tok) {
+		return ruleOperand{name: strings.ToLower(tok)}, nil
+	}
+	return ruleOperand{}, fmt.Errorf("无效的操作数: %q", tok)
+}
+
+// tokenizeRuleExpr 词法切分：标识符、数字（含负号与科学计数法）、运算符、括号
+func tokenizeRuleExpr(expr string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("无效的运算符: %q", string(r))
+			}
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case r == '<' || r == '>' || r == '=' || r == '!':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+				continue
+			}
+			if r == '=' {
+				return nil, fmt.Errorf("赋值运算符 '=' 无效，请使用 '=='")
+			}
+			tokens = append(tokens, string(r))
+			i++
+		case r == '-' || r == '.' || unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' ||
+				runes[j] == 'e' || runes[j] == 'E' ||
+				((runes[j] == '-' || runes[j] == '+') && (runes[j-1] == 'e' || runes[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			return nil, fmt.Errorf("无法识别的字符: %q", string(r))
+		}
+	}
+	return tokens, nil
+}
+
+func isRuleIdent(tok string) bool {
+	for i, r := range tok {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return tok != ""
+}
@@ -0,0 +1,122 @@
+package market
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRuleEvaluate(t *testing.T) {
+	data := &Data{
+		CurrentPrice: 100,
+		CurrentRSI7:  25,
+		FundingRate:  -0.0001,
+		Intraday15m:  &IntradayData{RSI14Values: []float64{35, 28}},
+		Intraday1h:   &IntradayData{MACDValues12269: []float64{-0.2, 0.5}},
+	}
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr string // 为空表示不应出错
+	}{
+		{"比较", "rsi7 < 30", true, ""},
+		{"常量在左侧", "30 > rsi7", true, ""},
+		{"负数与科学计数法", "funding < -1e-5", true, ""},
+		{"变量名大小写不敏感", "RSI14_15m <= 28", true, ""},
+		{"多条件触发", "rsi14_15m < 30 && macd_1h > 0 && funding < 0", true, ""},
+		{"多条件未触发", "rsi14_15m < 30 && macd_1h < 0", false, ""},
+		{"&& 优先于 ||", "price > 0 || price < 0 && price < 0", true, ""},
+		{"括号改变优先级", "(price > 0 || price < 0) && price < 0", false, ""},
+		{"取反", "!(rsi7 > 30)", true, ""},
+		{"双重取反", "!!(rsi7 > 30)", false, ""},
+		{"&& 短路不计算右侧", "price < 0 && missing_var > 0", false, ""},
+		{"|| 短路不计算右侧", "price > 0 || missing_var > 0", true, ""},
+		{"未知变量", "price > 0 && missing_var > 0", false, "未知或缺失的指标变量: missing_var"},
+		{"缺失周期的变量", "rsi14_4h < 30", false, "未知或缺失的指标变量: rsi14_4h"},
+		{"空表达式", "  ", false, "规则表达式为空"},
+		{"缺少右操作数", "rsi7 <", false, "规则表达式意外结束"},
+		{"单等号", "rsi7 = 30", false, "请使用 '=='"},
+		{"缺少右括号", "(rsi7 < 30", false, "缺少右括号"},
+		{"多余右括号", "rsi7 < 30)", false, "多余内容"},
+		{"单个&", "rsi7 < 30 & price > 0", false, "无效的运算符"},
+		{"缺少比较运算符", "rsi7 30", false, "期望比较运算符"},
+		{"无法识别的字符", "rsi7 < 30 # 注释", false, "无法识别的字符"},
+		{"无效操作数", "rsi7 < 1.2.3", false, "无效的操作数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Rule{Expr: tt.expr}.Evaluate(data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Evaluate(%q) err = %v, want 包含 %q", tt.expr, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Evaluate(%q): %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (Rule{Expr: "rsi7 < 30"}).Evaluate(nil); err == nil {
+		t.Error("数据为空时应返回错误")
+	}
+}
+
+func TestNewRuleCachesAST(t *testing.T) {
+	if _, err := NewRule("坏规则", "rsi7 <"); err == nil {
+		t.Fatal("NewRule 应在构造时返回解析错误")
+	}
+
+	rule, err := NewRule("超卖", "rsi7 < 30")
+	if err != nil {
+		t.Fatalf("NewRule: %v", err)
+	}
+	if rule.parsed == nil {
+		t.Fatal("NewRule 未缓存语法树")
+	}
+	if node, err := rule.compile(); err != nil || !reflect.DeepEqual(node, rule.parsed.node) {
+		t.Errorf("compile() 未复用缓存的语法树: %v, %v", node, err)
+	}
+	data := &Data{CurrentRSI7: 25}
+	if ok, err := rule.Evaluate(data); err != nil || !ok {
+		t.Errorf("Evaluate = %v, %v; want true, nil", ok, err)
+	}
+
+	// 构造后修改 Expr 时按新表达式重新解析
+	rule.Expr = "rsi7 > 30"
+	if ok, err := rule.Evaluate(data); err != nil || ok {
+		t.Errorf("修改 Expr 后 Evaluate = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	t.Cleanup(func() { SetLogger(nil) })
+
+	data := &Data{CurrentPrice: 100, CurrentRSI7: 75}
+	named, err := NewRule("超买", "rsi7 > 70")
+	if err != nil {
+		t.Fatalf("NewRule: %v", err)
+	}
+	rules := []Rule{
+		named,
+		{Expr: "price > 50"},
+		{Name: "未触发", Expr: "rsi7 < 30"},
+		{Name: "引用缺失变量", Expr: "oi > 0"},
+	}
+	if got, want := EvaluateRules(data, rules), []string{"超买", "price > 50"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EvaluateRules = %q, want %q", got, want)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.warns) != 1 || !strings.Contains(rec.warns[0], "引用缺失变量") {
+		t.Errorf("warns = %q, want 一条缺失变量的警告", rec.warns)
+	}
+}
@@ -0,0 +1,166 @@
+package market
+
+import (
+	"fmt"
+	"math"
+)
+
+// Validate 检查指标是否处于其理论取值范围内（如 RSI 0-100、Williams %R -100~0、ATR 非负），
+// 返回每一处异常的描述，全部通过时返回 nil。出现异常通常意味着计算缺陷或输入数据异常，
+// 调用方可在依据快照下单前使用；不足以计算而为0的指标视为合法
+func (d *Data) Validate() []error {
+	if d == nil {
+		return []error{fmt.Errorf("数据为空")}
+	}
+	c := &rangeChecker{}
+
+	if d.CurrentPrice <= 0 || math.IsInf(d.CurrentPrice, 0) || math.IsNaN(d.CurrentPrice) {
+		c.errs = append(c.errs, fmt.Errorf("当前价格=%v 必须为正数", d.CurrentPrice))
+	}
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{
+		{"3m价格变化", d.PriceChange3m},
+		{"15m价格变化", d.PriceChange15m},
+		{"1h价格变化", d.PriceChange1h},
+		{"4h价格变化", d.PriceChange4h},
+		{"1d价格变化", d.PriceChange1d},
+		{"EMA20", d.CurrentEMA20},
+		{"MACD", d.CurrentMACD},
+		{"资金费率", d.FundingRate},
+	} {
+		c.finite(f.name, f.value)
+	}
+	c.check("RSI7", d.CurrentRSI7, 0, 100)
+	c.check("资金费率百分位", d.FundingPercentile, 0, 100)
+	c.nonNegative("主动买卖量比", d.TakerBuySellRatio)
+	if d.OpenInterest != nil {
+		c.nonNegative("持仓量", d.OpenInterest.Latest)
+		c.nonNegative("平均持仓量", d.OpenInterest.Average)
+	}
+	if d.NearestSupport != 0 && d.NearestSupport > d.CurrentPrice {
+		c.errs = append(c.errs, fmt.Errorf("最近支撑位(%v)高于当前价格(%v)", d.NearestSupport, d.CurrentPrice))
+	}
+	if d.NearestResistance != 0 && d.NearestResistance < d.CurrentPrice {
+		c.errs = append(c.errs, fmt.Errorf("最近阻力位(%v)低于当前价格(%v)", d.NearestResistance, d.CurrentPrice))
+	}
+
+	for _, entry := range intradayByTimeframe(d) {
+		c.intraday(entry.timeframe, entry.data)
+	}
+	c.longerTerm("4h", d.LongerTermContext)
+	c.longerTerm("1d", d.LongerTerm1d)
+	c.longerTerm("1w", d.LongerTerm1w)
+	c.longerTerm("1M", d.LongerTerm1Mo)
+	return c.errs
+}
+
+// rangeChecker 收集超出取值范围的指标
+type rangeChecker struct {
+	errs []error
+}
+
+// check 检查 value 是否位于 [lo, hi]，NaN 视为越界
+func (c *rangeChecker) check(name string, value, lo, hi float64) {
+	if math.IsNaN(value) || value < lo || value > hi {
+		c.errs = append(c.errs, fmt.Errorf("%s=%v 超出取值范围[%v, %v]", name, value, lo, hi))
+	}
+}
+
+// nonNegative 检查 value 为有限的非负数
+func (c *rangeChecker) nonNegative(name string, value float64) {
+	c.check(name, value, 0, math.MaxFloat64)
+}
+
+// finite 检查 value 不是 NaN/Inf
+func (c *rangeChecker) finite(name string, value float64) {
+	c.check(name, value, -math.MaxFloat64, math.MaxFloat64)
+}
+
+// series 逐点检查序列，只报告第一个越界点
+func (c *rangeChecker) series(name string, values []float64, lo, hi float64) {
+	for i, v := range values {
+		if math.IsNaN(v) || v < lo || v > hi {
+			c.check(fmt.Sprintf("%s[%d]", name, i), v, lo, hi)
+			return
+		}
+	}
+}
+
+// ordered 检查 upper >= lower（任一为0表示未计算，跳过）
+func (c *rangeChecker) ordered(upperName string, upper float64, lowerName string, lower float64) {
+	if upper != 0 && lower != 0 && upper < lower {
+		c.errs = append(c.errs, fmt.Errorf("%s(%v)低于%s(%v)", upperName, upper, lowerName, lower))
+	}
+}
+
+// intraday 检查日内指标
+func (c *rangeChecker) intraday(tf string, data *IntradayData) {
+	if data == nil {
+		return
+	}
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{
+		{"ATR6", data.ATR6},
+		{"ATR10", data.ATR10},
+		{"ATR12", data.ATR12},
+		{"ATR14", data.ATR14},
+		{"ATR14%", data.ATR14Percent},
+		{"布林带宽度", data.BBBandwidth},
+		{"平均成交量", data.VolumeAverage},
+		{"量比", data.VolumeSpikeRatio},
+	} {
+		c.nonNegative(tf+" "+f.name, f.value)
+	}
+	c.check(tf+" StochK", data.StochK, 0, 100)
+	c.check(tf+" StochD", data.StochD, 0, 100)
+	c.check(tf+" StochRSI", data.StochRSI, 0, 100)
+	c.check(tf+" Williams%R", data.WilliamsR, -100, 0)
+	c.finite(tf+" ROC", data.ROC)
+	c.ordered(tf+" 布林带上轨", data.BBUpper, tf+" 布林带中轨", data.BBMiddle)
+	c.ordered(tf+" 布林带中轨", data.BBMiddle, tf+" 布林带下轨", data.BBLower)
+	c.series(tf+" RSI7", data.RSI7Values, 0, 100)
+	c.series(tf+" RSI9", data.RSI9Values, 0, 100)
+	c.series(tf+" RSI10", data.RSI10Values, 0, 100)
+	c.series(tf+" RSI14", data.RSI14Values, 0, 100)
+	c.series(tf+" 成交量", data.VolumeValues, 0, math.MaxFloat64)
+}
+
+// longerTerm 检查长期指标
+func (c *rangeChecker) longerTerm(tf string, data *LongerTermData) {
+	if data == nil {
+		return
+	}
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{
+		{"EMA20", data.EMA20},
+		{"EMA50", data.EMA50},
+		{"ATR3", data.ATR3},
+		{"ATR10", data.ATR10},
+		{"ATR12", data.ATR12},
+		{"ATR14", data.ATR14},
+		{"ATR14%", data.ATR14Percent},
+		{"VWAP", data.VWAP},
+		{"当前成交量", data.CurrentVolume},
+		{"平均成交量", data.AverageVolume},
+	} {
+		c.nonNegative(tf+" "+f.name, f.value)
+	}
+	c.check(tf+" ADX14", data.ADX14, 0, 100)
+	c.check(tf+" +DI", data.PlusDI, 0, 100)
+	c.check(tf+" -DI", data.MinusDI, 0, 100)
+	c.check(tf+" CMF20", data.CMF20, -1, 1)
+	c.finite(tf+" CCI20", data.CCI20)
+	c.ordered(tf+" VWAP上轨", data.VWAPUpper, tf+" VWAP", data.VWAP)
+	c.ordered(tf+" VWAP", data.VWAP, tf+" VWAP下轨", data.VWAPLower)
+	c.ordered(tf+" 唐奇安上轨", data.DonchianUpper, tf+" 唐奇安下轨", data.DonchianLower)
+	c.ordered(tf+" 价值区域上沿", data.ValueAreaHigh, tf+" POC", data.POC)
+	c.ordered(tf+" POC", data.POC, tf+" 价值区域下沿", data.ValueAreaLow)
+	c.series(tf+" RSI14", data.RSI14Values, 0, 100)
+	c.series(tf+" RSI21", data.RSI21Values, 0, 100)
+}
@@ -0,0 +1,54 @@
+package market
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDataValidate(t *testing.T) {
+	valid := func() *Data {
+		return &Data{
+			CurrentPrice:      100,
+			CurrentRSI7:       55,
+			NearestSupport:    95,
+			IntradaySeries:    &IntradayData{RSI7Values: []float64{40, 55}, StochK: 50, WilliamsR: -50},
+			LongerTermContext: &LongerTermData{EMA20: 98, ADX14: 25, CMF20: 0.1, VWAP: 99, VWAPUpper: 101, VWAPLower: 97},
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(d *Data)
+		want   []string // 每条期望错误包含的片段，为空表示全部通过
+	}{
+		{"合法数据", func(d *Data) {}, nil},
+		{"RSI越界", func(d *Data) { d.IntradaySeries.RSI7Values[1] = 120 }, []string{"3m RSI7[1]=120 超出取值范围[0, 100]"}},
+		{"多处异常", func(d *Data) {
+			d.CurrentRSI7 = -5
+			d.LongerTermContext.CMF20 = math.NaN()
+		}, []string{"RSI7=-5", "4h CMF20=NaN"}},
+		{"价格非正", func(d *Data) { d.CurrentPrice = 0; d.NearestSupport = 0 }, []string{"当前价格=0 必须为正数"}},
+		{"支撑位高于价格", func(d *Data) { d.NearestSupport = 105 }, []string{"最近支撑位(105)高于当前价格(100)"}},
+		{"VWAP带顺序颠倒", func(d *Data) { d.LongerTermContext.VWAPUpper = 90 }, []string{"4h VWAP上轨(90)低于4h VWAP(99)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := valid()
+			tt.modify(d)
+			errs := d.Validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d 个错误", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("错误[%d] = %q, want 包含 %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+
+	var nilData *Data
+	if errs := nilData.Validate(); len(errs) != 1 {
+		t.Errorf("nil.Validate() = %v, want 1 个错误", errs)
+	}
+}
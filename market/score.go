@@ -0,0 +1,99 @@
+package market
+
+import "math"
+
+// ScoreWeights Score 各分项的权重，按权重之和归一化，负权重按0处理
+type ScoreWeights struct {
+	RSI          float64 // 3分钟 RSI7 偏离50的程度
+	MACD         float64 // 1小时 MACD(12,26,9) 柱状图，以 ATR14 归一化
+	EMAAlignment float64 // 3m/15m/1h/4h/1d 各周期 EMA 趋势标签(up/down/flat)的一致程度
+	Funding      float64 // 资金费率方向（正费率视为多头情绪）
+}
+
+// DefaultScoreWeights 返回 Score 使用的默认权重：RSI 0.25、MACD 0.25、EMA排列 0.35、资金费率 0.15
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{RSI: 0.25, MACD: 0.25, EMAAlignment: 0.35, Funding: 0.15}
+}
+
+// scoreFundingScale 资金费率分项的归一化尺度，费率为该值时分项约为 tanh(1)≈0.76
+const scoreFundingScale = 0.0005
+
+// Score 使用默认权重计算方向性评分，范围 -100(强烈看空) 到 +100(强烈看多)
+func (d *Data) Score() float64 {
+	return d.ScoreWith(DefaultScoreWeights())
+}
+
+// ScoreWith 按指定权重计算方向性评分，范围 -100..+100
+// 每个分项先映射到 [-1,1]，缺失的数据对应分项为0（仍计入权重）；
+// 结果只依赖 Data 与权重，相同输入总是得到相同结果
+func (d *Data) ScoreWith(w ScoreWeights) float64 {
+	if d == nil {
+		return 0
+	}
+	components := []struct {
+		weight, value float64
+	}{
+		{w.RSI, scoreRSI(d)},
+		{w.MACD, scoreMACD(d)},
+		{w.EMAAlignment, scoreEMAAlignment(d)},
+		{w.Funding, math.Tanh(d.FundingRate / scoreFundingScale)},
+	}
+
+	var sum, total float64
+	for _, c := range components {
+		if c.weight <= 0 || math.IsNaN(c.value) {
+			continue
+		}
+		sum += c.weight * c.value
+		total += c.weight
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Max(-100, math.Min(100, sum/total*100))
+}
+
+// scoreRSI 将 RSI7 映射到 [-1,1]：50 为0，100 为1，0 为-1
+func scoreRSI(d *Data) float64 {
+	if d.CurrentRSI7 <= 0 {
+		return 0
+	}
+	return math.Max(-1, math.Min(1, (d.CurrentRSI7-50)/50))
+}
+
+// scoreMACD 以1小时 ATR14 归一化最新 MACD 柱状图后取 tanh
+func scoreMACD(d *Data) float64 {
+	s := d.Intraday1h
+	if s == nil || len(s.MACDHist12269) == 0 || s.ATR14 <= 0 {
+		return 0
+	}
+	return math.Tanh(s.MACDHist12269[len(s.MACDHist12269)-1] / s.ATR14)
+}
+
+// scoreEMAAlignment 各周期趋势标签的平均值：up 为1，down 为-1，flat 为0
+func scoreEMAAlignment(d *Data) float64 {
+	var trends []string
+	for _, s := range []*IntradayData{d.IntradaySeries, d.Intraday15m, d.Intraday1h} {
+		if s != nil {
+			trends = append(trends, s.Trend)
+		}
+	}
+	for _, s := range []*LongerTermData{d.LongerTermContext, d.LongerTerm1d} {
+		if s != nil {
+			trends = append(trends, s.Trend)
+		}
+	}
+	if len(trends) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, t := range trends {
+		switch t {
+		case TrendUp:
+			sum++
+		case TrendDown:
+			sum--
+		}
+	}
+	return sum / float64(len(trends))
+}
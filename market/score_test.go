@@ -0,0 +1,50 @@
+package market
+
+import (
+	"math"
+	"testing"
+)
+
+// scoreData 构造各分项方向一致的 Data：bullish 为 true 时全部看多，否则全部看空
+func scoreData(bullish bool) *Data {
+	sign, trend := 1.0, TrendUp
+	if !bullish {
+		sign, trend = -1, TrendDown
+	}
+	return &Data{
+		CurrentRSI7:       50 + sign*30,
+		FundingRate:       sign * 0.0005,
+		IntradaySeries:    &IntradayData{Trend: trend},
+		Intraday15m:       &IntradayData{Trend: trend},
+		Intraday1h:        &IntradayData{Trend: trend, ATR14: 10, MACDHist12269: []float64{0, sign * 10}},
+		LongerTermContext: &LongerTermData{Trend: trend},
+		LongerTerm1d:      &LongerTermData{Trend: trend},
+	}
+}
+
+func TestScore(t *testing.T) {
+	// RSI 0.6、MACD tanh(1)、EMA排列 1、资金费率 tanh(1)，按默认权重加权
+	want := (0.25*0.6 + 0.25*math.Tanh(1) + 0.35*1 + 0.15*math.Tanh(1)) * 100
+	tests := []struct {
+		name string
+		data *Data
+		want float64
+	}{
+		{"强烈看多", scoreData(true), want},
+		{"强烈看空", scoreData(false), -want},
+		{"无数据", &Data{}, 0},
+		{"nil", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloatEqual(t, "Score", tt.data.Score(), tt.want)
+		})
+	}
+
+	// 只保留单一分项权重时结果即该分项映射值
+	assertFloatEqual(t, "仅RSI", scoreData(true).ScoreWith(ScoreWeights{RSI: 1}), 60)
+	assertFloatEqual(t, "负权重忽略", scoreData(false).ScoreWith(ScoreWeights{EMAAlignment: 1, Funding: -5}), -100)
+	if got := scoreData(true).ScoreWith(ScoreWeights{}); got != 0 {
+		t.Errorf("权重全为0 = %v, want 0", got)
+	}
+}
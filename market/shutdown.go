@@ -0,0 +1,57 @@
+package market
+
+import (
+	"context"
+	"sync"
+)
+
+// background 跟踪包内启动的后台goroutine（WebSocket读取/重连、K线处理等），供 Shutdown 等待退出
+var background = struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	stopped bool
+	once    sync.Once
+}{}
+
+// goBackground 启动受 Shutdown 管理的后台goroutine；Shutdown 之后不再启动新的goroutine并返回 false
+func goBackground(fn func()) bool {
+	background.mu.Lock()
+	defer background.mu.Unlock()
+	if background.stopped {
+		return false
+	}
+	background.wg.Add(1)
+	go func() {
+		defer background.wg.Done()
+		fn()
+	}()
+	return true
+}
+
+// Shutdown 停止包内所有后台goroutine：关闭 WebSocket 连接与订阅，停止重连，并等待goroutine退出
+// 在 ctx 截止前全部退出返回 nil，否则返回 ctx.Err()。
+// 可重复调用：关闭动作只执行一次，之后的调用仅等待剩余goroutine退出。
+func Shutdown(ctx context.Context) error {
+	background.once.Do(func() {
+		background.mu.Lock()
+		background.stopped = true
+		background.mu.Unlock()
+
+		if WSMonitorCli != nil {
+			WSMonitorCli.Close()
+		}
+	})
+
+	exited := make(chan struct{})
+	go func() {
+		background.wg.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
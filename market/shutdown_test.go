@@ -0,0 +1,38 @@
+package market
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCombinedStreamsCloseDuringTraffic(t *testing.T) {
+	c := NewCombinedStreamsClient(10)
+	c.AddSubscriber("btcusdt@kline_3m", 1)
+	msg := []byte(`{"stream":"btcusdt@kline_3m","data":{}}`)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			c.handleCombinedMessage(msg)
+		}
+	}()
+	c.Close()
+	wg.Wait()
+}
+
+func TestWSMonitorCloseIdempotent(t *testing.T) {
+	prev := WSMonitorCli
+	defer func() { WSMonitorCli = prev }()
+
+	m := NewWSMonitor(10)
+	events, cancel := m.SubscribeCandleClose("BTCUSDT", "3m")
+	defer cancel()
+
+	m.Close()
+	m.Close()
+	if _, ok := <-events; ok {
+		t.Fatal("Close 后K线收盘事件通道应已关闭")
+	}
+}
@@ -0,0 +1,176 @@
+package market
+
+import "fmt"
+
+// Signal 一个已触发的指标条件，如 "RSI7<30 oversold"
+type Signal struct {
+	Name      string `json:"name"`
+	Timeframe string `json:"timeframe"`
+	Bullish   bool   `json:"bullish"` // true 看多，false 看空
+}
+
+// SignalThresholds Signals 使用的超买超卖阈值
+type SignalThresholds struct {
+	RSIOversold        float64 // 最新 RSI7 低于该值视为超卖
+	RSIOverbought      float64 // 最新 RSI7 高于该值视为超买
+	StochOversold      float64 // 随机指标%K 低于该值视为超卖
+	StochOverbought    float64 // 随机指标%K 高于该值视为超买
+	WilliamsOversold   float64 // 威廉指标%R 低于该值视为超卖(-100~0)
+	WilliamsOverbought float64 // 威廉指标%R 高于该值视为超买(-100~0)
+}
+
+// DefaultSignalThresholds 返回 Signals 使用的默认阈值：RSI 30/70、随机指标 20/80、威廉指标 -80/-20
+func DefaultSignalThresholds() SignalThresholds {
+	return SignalThresholds{
+		RSIOversold:        30,
+		RSIOverbought:      70,
+		StochOversold:      20,
+		StochOverbought:    80,
+		WilliamsOversold:   -80,
+		WilliamsOverbought: -20,
+	}
+}
+
+// Signals 使用默认阈值列出已触发的指标条件
+func (d *Data) Signals() []Signal {
+	return d.SignalsWith(DefaultSignalThresholds())
+}
+
+// SignalsWith 按指定阈值列出已触发的指标条件：各日内周期的 RSI7/随机指标/威廉指标超买超卖、
+// MACD(12,26,9) 与 EMA(9/21) 交叉、收盘价突破布林带、RSI背离，长期周期的放量突破，
+// 以及1小时 MACD 背离与15分钟假突破。按周期从短到长排列，无触发时返回 nil
+func (d *Data) SignalsWith(t SignalThresholds) []Signal {
+	if d == nil {
+		return nil
+	}
+	var signals []Signal
+	add := func(tf, name string, bullish bool) {
+		signals = append(signals, Signal{Name: name, Timeframe: tf, Bullish: bullish})
+	}
+
+	for _, entry := range intradayByTimeframe(d) {
+		tf, series := entry.timeframe, entry.data
+		if n := len(series.RSI7Values); n > 0 {
+			switch rsi := series.RSI7Values[n-1]; {
+			case rsi < t.RSIOversold:
+				add(tf, fmt.Sprintf("RSI7<%g oversold", t.RSIOversold), true)
+			case rsi > t.RSIOverbought:
+				add(tf, fmt.Sprintf("RSI7>%g overbought", t.RSIOverbought), false)
+			}
+		}
+		// %K/%D 均为0表示K线不足未计算
+		if series.StochK > 0 || series.StochD > 0 {
+			switch {
+			case series.StochK < t.StochOversold:
+				add(tf, fmt.Sprintf("Stoch<%g oversold", t.StochOversold), true)
+			case series.StochK > t.StochOverbought:
+				add(tf, fmt.Sprintf("Stoch>%g overbought", t.StochOverbought), false)
+			}
+		}
+		if series.WilliamsR != 0 {
+			switch {
+			case series.WilliamsR < t.WilliamsOversold:
+				add(tf, fmt.Sprintf("WilliamsR<%g oversold", t.WilliamsOversold), true)
+			case series.WilliamsR > t.WilliamsOverbought:
+				add(tf, fmt.Sprintf("WilliamsR>%g overbought", t.WilliamsOverbought), false)
+			}
+		}
+		if n := len(series.MACDHist12269); n >= 2 {
+			prev, last := series.MACDHist12269[n-2], series.MACDHist12269[n-1]
+			switch {
+			case prev <= 0 && last > 0:
+				add(tf, "MACD cross up", true)
+			case prev >= 0 && last < 0:
+				add(tf, "MACD cross down", false)
+			}
+		}
+		if series.EMACrossUp {
+			add(tf, "EMA9/21 cross up", true)
+		}
+		if series.EMACrossDown {
+			add(tf, "EMA9/21 cross down", false)
+		}
+		if n := len(series.MidPrices); n > 0 && series.BBUpper > series.BBLower {
+			switch price := series.MidPrices[n-1]; {
+			case price > series.BBUpper:
+				add(tf, "price>BB upper", false)
+			case price < series.BBLower:
+				add(tf, "price<BB lower", true)
+			}
+		}
+		if series.RSIBullishDivergence {
+			add(tf, "RSI14 bullish divergence", true)
+		}
+		if series.RSIBearishDivergence {
+			add(tf, "RSI14 bearish divergence", false)
+		}
+	}
+
+	for _, entry := range []struct {
+		timeframe string
+		data      *LongerTermData
+	}{
+		{"4h", d.LongerTermContext},
+		{"1d", d.LongerTerm1d},
+		{"1w", d.LongerTerm1w},
+		{"1M", d.LongerTerm1Mo},
+	} {
+		if entry.data == nil {
+			continue
+		}
+		if entry.data.BreakoutUp {
+			add(entry.timeframe, "Donchian breakout up", true)
+		}
+		if entry.data.BreakoutDown {
+			add(entry.timeframe, "Donchian breakout down", false)
+		}
+	}
+
+	if d.MACDBullishDivergence1h {
+		add("1h", "MACD bullish divergence", true)
+	}
+	if d.MACDBearishDivergence1h {
+		add("1h", "MACD bearish divergence", false)
+	}
+	switch d.Fakeout15m {
+	case FakeoutBullTrap:
+		add("15m", "bull trap", false)
+	case FakeoutBearTrap:
+		add("15m", "bear trap", true)
+	}
+	return signals
+}
+
+// timeframeSeries 周期及其日内指标
+type timeframeSeries struct {
+	timeframe string
+	data      *IntradayData
+}
+
+// intradayByTimeframe 按周期从短到长返回日内指标：优先使用 Data.Timeframes，
+// 为空时(如手动构造或旧版本序列化的数据)回退到3m/15m/1h固定字段
+func intradayByTimeframe(d *Data) []timeframeSeries {
+	var result []timeframeSeries
+	if len(d.Timeframes) > 0 {
+		timeframes := make([]string, 0, len(d.Timeframes))
+		for tf := range d.Timeframes {
+			timeframes = append(timeframes, tf)
+		}
+		for _, tf := range sortedTimeframes(timeframes) {
+			if d.Timeframes[tf] != nil {
+				result = append(result, timeframeSeries{tf, d.Timeframes[tf]})
+			}
+		}
+		return result
+	}
+	for _, entry := range []timeframeSeries{
+		{"3m", d.IntradaySeries},
+		{"15m", d.Intraday15m},
+		{"1h", d.Intraday1h},
+	} {
+		if entry.data != nil {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
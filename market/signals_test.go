@@ -0,0 +1,55 @@
+package market
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSignals(t *testing.T) {
+	data := &Data{
+		IntradaySeries: &IntradayData{RSI7Values: []float64{40, 25}},
+		Intraday15m:    &IntradayData{RSI7Values: []float64{50}, MACDHist12269: []float64{-0.5, 0.2}},
+	}
+	tests := []struct {
+		name       string
+		data       *Data
+		thresholds SignalThresholds
+		want       []Signal
+	}{
+		{"默认阈值", data, DefaultSignalThresholds(), []Signal{
+			{Name: "RSI7<30 oversold", Timeframe: "3m", Bullish: true},
+			{Name: "MACD cross up", Timeframe: "15m", Bullish: true},
+		}},
+		{"自定义RSI阈值", data, SignalThresholds{RSIOversold: 20, RSIOverbought: 45, WilliamsOversold: -80, WilliamsOverbought: -20}, []Signal{
+			{Name: "RSI7>45 overbought", Timeframe: "15m", Bullish: false},
+			{Name: "MACD cross up", Timeframe: "15m", Bullish: true},
+		}},
+		{"按周期排序", &Data{Timeframes: map[string]*IntradayData{
+			"1h": {RSI7Values: []float64{80}},
+			"3m": {WilliamsR: -90},
+		}}, DefaultSignalThresholds(), []Signal{
+			{Name: "WilliamsR<-80 oversold", Timeframe: "3m", Bullish: true},
+			{Name: "RSI7>70 overbought", Timeframe: "1h", Bullish: false},
+		}},
+		{"长期周期与15m假突破", &Data{
+			LongerTerm1d: &LongerTermData{BreakoutUp: true},
+			Fakeout15m:   FakeoutBullTrap,
+		}, DefaultSignalThresholds(), []Signal{
+			{Name: "Donchian breakout up", Timeframe: "1d", Bullish: true},
+			{Name: "bull trap", Timeframe: "15m", Bullish: false},
+		}},
+		{"无触发", &Data{IntradaySeries: &IntradayData{RSI7Values: []float64{50}}}, DefaultSignalThresholds(), nil},
+		{"nil", nil, DefaultSignalThresholds(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.SignalsWith(tt.thresholds); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SignalsWith() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := data.Signals(); len(got) != 2 {
+		t.Errorf("Signals() = %+v, want 2 个信号", got)
+	}
+}
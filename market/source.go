@@ -0,0 +1,17 @@
+package market
+
+import "context"
+
+// KlineSource K线数据源，默认实现为 WSMonitorCli(WebSocket 缓存)
+// 可替换为其他行情提供方，或在无 WebSocket 连接时注入固定K线
+type KlineSource interface {
+	GetCurrentKlines(symbol, interval string) ([]Kline, error)
+}
+
+// GetWithSource 使用指定K线数据源获取市场数据，src 为 nil 时使用 WSMonitorCli
+// 持仓量、资金费率等合约数据仍通过 REST 接口获取
+func GetWithSource(src KlineSource, symbol string) (*Data, error) {
+	opts := DefaultOptions()
+	opts.Source = src
+	return getWithConfig(context.Background(), symbol, DefaultIndicatorConfig(), opts)
+}
@@ -0,0 +1,117 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// intervalSource 记录被请求周期的 KlineSource
+type intervalSource struct {
+	fakeSource
+	mu        sync.Mutex
+	intervals []string
+}
+
+func (s *intervalSource) GetCurrentKlines(symbol, interval string) ([]Kline, error) {
+	s.mu.Lock()
+	s.intervals = append(s.intervals, symbol+"@"+interval)
+	s.mu.Unlock()
+	return s.fakeSource.GetCurrentKlines(symbol, interval)
+}
+
+func TestGetWithSource(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+		"/fapi/v1/openInterest": jsonHandler(`{"openInterest":"1234.5","symbol":"BTCUSDT"}`),
+	})
+	klines := testKlines(100, 3*time.Minute)
+	src := &intervalSource{fakeSource: fakeSource{klines: klines}}
+
+	data, err := GetWithSource(src, "btc")
+	if err != nil {
+		t.Fatalf("GetWithSource: %v", err)
+	}
+
+	want := make([]string, 0, len(DefaultTimeframes))
+	for _, tf := range DefaultTimeframes {
+		want = append(want, "BTCUSDT@"+tf)
+	}
+	sort.Strings(want)
+	sort.Strings(src.intervals)
+	if strings.Join(src.intervals, ",") != strings.Join(want, ",") {
+		t.Errorf("请求的周期 = %v, want %v", src.intervals, want)
+	}
+	if data.Symbol != "BTCUSDT" || data.CurrentPrice != klines[len(klines)-1].Close {
+		t.Errorf("Symbol/CurrentPrice = %s/%v, want BTCUSDT/%v", data.Symbol, data.CurrentPrice, klines[len(klines)-1].Close)
+	}
+	// 合约数据仍经 REST 获取
+	if data.OpenInterest == nil || data.OpenInterest.Latest != 1234.5 {
+		t.Errorf("OpenInterest = %+v, want Latest=1234.5", data.OpenInterest)
+	}
+}
+
+// blockingSource 阻塞到 release 关闭才返回的 KlineSource，模拟卡住的数据源
+type blockingSource struct {
+	release chan struct{}
+}
+
+func (s *blockingSource) GetCurrentKlines(symbol, interval string) ([]Kline, error) {
+	<-s.release
+	return nil, nil
+}
+
+func TestKlineSourceTimeoutFallsBackToREST(t *testing.T) {
+	rec := &recordingLogger{}
+	SetLogger(rec)
+	t.Cleanup(func() { SetLogger(nil) })
+	var restKlines atomic.Int32
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+		"/fapi/v1/klines":       countingHandler(&restKlines, klinesHandler(100)),
+	})
+	src := &blockingSource{release: make(chan struct{})}
+	t.Cleanup(func() { close(src.release) })
+
+	opts := DefaultOptions()
+	opts.Source = src
+	opts.KlineTimeout = 20 * time.Millisecond
+	begin := time.Now()
+	data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+	if err != nil {
+		t.Fatalf("GetWithOptions: %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("数据源阻塞时 Get 耗时 %v，未按 KlineTimeout 回退", elapsed)
+	}
+	if n := int(restKlines.Load()); n != len(opts.Timeframes) {
+		t.Errorf("REST klines 请求 %d 次, want %d", n, len(opts.Timeframes))
+	}
+	if data.CurrentPrice <= 0 {
+		t.Errorf("CurrentPrice = %v, want > 0", data.CurrentPrice)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	fallbacks := 0
+	for _, w := range rec.warns {
+		if strings.Contains(w, "改用 REST 接口") {
+			fallbacks++
+		}
+	}
+	if fallbacks != len(opts.Timeframes) {
+		t.Errorf("回退日志 %d 条, want 每个周期一条: %q", fallbacks, rec.warns)
+	}
+
+	// ctx 取消优先于超时回退
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fetchKlines(ctx, src, "BTCUSDT", "3m", time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("fetchKlines err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,35 @@
+package market
+
+// 挤压检测参数：布林带(20,2) 与肯特纳通道(EMA20 ± 1.5×ATR20)
+const (
+	squeezePeriod     = 20
+	squeezeBBMult     = 2.0
+	squeezeKeltnerATR = 1.5
+)
+
+// calculateKeltner 计算肯特纳通道：中轨为 period 期EMA，上下轨为中轨 ± atrMult 倍 period 期ATR
+// K线不足时返回0
+func calculateKeltner(klines []Kline, period int, atrMult float64) (upper, middle, lower float64) {
+	middle = calculateEMA(klines, period)
+	atr := calculateATR(klines, period)
+	if middle == 0 || atr == 0 {
+		return 0, 0, 0
+	}
+	return middle + atrMult*atr, middle, middle - atrMult*atr
+}
+
+// DetectSqueeze 检测 TTM 挤压：布林带(20,2)完全位于肯特纳通道(20,1.5ATR)之内时 inSqueeze 为 true，
+// 表示波动率收缩、可能酝酿突破；bandwidth 为布林带宽度百分比 (上轨-下轨)/中轨*100。K线不足时返回 false, 0
+func DetectSqueeze(klines []Kline) (inSqueeze bool, bandwidth float64) {
+	bbUpper, bbMiddle, bbLower := calculateBollingerBands(klines, squeezePeriod, squeezeBBMult)
+	if bbMiddle <= 0 {
+		return false, 0
+	}
+	bandwidth = (bbUpper - bbLower) / bbMiddle * 100
+
+	kcUpper, _, kcLower := calculateKeltner(klines, squeezePeriod, squeezeKeltnerATR)
+	if kcUpper == 0 {
+		return false, bandwidth
+	}
+	return bbUpper < kcUpper && bbLower > kcLower, bandwidth
+}
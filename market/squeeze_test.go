@@ -0,0 +1,34 @@
+package market
+
+import "testing"
+
+func TestDetectSqueeze(t *testing.T) {
+	flat := make([]float64, 40)
+	for i := range flat {
+		flat[i] = 100 + 0.2*float64(i%2)
+	}
+	tests := []struct {
+		name          string
+		klines        []Kline
+		wantSqueeze   bool
+		wantBandwidth bool // 带宽是否应大于0
+	}{
+		// 收盘价几乎不动而K线振幅很大：布林带收进肯特纳通道
+		{"波动收缩", closeKlines(3, flat...), true, true},
+		// 单边趋势、振幅很小：布林带远宽于肯特纳通道
+		{"趋势扩张", closeKlines(0.1, stepCloses(40, 100, 1)...), false, true},
+		{"K线不足", closeKlines(3, flat[:19]...), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			squeeze, bandwidth := DetectSqueeze(tt.klines)
+			if squeeze != tt.wantSqueeze || (bandwidth > 0) != tt.wantBandwidth {
+				t.Errorf("DetectSqueeze = %v, %v; want squeeze=%v, bandwidth>0=%v", squeeze, bandwidth, tt.wantSqueeze, tt.wantBandwidth)
+			}
+			if tt.wantBandwidth {
+				upper, mid, lower := calculateBollingerBands(tt.klines, squeezePeriod, squeezeBBMult)
+				assertFloatEqual(t, "bandwidth", bandwidth, (upper-lower)/mid*100)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package market
+
+import "time"
+
+// staleFactor 最新K线距今超过 staleFactor 倍周期时长即视为数据停滞
+const staleFactor = 1.5
+
+// IsStale 判断指定周期的数据是否停滞
+// 正常推送时最新K线（当前未收盘K线）的开盘时间距今不会超过一个周期；
+// 超过 1.5 倍周期说明数据源已冻结，即使 Get 仍然“成功”返回了旧K线。
+// 未知周期或缺少该周期的K线时间时返回 true（无法确认数据新鲜）。
+func (d *Data) IsStale(interval string, now time.Time) bool {
+	duration, ok := intervalDuration(interval)
+	if !ok || d == nil {
+		return true
+	}
+	openTime, ok := d.LatestKlineTimes[interval]
+	if !ok || openTime.IsZero() {
+		return true
+	}
+	return now.Sub(openTime.Time) > time.Duration(float64(duration)*staleFactor)
+}
+
+// IsStaleAfter 判断快照是否已超过 maxAge 未更新（以 LastUpdate 为准），
+// 适合在下单前拒绝过旧的数据；LastUpdate 缺失时返回 true
+func (d *Data) IsStaleAfter(maxAge time.Duration) bool {
+	if d == nil || d.LastUpdate.IsZero() {
+		return true
+	}
+	return now().Sub(d.LastUpdate.Time) > maxAge
+}
+
+// latestKlineTimes 提取各周期最新一根K线的开盘时间
+func latestKlineTimes(klinesByInterval map[string][]Kline) map[string]Timestamp {
+	times := make(map[string]Timestamp, len(klinesByInterval))
+	for interval, klines := range klinesByInterval {
+		if len(klines) == 0 {
+			continue
+		}
+		times[interval] = Timestamp{time.UnixMilli(klines[len(klines)-1].OpenTime)}
+	}
+	return times
+}
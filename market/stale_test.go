@@ -0,0 +1,55 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsStaleAfter(t *testing.T) {
+	current := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	useFakeClock(t, current)
+
+	tests := []struct {
+		name string
+		data *Data
+		want bool
+	}{
+		{"刚更新", &Data{LastUpdate: Timestamp{current.Add(-time.Minute)}}, false},
+		{"恰好到期", &Data{LastUpdate: Timestamp{current.Add(-5 * time.Minute)}}, false},
+		{"连接断开后的旧数据", &Data{LastUpdate: Timestamp{current.Add(-2 * time.Hour)}}, true},
+		{"缺少LastUpdate", &Data{}, true},
+		{"nil", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.IsStaleAfter(5 * time.Minute); got != tt.want {
+				t.Errorf("IsStaleAfter(5m) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSetsLastUpdate(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+	klines := testKlines(100, 3*time.Minute)
+	opts := DefaultOptions()
+	opts.Source = &fakeSource{klines: klines}
+
+	data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+	if err != nil {
+		t.Fatalf("GetWithOptions: %v", err)
+	}
+	want := time.UnixMilli(klines[len(klines)-1].CloseTime)
+	if !data.LastUpdate.Equal(want) {
+		t.Errorf("LastUpdate = %v, want 最新3m K线收盘时间 %v", data.LastUpdate, want)
+	}
+
+	useFakeClock(t, want.Add(time.Hour))
+	if !data.IsStaleAfter(10 * time.Minute) {
+		t.Error("1小时前的快照应视为过旧")
+	}
+}
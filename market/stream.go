@@ -0,0 +1,98 @@
+package market
+
+import (
+	"context"
+	"errors"
+)
+
+// CandleCloseSource 能推送K线收盘事件的K线数据源，WSMonitor 实现了该接口
+type CandleCloseSource interface {
+	KlineSource
+	// SubscribeCandleClose 订阅收盘事件，调用返回的取消函数后停止推送并关闭通道
+	SubscribeCandleClose(symbol, interval string) (<-chan Kline, func())
+}
+
+// Subscribe 订阅 symbol 的实时市场数据：订阅后立即计算并推送一份 Data，
+// 之后基础周期(3m)每根K线收盘时重新计算并推送。
+// 首次计算通过数据源获取各周期K线，WSMonitor 对尚未监控的交易对会在此时动态订阅对应的 WebSocket 流，
+// 因此不要求调用前已订阅。ctx 取消、数据源关闭或 Shutdown 后停止订阅并关闭返回的通道；
+// 单次计算失败记录日志后跳过，不关闭通道
+func Subscribe(ctx context.Context, symbol string) (<-chan *Data, error) {
+	return SubscribeWithOptions(ctx, symbol, DefaultOptions())
+}
+
+// SubscribeWithOptions 按指定选项订阅实时市场数据，基础周期为3m，未请求3m时为最短周期
+// 数据源(Options.Source，默认 WSMonitorCli)须实现 CandleCloseSource；不支持现货与历史模式(EndTime)
+func SubscribeWithOptions(ctx context.Context, symbol string, opts Options) (<-chan *Data, error) {
+	timeframes, err := opts.timeframes()
+	if err != nil {
+		return nil, err
+	}
+	opts.Timeframes = timeframes
+	if opts.market() != Futures || !opts.EndTime.IsZero() {
+		return nil, errors.New("订阅仅支持合约实时数据")
+	}
+	if opts.Source == nil && WSMonitorCli == nil {
+		return nil, errors.New("WebSocket 监控未初始化")
+	}
+	src, ok := opts.klineSource().(CandleCloseSource)
+	if !ok {
+		return nil, errors.New("K线数据源不支持收盘事件推送")
+	}
+
+	symbol = Normalize(symbol)
+	events, cancel := src.SubscribeCandleClose(symbol, baseTimeframe(timeframes))
+	out := make(chan *Data, 1)
+	worker := func() {
+		defer close(out)
+		defer cancel()
+		// push 计算并推送一份 Data，返回 false 表示订阅应结束
+		push := func() bool {
+			data, err := getWithConfig(ctx, symbol, DefaultIndicatorConfig(), opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return false
+				}
+				logger.Warnf("订阅 %s 重新计算失败: %v", symbol, err)
+				return true
+			}
+			select {
+			case out <- data:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if !push() {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+			}
+			if !push() {
+				return
+			}
+		}
+	}
+	if !goBackground(worker) {
+		cancel()
+		return nil, errors.New("行情模块已关闭")
+	}
+	return out, nil
+}
+
+// baseTimeframe 返回驱动订阅推送的基础周期：与 Get 的headline指标一致，优先3m，否则为最短周期
+func baseTimeframe(timeframes []string) string {
+	for _, tf := range timeframes {
+		if tf == "3m" {
+			return tf
+		}
+	}
+	return shortestTimeframe(timeframes)
+}
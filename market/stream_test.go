@@ -0,0 +1,69 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeCandleSource 可手动触发收盘事件的 CandleCloseSource
+type fakeCandleSource struct {
+	fakeSource
+	events chan Kline
+}
+
+func (s *fakeCandleSource) SubscribeCandleClose(symbol, interval string) (<-chan Kline, func()) {
+	return s.events, func() {}
+}
+
+func TestSubscribePushesInitialAndOnClose(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+	src := &fakeCandleSource{
+		fakeSource: fakeSource{klines: testKlines(100, 3*time.Minute)},
+		events:     make(chan Kline, 1),
+	}
+	opts := DefaultOptions()
+	opts.Source = src
+	opts.Timeframes = []string{"3m"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, err := SubscribeWithOptions(ctx, "BTC", opts)
+	if err != nil {
+		t.Fatalf("SubscribeWithOptions: %v", err)
+	}
+
+	receive := func(stage string) *Data {
+		t.Helper()
+		select {
+		case data, ok := <-out:
+			if !ok {
+				t.Fatalf("%s: 通道提前关闭", stage)
+			}
+			return data
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: 未收到推送", stage)
+		}
+		return nil
+	}
+
+	// 未发生收盘事件也应立即推送首份数据
+	if data := receive("首次推送"); data.Symbol != "BTCUSDT" {
+		t.Errorf("Symbol = %q, want BTCUSDT", data.Symbol)
+	}
+	src.events <- Kline{}
+	receive("收盘推送")
+
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("ctx 取消后不应继续推送")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ctx 取消后通道未关闭")
+	}
+}
@@ -0,0 +1,65 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// TickerStats 合约24小时滚动行情统计（Binance /fapi/v1/ticker/24hr）
+type TickerStats struct {
+	PriceChangePercent float64 `json:"price_change_percent"` // 24小时涨跌幅(%)
+	WeightedAvgPrice   float64 `json:"weighted_avg_price"`   // 24小时成交量加权均价
+	HighPrice          float64 `json:"high_price"`           // 24小时最高价
+	LowPrice           float64 `json:"low_price"`            // 24小时最低价
+	Volume             float64 `json:"volume"`               // 24小时成交量(基础币)
+	QuoteVolume        float64 `json:"quote_volume"`         // 24小时成交额(计价币)
+}
+
+// get24hTicker 获取24小时行情统计
+func get24hTicker(ctx context.Context, symbol string) (*TickerStats, error) {
+	url := fmt.Sprintf("%s/fapi/v1/ticker/24hr?symbol=%s", BaseURL, symbol)
+
+	body, err := doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseTicker24h(body)
+}
+
+// parseTicker24h 解析 ticker/24hr 响应，数值字段均为字符串
+func parseTicker24h(body []byte) (*TickerStats, error) {
+	var result struct {
+		PriceChangePercent string `json:"priceChangePercent"`
+		WeightedAvgPrice   string `json:"weightedAvgPrice"`
+		HighPrice          string `json:"highPrice"`
+		LowPrice           string `json:"lowPrice"`
+		Volume             string `json:"volume"`
+		QuoteVolume        string `json:"quoteVolume"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	stats := &TickerStats{}
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *float64
+	}{
+		{"priceChangePercent", result.PriceChangePercent, &stats.PriceChangePercent},
+		{"weightedAvgPrice", result.WeightedAvgPrice, &stats.WeightedAvgPrice},
+		{"highPrice", result.HighPrice, &stats.HighPrice},
+		{"lowPrice", result.LowPrice, &stats.LowPrice},
+		{"volume", result.Volume, &stats.Volume},
+		{"quoteVolume", result.QuoteVolume, &stats.QuoteVolume},
+	} {
+		v, err := strconv.ParseFloat(f.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s failed: %w", f.name, err)
+		}
+		*f.dst = v
+	}
+	return stats, nil
+}
@@ -0,0 +1,57 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseTicker24h(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    TickerStats
+		wantErr string
+	}{
+		{"正常", `{"symbol":"BTCUSDT","priceChange":"-94.99","priceChangePercent":"-2.189",` +
+			`"weightedAvgPrice":"4297.52","lastPrice":"4243.00","highPrice":"4391.00","lowPrice":"4210.50",` +
+			`"volume":"123456.789","quoteVolume":"530560123.45"}`,
+			TickerStats{PriceChangePercent: -2.189, WeightedAvgPrice: 4297.52, HighPrice: 4391, LowPrice: 4210.5, Volume: 123456.789, QuoteVolume: 530560123.45}, ""},
+		{"字段格式错误", `{"priceChangePercent":"1","weightedAvgPrice":"1","highPrice":"x","lowPrice":"1","volume":"1","quoteVolume":"1"}`,
+			TickerStats{}, "parse highPrice failed"},
+		{"缺少字段", `{"priceChangePercent":"1"}`, TickerStats{}, "parse weightedAvgPrice failed"},
+		{"非JSON", `<html>`, TickerStats{}, "invalid character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTicker24h([]byte(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want 包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTicker24h: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("parseTicker24h = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGet24hTicker(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/ticker/24hr": jsonHandler(`{"priceChangePercent":"3.5","weightedAvgPrice":"100","highPrice":"105",` +
+			`"lowPrice":"95","volume":"1000","quoteVolume":"100000"}`),
+	})
+	stats, err := get24hTicker(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("get24hTicker: %v", err)
+	}
+	if stats.PriceChangePercent != 3.5 || stats.HighPrice != 105 || stats.QuoteVolume != 100000 {
+		t.Errorf("get24hTicker = %+v", stats)
+	}
+}
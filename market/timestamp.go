@@ -0,0 +1,71 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// JSON 时间戳格式
+const (
+	TimestampRFC3339 = "rfc3339" // "2024-01-02T15:04:05Z"（默认）
+	TimestampMillis  = "millis"  // 毫秒整数，适配多数 JavaScript 图表库
+	TimestampUnix    = "unix"    // 秒级整数
+)
+
+// TimestampFormat 控制 Timestamp 类型字段的 JSON 输出格式，取值见 TimestampRFC3339/TimestampMillis/TimestampUnix
+// 注意：Kline 的 OpenTime/CloseTime 保持 Binance 原始的毫秒整数，不受此选项影响
+var TimestampFormat = TimestampRFC3339
+
+// Timestamp 按 TimestampFormat 进行 JSON 序列化的时间
+type Timestamp struct {
+	time.Time
+}
+
+// MarshalJSON 按 TimestampFormat 输出；零值输出 null
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	switch TimestampFormat {
+	case TimestampMillis:
+		return []byte(strconv.FormatInt(t.UnixMilli(), 10)), nil
+	case TimestampUnix:
+		return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+	case TimestampRFC3339, "":
+		return json.Marshal(t.UTC().Format(time.RFC3339Nano))
+	default:
+		return nil, fmt.Errorf("不支持的时间戳格式: %s", TimestampFormat)
+	}
+}
+
+// UnmarshalJSON 兼容三种格式：字符串按 RFC3339 解析，整数按 TimestampFormat 判断秒/毫秒（默认毫秒）
+func (t *Timestamp) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("解析时间戳失败: %w", err)
+		}
+		t.Time = parsed
+		return nil
+	}
+	v, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return fmt.Errorf("解析时间戳失败: %w", err)
+	}
+	if TimestampFormat == TimestampUnix {
+		t.Time = time.Unix(v, 0)
+	} else {
+		t.Time = time.UnixMilli(v)
+	}
+	return nil
+}
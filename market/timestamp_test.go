@@ -0,0 +1,90 @@
+package market
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampFormatRoundTrip(t *testing.T) {
+	prev := TimestampFormat
+	t.Cleanup(func() { TimestampFormat = prev })
+
+	update := time.Date(2024, 1, 2, 15, 4, 5, 678_000_000, time.UTC)
+	kline := update.Add(-3 * time.Minute)
+	funding := update.Add(8 * time.Hour)
+
+	tests := []struct {
+		format     string
+		wantUpdate string        // last_update 的原始 JSON
+		precision  time.Duration // 该格式可保留的精度
+	}{
+		{TimestampRFC3339, `"2024-01-02T15:04:05.678Z"`, time.Nanosecond},
+		{TimestampMillis, "1704207845678", time.Millisecond},
+		{TimestampUnix, "1704207845", time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			TimestampFormat = tt.format
+			data := &Data{
+				Symbol:           "BTCUSDT",
+				LastUpdate:       Timestamp{update},
+				LatestKlineTimes: map[string]Timestamp{"3m": {kline}},
+				Funding:          &FundingData{NextFundingTime: Timestamp{funding}},
+			}
+			body, err := json.Marshal(data)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(body, &raw); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := string(raw["last_update"]); got != tt.wantUpdate {
+				t.Errorf("last_update = %s, want %s", got, tt.wantUpdate)
+			}
+
+			var decoded Data
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				t.Fatalf("Unmarshal Data: %v", err)
+			}
+			for _, c := range []struct {
+				name      string
+				got, want time.Time
+			}{
+				{"LastUpdate", decoded.LastUpdate.Time, update},
+				{"LatestKlineTimes[3m]", decoded.LatestKlineTimes["3m"].Time, kline},
+				{"Funding.NextFundingTime", decoded.Funding.NextFundingTime.Time, funding},
+			} {
+				if want := c.want.Truncate(tt.precision); !c.got.Equal(want) {
+					t.Errorf("%s 往返后 = %v, want %v", c.name, c.got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimestampZeroAndInvalidFormat(t *testing.T) {
+	prev := TimestampFormat
+	t.Cleanup(func() { TimestampFormat = prev })
+
+	// 零值输出 null，反序列化后仍为零值
+	body, err := json.Marshal(&Data{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.Contains(string(body), `"last_update":null`) {
+		t.Errorf("零值时间应输出 null: %s", body)
+	}
+	var decoded Data
+	if err := json.Unmarshal(body, &decoded); err != nil || !decoded.LastUpdate.IsZero() {
+		t.Errorf("Unmarshal = %v, LastUpdate = %v; want 零值", err, decoded.LastUpdate)
+	}
+
+	TimestampFormat = "iso"
+	if _, err := json.Marshal(&Data{LastUpdate: Timestamp{time.Unix(1, 0)}}); err == nil || !strings.Contains(err.Error(), "不支持的时间戳格式") {
+		t.Errorf("Marshal error = %v, want 不支持的时间戳格式", err)
+	}
+}
@@ -0,0 +1,163 @@
+package market
+
+// 趋势方向标签
+const (
+	TrendUp    = "up"
+	TrendDown  = "down"
+	TrendFlat  = "flat"  // 单一时间框架内无明确方向
+	TrendMixed = "mixed" // 多时间框架加权后无明确方向
+)
+
+// TimeframeWeights 多时间框架趋势投票权重，周期越大权重越高
+type TimeframeWeights struct {
+	TF3m  float64 `json:"3m"`
+	TF15m float64 `json:"15m"`
+	TF1h  float64 `json:"1h"`
+	TF4h  float64 `json:"4h"`
+	TF1d  float64 `json:"1d"`
+}
+
+// TrendWeights DominantTrend 使用的权重配置，默认 1d 最重、3m 最轻
+var TrendWeights = TimeframeWeights{
+	TF3m:  1,
+	TF15m: 2,
+	TF1h:  3,
+	TF4h:  4,
+	TF1d:  5,
+}
+
+// DominantTrendThreshold 加权得分的绝对值超过该阈值才判定为 up/down，否则为 mixed
+var DominantTrendThreshold = 0.2
+
+// DominantTrend 按 TrendWeights 对各时间框架的 Trend 标签加权投票，返回 "up"/"down"/"mixed"
+// 高周期权重更大，避免被3分钟噪音来回甩
+func DominantTrend(data *Data) string {
+	score := DominantTrendScore(data)
+	switch {
+	case score > DominantTrendThreshold:
+		return TrendUp
+	case score < -DominantTrendThreshold:
+		return TrendDown
+	default:
+		return TrendMixed
+	}
+}
+
+// DominantTrendScore 返回加权趋势得分，范围 [-1, 1]
+// up 记 +1，down 记 -1，flat 记 0；按存在数据的时间框架权重归一化
+func DominantTrendScore(data *Data) float64 {
+	if data == nil {
+		return 0
+	}
+	votes := []struct {
+		trend  string
+		weight float64
+	}{
+		{intradayTrend(data.IntradaySeries), TrendWeights.TF3m},
+		{intradayTrend(data.Intraday15m), TrendWeights.TF15m},
+		{intradayTrend(data.Intraday1h), TrendWeights.TF1h},
+		{longerTermTrend(data.LongerTermContext), TrendWeights.TF4h},
+		{longerTermTrend(data.LongerTerm1d), TrendWeights.TF1d},
+	}
+
+	var score, total float64
+	for _, v := range votes {
+		if v.trend == "" || v.weight <= 0 {
+			continue
+		}
+		total += v.weight
+		switch v.trend {
+		case TrendUp:
+			score += v.weight
+		case TrendDown:
+			score -= v.weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return score / total
+}
+
+func intradayTrend(d *IntradayData) string {
+	if d == nil {
+		return ""
+	}
+	return d.Trend
+}
+
+func longerTermTrend(d *LongerTermData) string {
+	if d == nil {
+		return ""
+	}
+	return d.Trend
+}
+
+// classifyIntradayTrend 日内趋势：收盘价位于EMA20之上且EMA20上行为 up，反之为 down，否则 flat
+func classifyIntradayTrend(closes, ema20 []float64) string {
+	if len(closes) == 0 || len(ema20) < 2 {
+		return TrendFlat
+	}
+	lastClose := closes[len(closes)-1]
+	lastEMA := ema20[len(ema20)-1]
+	slope := lastEMA - ema20[0]
+	switch {
+	case lastClose > lastEMA && slope > 0:
+		return TrendUp
+	case lastClose < lastEMA && slope < 0:
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
+// classifyLongerTermTrend 长期趋势：收盘价 > EMA20 > EMA50 为 up，收盘价 < EMA20 < EMA50 为 down，否则 flat
+// EMA50 数据不足(为0)时仅比较收盘价与EMA20
+func classifyLongerTermTrend(lastClose, ema20, ema50 float64) string {
+	if lastClose <= 0 || ema20 <= 0 {
+		return TrendFlat
+	}
+	if ema50 <= 0 {
+		switch {
+		case lastClose > ema20:
+			return TrendUp
+		case lastClose < ema20:
+			return TrendDown
+		}
+		return TrendFlat
+	}
+	switch {
+	case lastClose > ema20 && ema20 > ema50:
+		return TrendUp
+	case lastClose < ema20 && ema20 < ema50:
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
+// EMA 交叉检测使用的快慢线周期
+const (
+	emaCrossFast = 9
+	emaCrossSlow = 21
+)
+
+// DetectEMACross 检测最新一根K线上快慢EMA是否发生交叉
+// 金叉(crossedUp)：上一根快线 <= 慢线且最新一根快线 > 慢线；死叉(crossedDown)反之
+// 需要至少 max(fast, slow)+1 根K线，不足时均返回 false
+func DetectEMACross(klines []Kline, fast, slow int) (crossedUp, crossedDown bool) {
+	longest := slow
+	if fast > longest {
+		longest = fast
+	}
+	if fast <= 0 || slow <= 0 || len(klines) < longest+1 {
+		return false, false
+	}
+
+	fastEMA := emaSeries(klines, fast)
+	slowEMA := emaSeries(klines, slow)
+	last := len(klines) - 1
+	prevDiff := fastEMA[last-1] - slowEMA[last-1]
+	currDiff := fastEMA[last] - slowEMA[last]
+	return prevDiff <= 0 && currDiff > 0, prevDiff >= 0 && currDiff < 0
+}
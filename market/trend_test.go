@@ -0,0 +1,47 @@
+package market
+
+import "testing"
+
+func TestDetectEMACross(t *testing.T) {
+	tests := []struct {
+		name     string
+		closes   []float64
+		wantUp   bool
+		wantDown bool
+	}{
+		{"下跌后反弹金叉", append(stepCloses(40, 140, -1), stepCloses(30, 101, 2)...), true, false},
+		{"上涨后回落死叉", append(stepCloses(40, 100, 1), stepCloses(30, 139, -2)...), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			klines := closeKlines(1, tt.closes...)
+			fast, slow := emaSeries(klines, emaCrossFast), emaSeries(klines, emaCrossSlow)
+			cross := -1
+			for i := emaCrossSlow; i < len(klines); i++ {
+				if (fast[i-1]-slow[i-1])*(fast[i]-slow[i]) < 0 {
+					cross = i
+					break
+				}
+			}
+			if cross < 0 {
+				t.Fatal("构造的序列未发生交叉")
+			}
+
+			// 截取到交叉发生的那根K线：最新一根触发交叉
+			up, down := DetectEMACross(klines[:cross+1], emaCrossFast, emaCrossSlow)
+			if up != tt.wantUp || down != tt.wantDown {
+				t.Errorf("交叉K线 = %v, %v; want %v, %v", up, down, tt.wantUp, tt.wantDown)
+			}
+			// 交叉前一根与后一根均不报告
+			for _, n := range []int{cross, cross + 2} {
+				if up, down := DetectEMACross(klines[:n], emaCrossFast, emaCrossSlow); up || down {
+					t.Errorf("%d 根K线 = %v, %v; want false, false", n, up, down)
+				}
+			}
+		})
+	}
+
+	if up, down := DetectEMACross(closeKlines(1, stepCloses(21, 100, 1)...), 9, 21); up || down {
+		t.Error("K线不足时不应报告交叉")
+	}
+}
@@ -4,97 +4,227 @@ import "time"
 
 // Data 市场数据结构
 type Data struct {
-	Symbol            string
-	CurrentPrice      float64
-	PriceChange3m     float64 // 新增：最近一个3m与前一个3m的价格变化百分比
-	PriceChange1h     float64 // 1小时价格变化百分比
-	PriceChange4h     float64 // 4小时价格变化百分比
-	PriceChange15m    float64 // 新增：15分钟价格变化百分比
-	PriceChange1d     float64 // 新增：1天价格变化百分比
-	CurrentEMA20      float64
-	CurrentMACD       float64
-	CurrentRSI7       float64
-	OpenInterest      *OIData
-	FundingRate       float64
-	IntradaySeries    *IntradayData   // 3分钟数据
-	Intraday15m       *IntradayData   // 新增：15分钟数据
-	Intraday1h        *IntradayData   // 新增：1小时数据
-	LongerTermContext *LongerTermData // 4小时数据
-	LongerTerm1d      *LongerTermData // 新增：1天数据
+	Symbol            string           `json:"symbol"`
+	Market            Market           `json:"market"` // futures / spot，现货模式下合约相关字段为空
+	CurrentPrice      float64          `json:"current_price"`
+	PriceChange3m     float64          `json:"price_change_3m"`  // 新增：最近一个3m与前一个3m的价格变化百分比
+	PriceChange1h     float64          `json:"price_change_1h"`  // 1小时价格变化百分比
+	PriceChange4h     float64          `json:"price_change_4h"`  // 4小时价格变化百分比
+	PriceChange15m    float64          `json:"price_change_15m"` // 新增：15分钟价格变化百分比
+	PriceChange1d     float64          `json:"price_change_1d"`  // 新增：1天价格变化百分比
+	CurrentEMA20      float64          `json:"current_ema20"`
+	CurrentMACD       float64          `json:"current_macd"`
+	CurrentRSI7       float64          `json:"current_rsi7"`
+	OpenInterest      *OIData          `json:"open_interest"`
+	FundingRate       float64          `json:"funding_rate"`         // 与 Funding.Rate 相同，保留以兼容旧调用方
+	Funding           *FundingData     `json:"funding"`              // 资金费率、标记/指数价格，获取失败时为nil
+	FundingPercentile float64          `json:"funding_percentile"`   // 当前资金费率在近期资金费率历史中的百分位(0-100)，无历史数据时为0
+	FundingHistory    []float64        `json:"funding_history"`      // 最近 FundingHistoryLimit 期已结算资金费率（按时间升序），需开启 Options.FundingHistory
+	FundingTrend      string           `json:"funding_trend"`        // 资金费率趋势: rising/falling/flat
+	LongShort         *LongShortData   `json:"long_short"`           // 多空账户比，未开启 Options.LongShortRatio 或获取失败时为nil
+	TakerBuySellRatio float64          `json:"taker_buy_sell_ratio"` // 主动买入量/主动卖出量，未开启 Options.TakerBuySellRatio 或获取失败时为0
+	Liquidations      *LiquidationData `json:"liquidations"`         // 最近强平汇总，仅 Options.Liquidations 时获取，未获取或失败时为nil
+	Depth             *DepthData       `json:"depth"`                // 订单簿深度快照，未启用或获取失败时为nil
+	Ticker            *TickerStats     `json:"ticker"`               // 24小时行情统计，未开启 Options.Ticker24h 或获取失败时为nil
+	IntradaySeries    *IntradayData    `json:"intraday_series"`      // 3分钟数据
+	Intraday15m       *IntradayData    `json:"intraday_15m"`         // 新增：15分钟数据
+	Intraday1h        *IntradayData    `json:"intraday_1h"`          // 新增：1小时数据
+	LongerTermContext *LongerTermData  `json:"longer_term_context"`  // 4小时数据
+	LongerTerm1d      *LongerTermData  `json:"longer_term_1d"`       // 新增：1天数据
+	LongerTerm1w      *LongerTermData  `json:"longer_term_1w"`       // 1周数据，仅在请求1w周期时填充
+	LongerTerm1Mo     *LongerTermData  `json:"longer_term_1mo"`      // 1月数据，仅在请求1M周期时填充
+
+	// 按周期(如 "3m"、"1w")索引的日内指标，包含本次获取的全部周期
+	Timeframes map[string]*IntradayData `json:"timeframes"`
 
 	// Effort vs Result 指标 (价量 + OI 共振效率) 越高代表价格推进效率高
-	EffortResult3m  float64
-	EffortResult15m float64
-	EffortResult1h  float64
+	EffortResult3m  float64 `json:"effort_result_3m"`
+	EffortResult15m float64 `json:"effort_result_15m"`
+	EffortResult1h  float64 `json:"effort_result_1h"`
 	// 解释标签 (高效/低效/背离)，便于直接输出
-	EffortLabel3m  string
-	EffortLabel15m string
-	EffortLabel1h  string
+	EffortLabel3m  string `json:"effort_label_3m"`
+	EffortLabel15m string `json:"effort_label_15m"`
+	EffortLabel1h  string `json:"effort_label_1h"`
+
+	// 1小时 MACD 柱状图背离，周期取自 IndicatorConfig.MACDShort/MACDLong/MACDSignal(默认12,26,9)
+	MACDBullishDivergence1h bool `json:"macd_bullish_divergence_1h"`
+	MACDBearishDivergence1h bool `json:"macd_bearish_divergence_1h"`
+
+	// 15分钟假突破检测: "bull_trap" / "bear_trap" / "none"
+	Fakeout15m string `json:"fakeout_15m"`
+
+	// 基于1小时摆动点的最近支撑位(低于当前价)与阻力位(高于当前价)，不存在时为0
+	NearestSupport    float64 `json:"nearest_support"`
+	NearestResistance float64 `json:"nearest_resistance"`
+
+	// 最近几根3分钟K线识别出的形态（Index 对应 Klines["3m"] 下标）
+	Patterns3m []Pattern `json:"patterns_3m"`
+
+	// 各周期最新一根K线的开盘时间（键为周期，如 "3m"），用于检测数据源是否停止更新
+	LatestKlineTimes map[string]Timestamp `json:"latest_kline_times"`
+
+	// 主周期(3m，未请求时为最短周期)最新一根K线的收盘时间，见 IsStaleAfter
+	LastUpdate Timestamp `json:"last_update"`
+
+	// Partial 为 true 表示部分周期K线数量不足(如 WebSocket 缓存尚未填满)，
+	// Warnings 列出无法计算(值为0)的指标，见 MinKlines
+	Partial  bool     `json:"partial"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	// 计算所用的原始K线（键为周期），便于调用方自行分析或绘图而无需重新获取
+	// 默认保留，每个周期约100根，会增加内存与JSON体积；可通过 Options.OmitKlines 关闭
+	Klines map[string][]Kline `json:"klines,omitempty"`
 }
 
 // OIData Open Interest数据
 type OIData struct {
-	Latest  float64
-	Average float64
+	Latest  float64 `json:"latest"`
+	Average float64 `json:"average"` // History 的算术平均，历史不可用时等于 Latest
+	// Binance 持仓量历史（5分钟粒度，最近 OIHistoryLimit 个点，按时间升序）
+	History []float64 `json:"history"`
 	// 历史序列（不同周期）
-	Series5m  []float64
-	Series15m []float64
-	Series1h  []float64
-	Series4h  []float64
-	Series1d  []float64
+	Series5m  []float64 `json:"series_5m"`
+	Series15m []float64 `json:"series_15m"`
+	Series1h  []float64 `json:"series_1h"`
+	Series4h  []float64 `json:"series_4h"`
+	Series1d  []float64 `json:"series_1d"`
 
 	// 变化率（相邻最新两点的百分比变化）
-	Change5m  float64
-	Change15m float64
-	Change1h  float64
-	Change4h  float64
-	Change1d  float64
+	Change5m  float64 `json:"change_5m"`
+	Change15m float64 `json:"change_15m"`
+	Change1h  float64 `json:"change_1h"`
+	Change4h  float64 `json:"change_4h"`
+	Change1d  float64 `json:"change_1d"`
 
 	// 趋势评分（简单地取各周期变化率的平均，后续可替换为线性回归斜率加权）
-	TrendScore float64
+	TrendScore float64 `json:"trend_score"`
 }
 
 // IntradayData 日内数据(3分钟,15,1小时)
 type IntradayData struct {
-	ATR6  float64
-	ATR10 float64
-	ATR12 float64
-	ATR14 float64
+	ATR6         float64 `json:"atr6"`
+	ATR10        float64 `json:"atr10"`
+	ATR12        float64 `json:"atr12"`
+	ATR14        float64 `json:"atr14"`
+	ATR14Percent float64 `json:"atr14_percent"` // ATR14 / 最新收盘价 * 100
+
+	// 布林带(20,2)最新值
+	BBUpper  float64 `json:"bb_upper"`
+	BBMiddle float64 `json:"bb_middle"`
+	BBLower  float64 `json:"bb_lower"`
+
+	// 布林带宽度百分比与 TTM 挤压(布林带位于肯特纳通道内，波动率收缩)
+	BBBandwidth float64 `json:"bb_bandwidth"`
+	Squeeze     bool    `json:"squeeze"`
+
+	// 随机指标(14,3)最新值
+	StochK float64 `json:"stoch_k"`
+	StochD float64 `json:"stoch_d"`
+
+	StochRSI  float64 `json:"stoch_rsi"`  // 随机RSI(14,14)最新值，0-100
+	WilliamsR float64 `json:"williams_r"` // 威廉指标%R(14)最新值，-100~0
+	ROC       float64 `json:"roc"`        // 变动率ROC(10)：相对10根K线前收盘价的百分比变化
+	CCI20     float64 `json:"cci20"`      // 商品通道指数(20)，<-100 超卖
+
+	// 超级趋势(10,3)：跟踪止损位与方向(true 为多头)
+	Supertrend        float64 `json:"supertrend"`
+	SupertrendBullish bool    `json:"supertrend_bullish"`
+
+	// 最新一根K线上 EMA(9) 与 EMA(21) 的金叉/死叉
+	EMACrossUp   bool `json:"ema_cross_up"`
+	EMACrossDown bool `json:"ema_cross_down"`
 
-	MidPrices   []float64
-	EMA20Values []float64
+	// EMA带：RibbonPeriods 各周期EMA最新值(键为周期)，见 RibbonBullish/RibbonBearish
+	Ribbon map[int]float64 `json:"ribbon"`
 
-	MACDValues10208 []float64
-	MACDValues12269 []float64
+	// RSI(14)常规背离（窗口为 DivergenceWindow）
+	RSIBullishDivergence bool `json:"rsi_bullish_divergence"`
+	RSIBearishDivergence bool `json:"rsi_bearish_divergence"`
 
-	RSI7Values  []float64
-	RSI9Values  []float64
-	RSI10Values []float64
-	RSI14Values []float64
+	MidPrices   []float64 `json:"mid_prices"`
+	EMA20Values []float64 `json:"ema20_values"`
+
+	MACDValues10208 []float64 `json:"macd_values_10208"`
+	MACDValues12269 []float64 `json:"macd_values_12269"`
+
+	// 信号线(DEA)与柱状图(DIF-DEA)，只包含信号线形成后的点，与 MACDValues* 末尾对齐（K线不足时更短）
+	MACDDEA10208  []float64 `json:"macd_dea_10208"`
+	MACDDEA12269  []float64 `json:"macd_dea_12269"`
+	MACDHist10208 []float64 `json:"macd_hist_10208"`
+	MACDHist12269 []float64 `json:"macd_hist_12269"`
+
+	RSI7Values  []float64 `json:"rsi7_values"`
+	RSI9Values  []float64 `json:"rsi9_values"`
+	RSI10Values []float64 `json:"rsi10_values"`
+	RSI14Values []float64 `json:"rsi14_values"`
 
 	// 新增：成交量序列与量能指标
-	VolumeValues     []float64 // 最近10个点的成交量
-	VolumeAverage    float64   // 最近10个点平均成交量
-	VolumeSpikeRatio float64   // 最新成交量 / 之前N(默认为9)个平均成交量
+	VolumeValues     []float64 `json:"volume_values"`      // 最近10个点的成交量
+	VolumeAverage    float64   `json:"volume_average"`     // 最近10个点平均成交量
+	VolumeSpikeRatio float64   `json:"volume_spike_ratio"` // 最新成交量 / 之前N(默认为9)个平均成交量
+	OBV              float64   `json:"obv"`                // 能量潮(从窗口第一根K线起累计)
+	OBVValues        []float64 `json:"obv_values"`         // 最近10个点的OBV
+
+	Trend string `json:"trend"` // 趋势标签: up/down/flat（收盘价与EMA20位置及EMA20斜率）
 }
 
 // LongerTermData 长期数据(4小时时间框架1天)
 type LongerTermData struct {
-	EMA20 float64
-	EMA50 float64
+	EMA20 float64 `json:"ema20"`
+	EMA50 float64 `json:"ema50"`
+
+	ATR3         float64 `json:"atr3"`
+	ATR10        float64 `json:"atr10"`
+	ATR12        float64 `json:"atr12"`
+	ATR14        float64 `json:"atr14"`
+	ATR14Percent float64 `json:"atr14_percent"` // ATR14 / 最新收盘价 * 100
+
+	VWAP float64 `json:"vwap"` // 成交量加权平均价（整个K线窗口）
+
+	// VWAP ± 2倍成交量加权标准差
+	VWAPUpper float64 `json:"vwap_upper"`
+	VWAPLower float64 `json:"vwap_lower"`
+
+	// 趋势强度 ADX(14) 与方向指标 +DI/-DI，ADX>25 通常视为趋势行情
+	ADX14   float64 `json:"adx14"`
+	PlusDI  float64 `json:"plus_di"`
+	MinusDI float64 `json:"minus_di"`
+
+	CCI20 float64 `json:"cci20"` // 商品通道指数(20)，>+100 超买、<-100 超卖
+	CMF20 float64 `json:"cmf20"` // 蔡金资金流量(20)，-1~1，正值吸筹、负值派发
+
+	// 唐奇安通道(20)：最近20根K线最高价/最低价及中值，作为突破参考位
+	DonchianUpper float64 `json:"donchian_upper"`
+	DonchianLower float64 `json:"donchian_lower"`
+	DonchianMid   float64 `json:"donchian_mid"`
+
+	// 最新K线放量突破前20根K线的唐奇安通道（成交量超过均量 BreakoutVolumeMultiple 倍）
+	BreakoutUp   bool `json:"breakout_up"`
+	BreakoutDown bool `json:"breakout_down"`
+
+	// 成交量分布：成交量最大的价格档(POC)及覆盖70%成交量的价值区域上下沿
+	POC           float64 `json:"poc"`
+	ValueAreaHigh float64 `json:"value_area_high"`
+	ValueAreaLow  float64 `json:"value_area_low"`
+
+	Ichimoku *Ichimoku `json:"ichimoku"` // 一目均衡表(9,26,52)，K线不足52根时为nil
+
+	// 最近50根K线摆动高低点间的斐波那契回撤价位，键为比例("0.236"~"0.786")，K线不足时为nil
+	FibLevels map[string]float64 `json:"fib_levels"`
 
-	ATR3  float64
-	ATR10 float64
-	ATR12 float64
-	ATR14 float64
+	CurrentVolume float64 `json:"current_volume"`
+	AverageVolume float64 `json:"average_volume"`
 
-	CurrentVolume float64
-	AverageVolume float64
+	MACDValues142810 []float64 `json:"macd_values_142810"`
+	MACDValues12269  []float64 `json:"macd_values_12269"`
+	MACDDEA142810    []float64 `json:"macd_dea_142810"`  // 信号线，只含信号线形成后的点，与 MACDValues142810 末尾对齐
+	MACDDEA12269     []float64 `json:"macd_dea_12269"`   // 信号线，只含信号线形成后的点，与 MACDValues12269 末尾对齐
+	MACDHist142810   []float64 `json:"macd_hist_142810"` // 柱状图(DIF-DEA)，与对应 DEA 等长
+	MACDHist12269    []float64 `json:"macd_hist_12269"`  // 柱状图(DIF-DEA)，与对应 DEA 等长
+	RSI14Values      []float64 `json:"rsi14_values"`
+	RSI21Values      []float64 `json:"rsi21_values"`
 
-	MACDValues142810 []float64
-	MACDValues12269  []float64
-	RSI14Values      []float64
-	RSI21Values      []float64
+	Trend string `json:"trend"` // 趋势标签: up/down/flat（收盘价、EMA20、EMA50排列）
 }
 
 // Binance API 响应结构
@@ -112,6 +242,7 @@ type SymbolInfo struct {
 	QuantityPrecision int    `json:"quantityPrecision"`
 }
 
+// Kline K线数据，JSON 键沿用原有的 camelCase(openTime/closeTime/quoteVolume 等)以兼容已有消费方
 type Kline struct {
 	OpenTime            int64   `json:"openTime"`
 	Open                float64 `json:"open"`
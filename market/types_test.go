@@ -0,0 +1,58 @@
+package market
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestDataJSONKeys(t *testing.T) {
+	data := &Data{
+		Symbol:         "BTCUSDT",
+		CurrentPrice:   64250.5,
+		OpenInterest:   &OIData{Latest: 1234.5},
+		Funding:        &FundingData{Rate: 0.0001},
+		IntradaySeries: &IntradayData{RSI7Values: []float64{55.5}},
+		LongerTerm1Mo:  &LongerTermData{EMA20: 60000},
+		Klines:         map[string][]Kline{"3m": {{OpenTime: 1, CloseTime: 2, QuoteVolume: 3}}},
+	}
+	body, err := data.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if _, ok := raw["longer_term_1mo"]; !ok {
+		t.Error("缺少 longer_term_1mo 字段")
+	}
+
+	var klines map[string][]map[string]json.RawMessage
+	if err := json.Unmarshal(raw["klines"], &klines); err != nil {
+		t.Fatalf("Unmarshal klines: %v", err)
+	}
+	// Kline 保持原有 camelCase 键
+	for _, key := range []string{"openTime", "closeTime", "quoteVolume", "takerBuyBaseVolume", "takerBuyQuoteVolume"} {
+		if _, ok := klines["3m"][0][key]; !ok {
+			t.Errorf("Kline 缺少 %q 字段", key)
+		}
+	}
+	snake := regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+	for key := range raw {
+		if !snake.MatchString(key) {
+			t.Errorf("Data 字段 %q 不是 snake_case", key)
+		}
+	}
+
+	var decoded Data
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Unmarshal Data: %v", err)
+	}
+	if decoded.CurrentPrice != 64250.5 || decoded.OpenInterest.Latest != 1234.5 || decoded.Funding.Rate != 0.0001 ||
+		decoded.IntradaySeries.RSI7Values[0] != 55.5 || decoded.LongerTerm1Mo.EMA20 != 60000 ||
+		decoded.Klines["3m"][0].QuoteVolume != 3 {
+		t.Errorf("往返序列化结果不一致: %+v", decoded)
+	}
+}
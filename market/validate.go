@@ -0,0 +1,81 @@
+package market
+
+import "fmt"
+
+// ValidateKlines 检查K线是否可用于指标计算：
+// 开盘时间严格递增（有序且无重复）、收盘价为正、最高价不低于最低价
+// 返回第一处异常的描述，全部通过时返回 nil
+func ValidateKlines(klines []Kline) error {
+	for i, k := range klines {
+		if k.Close <= 0 {
+			return fmt.Errorf("第%d根K线收盘价无效: %v", i, k.Close)
+		}
+		if k.High < k.Low {
+			return fmt.Errorf("第%d根K线最高价(%v)低于最低价(%v)", i, k.High, k.Low)
+		}
+		if i > 0 && k.OpenTime <= klines[i-1].OpenTime {
+			return fmt.Errorf("第%d根K线开盘时间(%d)未晚于前一根(%d)", i, k.OpenTime, klines[i-1].OpenTime)
+		}
+	}
+	return nil
+}
+
+// indicatorRequirement 指标名称及计算所需的最少K线数
+type indicatorRequirement struct {
+	name      string
+	minKlines int
+}
+
+// intradayRequirements 日内指标(各周期均计算)所需的最少K线数，不足时对应指标为0
+var intradayRequirements = []indicatorRequirement{
+	{"RSI(14)", 15},
+	{"ATR(14)", 15},
+	{"EMA(20)", 20},
+	{"布林带(20,2)", 20},
+	{"MACD(12,26,9)", 34},
+}
+
+// longerTermRequirements 4h/1d 长期指标额外所需的最少K线数
+var longerTermRequirements = []indicatorRequirement{
+	{"EMA(50)", 50},
+	{"一目均衡表(9,26,52)", 52},
+}
+
+// isLongerTermTimeframe 是否为额外计算长期指标(LongerTermData)的周期
+func isLongerTermTimeframe(timeframe string) bool {
+	switch timeframe {
+	case "4h", "1d", "1w", "1M":
+		return true
+	}
+	return false
+}
+
+// MinKlines 返回该周期全部指标都能计算所需的最少K线数
+func MinKlines(timeframe string) int {
+	required := 0
+	for _, req := range timeframeRequirements(timeframe) {
+		if req.minKlines > required {
+			required = req.minKlines
+		}
+	}
+	return required
+}
+
+// timeframeRequirements 返回该周期需要检查的指标
+func timeframeRequirements(timeframe string) []indicatorRequirement {
+	if isLongerTermTimeframe(timeframe) {
+		return append(append([]indicatorRequirement(nil), intradayRequirements...), longerTermRequirements...)
+	}
+	return intradayRequirements
+}
+
+// klineWarnings 列出该周期因K线不足而无法计算(结果为0)的指标
+func klineWarnings(timeframe string, count int) []string {
+	var warnings []string
+	for _, req := range timeframeRequirements(timeframe) {
+		if count < req.minKlines {
+			warnings = append(warnings, fmt.Sprintf("%s %s 需要至少%d根K线，当前%d根", timeframe, req.name, req.minKlines, count))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,96 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateKlines(t *testing.T) {
+	modify := func(i int, f func(*Kline)) []Kline {
+		klines := testKlines(5, time.Minute)
+		f(&klines[i])
+		return klines
+	}
+	tests := []struct {
+		name    string
+		klines  []Kline
+		wantErr string // 为空表示应通过
+	}{
+		{"正常", testKlines(5, time.Minute), ""},
+		{"无K线", nil, ""},
+		{"收盘价为0", modify(2, func(k *Kline) { k.Close = 0 }), "第2根K线收盘价无效"},
+		{"收盘价为负", modify(0, func(k *Kline) { k.Close = -1 }), "第0根K线收盘价无效"},
+		{"最高价低于最低价", modify(3, func(k *Kline) { k.High, k.Low = k.Low, k.High }), "第3根K线最高价"},
+		{"开盘时间重复", modify(4, func(k *Kline) { k.OpenTime -= time.Minute.Milliseconds() }), "第4根K线开盘时间"},
+		{"乱序", modify(1, func(k *Kline) { k.OpenTime = 10 * time.Minute.Milliseconds() }), "第2根K线开盘时间"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKlines(tt.klines)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateKlines = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateKlines = %v, want 包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKlineWarnings(t *testing.T) {
+	tests := []struct {
+		timeframe string
+		count     int
+		want      []string // 应出现的指标名称
+		wantCount int
+	}{
+		{"3m", 5, []string{"3m MACD(12,26,9) 需要至少34根K线，当前5根", "3m RSI(14)"}, len(intradayRequirements)},
+		{"15m", 20, []string{"15m MACD(12,26,9)"}, 1},
+		{"1h", 34, nil, 0},
+		{"4h", 40, []string{"4h EMA(50)", "4h 一目均衡表(9,26,52)"}, 2},
+		{"1d", MinKlines("1d"), nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timeframe, func(t *testing.T) {
+			got := klineWarnings(tt.timeframe, tt.count)
+			if len(got) != tt.wantCount {
+				t.Errorf("klineWarnings(%s, %d) = %q, want %d 条", tt.timeframe, tt.count, got, tt.wantCount)
+			}
+			joined := strings.Join(got, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(joined, want) {
+					t.Errorf("警告缺少 %q: %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetWithFewKlinesIsPartial(t *testing.T) {
+	useTestServer(t, map[string]http.HandlerFunc{
+		"/fapi/v1/exchangeInfo": jsonHandler(testExchangeInfo),
+	})
+	opts := DefaultOptions()
+	opts.Timeframes = []string{"3m"}
+	opts.Source = &fakeSource{klines: testKlines(5, 3*time.Minute)}
+
+	data, err := GetWithOptions(context.Background(), "BTCUSDT", opts)
+	if err != nil {
+		t.Fatalf("GetWithOptions: %v", err)
+	}
+	if !data.Partial || !strings.Contains(strings.Join(data.Warnings, ";"), "MACD(12,26,9)") {
+		t.Errorf("Partial = %v, Warnings = %q; want MACD 警告", data.Partial, data.Warnings)
+	}
+	if data.CurrentMACD != 0 {
+		t.Errorf("CurrentMACD = %v, K线不足时应为0", data.CurrentMACD)
+	}
+	if out := Format(data); !strings.Contains(out, "数据不完整") {
+		t.Errorf("Format 未提示数据不完整:\n%s", out)
+	}
+}
@@ -14,7 +14,6 @@ type WSClient struct {
 	conn        *websocket.Conn
 	mu          sync.RWMutex
 	subscribers map[string]chan []byte
-	reconnect   bool
 	done        chan struct{}
 }
 
@@ -71,7 +70,6 @@ type TickerWSData struct {
 func NewWSClient() *WSClient {
 	return &WSClient{
 		subscribers: make(map[string]chan []byte),
-		reconnect:   true,
 		done:        make(chan struct{}),
 	}
 }
@@ -81,7 +79,7 @@ func (w *WSClient) Connect() error {
 		HandshakeTimeout: 10 * time.Second,
 	}
 
-	conn, _, err := dialer.Dial("wss://ws-fapi.binance.com/ws-fapi/v1", nil)
+	conn, _, err := dialer.Dial(WSAPIURL, nil)
 	if err != nil {
 		return fmt.Errorf("WebSocket连接失败: %v", err)
 	}
@@ -93,7 +91,7 @@ func (w *WSClient) Connect() error {
 	log.Println("WebSocket连接成功")
 
 	// 启动消息读取循环
-	go w.readMessages()
+	goBackground(w.readMessages)
 
 	return nil
 }
@@ -170,10 +168,10 @@ func (w *WSClient) handleMessage(message []byte) {
 		return
 	}
 
+	// 持有读锁完成发送（非阻塞），避免 Close 在查找与发送之间关闭该通道
 	w.mu.RLock()
+	defer w.mu.RUnlock()
 	ch, exists := w.subscribers[wsMsg.Stream]
-	w.mu.RUnlock()
-
 	if exists {
 		select {
 		case ch <- wsMsg.Data:
@@ -184,16 +182,23 @@ func (w *WSClient) handleMessage(message []byte) {
 }
 
 func (w *WSClient) handleReconnect() {
-	if !w.reconnect {
+	// 已关闭时不再重连（done 由 Close 关闭，可安全地并发读取）
+	select {
+	case <-w.done:
 		return
+	default:
 	}
 
 	log.Println("尝试重新连接...")
-	time.Sleep(3 * time.Second)
+	select {
+	case <-w.done:
+		return
+	case <-time.After(3 * time.Second):
+	}
 
 	if err := w.Connect(); err != nil {
 		log.Printf("重新连接失败: %v", err)
-		go w.handleReconnect()
+		goBackground(w.handleReconnect)
 	}
 }
 
@@ -212,7 +217,6 @@ func (w *WSClient) RemoveSubscriber(stream string) {
 }
 
 func (w *WSClient) Close() {
-	w.reconnect = false
 	close(w.done)
 
 	w.mu.Lock()